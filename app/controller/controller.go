@@ -3,7 +3,10 @@ package controller
 import (
 	"context"
 	"errors"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 
 	"github.com/devesh2997/consequent/errorx"
 	"github.com/devesh2997/consequent/logger"
@@ -18,6 +21,7 @@ const (
 	dataKey         = "data"
 	msgKey          = "msg"
 	errorMessageKey = "errorMessage"
+	retryAtKey      = "retry_at"
 )
 
 type Controller struct {
@@ -38,7 +42,16 @@ func (ctrl Controller) SendWithError(gCtx *gin.Context, err error) {
 	// passing context of request because that's where request id is stored.
 	ctrl.logError(gCtx.Request.Context(), err)
 
-	gCtx.JSON(httpStatusCode, gin.H{codeKey: codeFailed, errorCodeKey: errCode, errorMessageKey: err.Error()})
+	response := gin.H{codeKey: codeFailed, errorCodeKey: errCode, errorMessageKey: err.Error()}
+
+	var tooManyRequestsError errorx.TooManyRequestsError
+	if errors.As(err, &tooManyRequestsError) {
+		retryAfter := math.Ceil(time.Until(tooManyRequestsError.RetryAt).Seconds())
+		gCtx.Header("Retry-After", strconv.Itoa(int(math.Max(retryAfter, 0))))
+		response[retryAtKey] = tooManyRequestsError.RetryAt
+	}
+
+	gCtx.JSON(httpStatusCode, response)
 }
 
 func (ctrl Controller) SendBadRequestError(gCtx *gin.Context, err error) {
@@ -79,6 +92,7 @@ func (ctrl Controller) BindQueryAndBody(gCtx *gin.Context, dest interface{}) err
 func (ctrl Controller) logError(ctx context.Context, err error) {
 	var businessError errorx.BusinessError
 	var validationError errorx.ValidationError
+	var tooManyRequestsError errorx.TooManyRequestsError
 
 	shouldLogError := true
 
@@ -86,6 +100,8 @@ func (ctrl Controller) logError(ctx context.Context, err error) {
 		shouldLogError = false
 	} else if errors.As(err, &businessError) {
 		shouldLogError = false
+	} else if errors.As(err, &tooManyRequestsError) {
+		shouldLogError = false
 	}
 
 	if shouldLogError {
@@ -99,6 +115,7 @@ func (ctrl Controller) getHTTPStatusCodeFromError(err error) int {
 	var apiCallError errorx.APICallError
 	var unauthorizedError errorx.UnauthorizedError
 	var systemError errorx.SystemError
+	var tooManyRequestsError errorx.TooManyRequestsError
 
 	if errors.As(err, &notFoundError) {
 		return http.StatusNotFound
@@ -110,6 +127,8 @@ func (ctrl Controller) getHTTPStatusCodeFromError(err error) int {
 		return http.StatusUnauthorized
 	} else if errors.As(err, &systemError) {
 		return http.StatusInternalServerError
+	} else if errors.As(err, &tooManyRequestsError) {
+		return http.StatusTooManyRequests
 	}
 
 	return 200
@@ -1,25 +1,39 @@
 package middleware
 
 import (
-	"encoding/base64"
-	"encoding/json"
+	"context"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 
 	"github.com/devesh2997/consequent/contextx"
+	"github.com/devesh2997/consequent/identity/domain/entities"
 	"github.com/devesh2997/consequent/identity/domain/services"
 	"github.com/devesh2997/consequent/logger"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
 
 	"github.com/gin-gonic/gin"
 )
 
+const apiKeyScheme = "ApiKey "
+
 var tokenRequiredMessage = "authorization header is required."
 var errTokenRequired = errors.New(tokenRequiredMessage)
-var errTokenInvalid = errors.New("invalid token provided")
+var errUserRequired = errors.New("this endpoint can only be used by users.")
+var errClientNotAllowed = errors.New("this endpoint can not be used with tokens issued to oauth clients.")
+var errAccessTokenRequired = errors.New("this endpoint can not be used with an api key.")
+var errScopeRequired = func(scope string) error {
+	return fmt.Errorf("the %s scope is required.", scope)
+}
+var errServiceRequired = errors.New("this endpoint can only be used by service clients.")
+var errPermissionRequired = func(permission string) error {
+	return fmt.Errorf("the %s permission is required.", permission)
+}
 
 type Tokens struct {
-	BearerToken bearerToken `header:"Authorization"` // jwt token
+	BearerToken bearerToken `header:"Authorization"` // jwt token, or api key with the ApiKey scheme
+	APIKey      string      `header:"X-API-Key"`
 }
 
 func (tokens Tokens) getJWT() string {
@@ -33,20 +47,27 @@ func (tokens Tokens) getJWT() string {
 	return ""
 }
 
-func (tokens Tokens) validate(tokenService services.TokenService) error {
+// getAPIKey returns the api key of the X-API-Key header, or of the authorization header with the ApiKey scheme.
+func (tokens Tokens) getAPIKey() string {
+	if tokens.APIKey != "" {
+		return tokens.APIKey
+	}
+
+	return strings.TrimPrefix(string(tokens.BearerToken), apiKeyScheme)
+}
+
+func (tokens Tokens) hasAPIKey() bool {
+	return tokens.APIKey != "" || strings.HasPrefix(string(tokens.BearerToken), apiKeyScheme)
+}
+
+// validate validates the bearer token and returns its claims.
+func (tokens Tokens) validate(ctx context.Context, tokenService services.TokenService) (*services.AccessTokenClaims, error) {
 	isBearerTokenPresent := tokens.BearerToken.isPresent()
 	if !isBearerTokenPresent {
-		return errTokenRequired
-	}
-	if _, err := tokenService.Validate(tokens.getJWT()); err != nil {
-		return err
-	}
-	_, err := tokens.BearerToken.getRequestUser()
-	if err != nil {
-		return err
+		return nil, errTokenRequired
 	}
 
-	return nil
+	return tokenService.Validate(ctx, tokens.getJWT())
 }
 
 func respondWithUnauthenticatedError(c *gin.Context, err error) {
@@ -54,8 +75,11 @@ func respondWithUnauthenticatedError(c *gin.Context, err error) {
 	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
 }
 
-// Authorisation is...
-func Authorisation(tokenService services.TokenService) gin.HandlerFunc {
+// Authorisation authorises requests with an access token, or with an api key through the ApiKey authorization
+// scheme or the X-API-Key header. Either way the user is saved to the context of the request. Requests of service
+// clients with a service token are authorised as well, with the service principal saved to the context instead of a
+// user. Routes that are only meant for users must be guarded with RequireUser as well.
+func Authorisation(tokenService services.TokenService, apiKeyService services.APIKeyService) gin.HandlerFunc {
 	return func(gCtx *gin.Context) {
 		var requestTokens Tokens
 
@@ -64,63 +88,185 @@ func Authorisation(tokenService services.TokenService) gin.HandlerFunc {
 			return
 		}
 
-		if err := requestTokens.validate(tokenService); err != nil {
+		if requestTokens.hasAPIKey() {
+			apiKey, user, roles, err := apiKeyService.Validate(gCtx.Request.Context(), requestTokens.getAPIKey())
+			if err != nil {
+				respondWithUnauthenticatedError(gCtx, err)
+				return
+			}
+
+			saveAPIKeyUserToContext(gCtx, *apiKey, *user, roles)
+
+			gCtx.Next()
+			return
+		}
+
+		if tokenService.IsServiceToken(requestTokens.getJWT()) {
+			claims, err := tokenService.ValidateServiceToken(gCtx.Request.Context(), requestTokens.getJWT())
+			if err != nil {
+				respondWithUnauthenticatedError(gCtx, err)
+				return
+			}
+
+			saveTokensAndServiceToContext(gCtx, requestTokens, claims)
+
+			gCtx.Next()
+			return
+		}
+
+		claims, err := requestTokens.validate(gCtx.Request.Context(), tokenService)
+		if err != nil {
 			respondWithUnauthenticatedError(gCtx, err)
 			return
 		}
 
-		saveTokensAndUserToContext(gCtx, requestTokens)
+		saveTokensAndUserToContext(gCtx, requestTokens, claims)
 
 		gCtx.Next()
 	}
 }
 
-func saveTokensAndUserToContext(gCtx *gin.Context, tokens Tokens) {
+func saveTokensAndUserToContext(gCtx *gin.Context, tokens Tokens, claims *services.AccessTokenClaims) {
 	reqContext := gCtx.Request.Context()
 
 	contextWithBearerToken := contextx.WithBearerToken(reqContext, string(tokens.BearerToken))
-	requestUser, _ := tokens.BearerToken.getRequestUser()
-	contextWithUser := contextx.WithRequestUser(contextWithBearerToken, *requestUser)
+	requestUser := contextx.RequestUser{
+		ID:     claims.User.ID,
+		Mobile: claims.User.Mobile,
+		Email:  claims.User.Email,
+		// set for tokens issued to oauth clients
+		ClientID:    claims.ClientID,
+		Scope:       claims.Scope,
+		TokenID:     claims.Id,
+		Roles:       claims.Roles,
+		Permissions: claims.Permissions,
+	}
+	contextWithUser := contextx.WithRequestUser(contextWithBearerToken, requestUser)
 
 	gCtx.Request = gCtx.Request.WithContext(contextWithUser)
 }
 
-type bearerToken string
+func saveAPIKeyUserToContext(gCtx *gin.Context, apiKey entities.APIKey, user userEntities.User, roles []userEntities.Role) {
+	requestUser := contextx.RequestUser{
+		ID:          user.ID,
+		Mobile:      user.Mobile,
+		Email:       user.Email,
+		Scope:       apiKey.Scope,
+		APIKeyID:    apiKey.ID,
+		Roles:       userEntities.RoleNames(roles),
+		Permissions: userEntities.RolePermissions(roles),
+	}
+	contextWithUser := contextx.WithRequestUser(gCtx.Request.Context(), requestUser)
 
-func (t bearerToken) isPresent() bool {
-	return t != ""
+	gCtx.Request = gCtx.Request.WithContext(contextWithUser)
 }
 
-func (t bearerToken) getRequestUser() (*contextx.RequestUser, error) {
-	jwt := strings.Replace(string(t), "Bearer ", "", 1)
-	base64EncodedPayload := strings.Split(jwt, ".")[1]
+func saveTokensAndServiceToContext(gCtx *gin.Context, tokens Tokens, claims *services.ServiceTokenClaims) {
+	reqContext := gCtx.Request.Context()
 
-	payloadBytes, err := base64.RawURLEncoding.DecodeString(base64EncodedPayload)
-	if err != nil {
-		return nil, err
+	contextWithBearerToken := contextx.WithBearerToken(reqContext, string(tokens.BearerToken))
+	principal := contextx.ServicePrincipal{
+		ClientID: claims.ClientID,
+		Scope:    claims.Scope,
+		TokenID:  claims.Id,
 	}
+	contextWithService := contextx.WithServicePrincipal(contextWithBearerToken, principal)
+
+	gCtx.Request = gCtx.Request.WithContext(contextWithService)
+}
 
-	type userPayload struct {
-		ID     int64  `json:"id"`
-		Email  string `json:"email"`
-		Mobile string `json:"mobile"`
+// RequireUser refuses requests that were not authorised for a user, such as those of service clients. Requests of
+// oauth clients on behalf of a user are refused as well, the first party apis are only for the user themselves. It
+// must be used after Authorisation.
+func RequireUser() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+		if !requestUser.IsPresent() {
+			gCtx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errUserRequired.Error()})
+			return
+		}
+		if requestUser.IsClient() {
+			gCtx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errClientNotAllowed.Error()})
+			return
+		}
+
+		gCtx.Next()
 	}
-	payload := struct {
-		User userPayload `json:"usr"`
-	}{}
-	err = json.Unmarshal(payloadBytes, &payload)
-	if err != nil {
-		return nil, err
+}
+
+// RequireUserOrClient refuses requests that were not authorised for a user, but unlike RequireUser it accepts those
+// of oauth clients on behalf of a user. It must be used after Authorisation.
+func RequireUserOrClient() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		if !contextx.GetRequestUser(gCtx.Request.Context()).IsPresent() {
+			gCtx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errUserRequired.Error()})
+			return
+		}
+
+		gCtx.Next()
+	}
+}
+
+// RequireAccessToken refuses requests that were authorised with an api key rather than an access token. It guards
+// the apis that manage the account and its credentials, so that a leaked key cannot be used to take over the
+// account or to create credentials that outlive its revocation. It must be used after Authorisation.
+func RequireAccessToken() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		if contextx.GetRequestUser(gCtx.Request.Context()).APIKeyID != 0 {
+			gCtx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errAccessTokenRequired.Error()})
+			return
+		}
+
+		gCtx.Next()
+	}
+}
+
+// RequireScope refuses requests of users that are limited to a scope, such as those with a scoped api key, unless
+// their scope contains the given one. Every route that api keys can be used for must be guarded with either
+// RequireScope or RequireAccessToken. It must be used after Authorisation.
+func RequireScope(scope string) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		if !contextx.GetRequestUser(gCtx.Request.Context()).HasScope(scope) {
+			gCtx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errScopeRequired(scope).Error()})
+			return
+		}
+
+		gCtx.Next()
 	}
+}
+
+// RequireService refuses requests that were not authorised for a service client with a service token. It must be
+// used after Authorisation.
+func RequireService() gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		if !contextx.GetServicePrincipal(gCtx.Request.Context()).IsPresent() {
+			gCtx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errServiceRequired.Error()})
+			return
+		}
+
+		gCtx.Next()
+	}
+}
+
+// RequirePermission refuses requests of users that have not been granted the permission by any of their roles, and
+// of service clients that have not been granted it as a scope. It must be used after Authorisation.
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(gCtx *gin.Context) {
+		reqContext := gCtx.Request.Context()
+		requestUser := contextx.GetRequestUser(reqContext)
+		principal := contextx.GetServicePrincipal(reqContext)
 
-	if payload.User.ID == 0 {
-		return nil, errTokenInvalid
+		if !requestUser.HasPermission(permission) && !principal.HasPermission(permission) {
+			gCtx.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": errPermissionRequired(permission).Error()})
+			return
+		}
+
+		gCtx.Next()
 	}
+}
+
+type bearerToken string
 
-	up := payload.User
-	return &contextx.RequestUser{
-		ID:     up.ID,
-		Mobile: up.Mobile,
-		Email:  up.Email,
-	}, nil
+func (t bearerToken) isPresent() bool {
+	return t != ""
 }
@@ -15,7 +15,8 @@ import (
 )
 
 var (
-	xRequestIDKey = "X-Request-ID"
+	xRequestIDKey  = "X-Request-ID"
+	xDeviceNameKey = "X-Device-Name"
 )
 
 // generator a function type that returns string.
@@ -31,15 +32,18 @@ func uuid(len int) string {
 	return base64.StdEncoding.EncodeToString(bytes)[:len]
 }
 
-// RequestInfo is a middleware that injects a RequestID, request body and request url into the context of each request.
+// RequestInfo is a middleware that injects a RequestID, request body, request url, client ip, user agent and device name into the context of each request.
 func RequestInfo(gen generator) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		contextWithRequestID := injectRequestID(c, gen)
 		contextWithRequestURL := injectRequestURL(c.Request, contextWithRequestID)
 		contextWithRequestBody := injectRequestBody(c, contextWithRequestURL)
 		contextWithRequestHeader := injectRequestHeader(c, contextWithRequestBody)
+		contextWithClientIP := contextx.WithClientIP(contextWithRequestHeader, c.ClientIP())
+		contextWithUserAgent := contextx.WithUserAgent(contextWithClientIP, c.Request.UserAgent())
+		contextWithDeviceName := contextx.WithDeviceName(contextWithUserAgent, c.Request.Header.Get(xDeviceNameKey))
 
-		c.Request = c.Request.WithContext(contextWithRequestHeader)
+		c.Request = c.Request.WithContext(contextWithDeviceName)
 		c.Next()
 	}
 }
@@ -4,8 +4,10 @@ import (
 	"net/http"
 
 	"github.com/devesh2997/consequent/app/middleware"
+	"github.com/devesh2997/consequent/config"
 	"github.com/devesh2997/consequent/identity/router"
 	"github.com/devesh2997/consequent/logger"
+	oauthRouter "github.com/devesh2997/consequent/oauth/router"
 	userRouter "github.com/devesh2997/consequent/user/router"
 	"github.com/gin-gonic/gin"
 )
@@ -13,6 +15,9 @@ import (
 // Create is...
 func Create() http.Handler {
 	r := gin.New()
+	if err := r.SetTrustedProxies(config.Config.TrustedProxies); err != nil {
+		panic(err)
+	}
 
 	setupGlobalMiddlewares(r)
 
@@ -22,12 +27,19 @@ func Create() http.Handler {
 		})
 	})
 
+	wellKnownGroup := r.Group("/.well-known")
+	router.InjectWellKnownRoutes(wellKnownGroup)
+	oauthRouter.InjectWellKnownRoutes(wellKnownGroup)
+
 	identityGroup := r.Group("/identity")
 	router.InjectIdentityRoutes(identityGroup)
 
 	userGroup := r.Group("/user")
 	userRouter.InjectUserRoutes(userGroup)
 
+	oauthGroup := r.Group("/oauth")
+	oauthRouter.InjectOAuthRoutes(oauthGroup)
+
 	return r
 }
 
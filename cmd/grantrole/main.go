@@ -0,0 +1,45 @@
+// grantrole grants a role to a user, or revokes it with -revoke. It is how the first admin is made, after which roles
+// can be managed with the admin apis.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/devesh2997/consequent/cmd/flags"
+	"github.com/devesh2997/consequent/config"
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/user/containers"
+)
+
+var (
+	userID = flag.Int64("user-id", 0, "id of the user")
+	role   = flag.String("role", "admin", "name of the role")
+	revoke = flag.Bool("revoke", false, "revoke the role instead of granting it")
+)
+
+func main() {
+	env := flags.GetEnvironment()
+	config.LoadConfig(env, ".")
+
+	roleService := containers.InjectRoleService()
+
+	var err error
+	if *revoke {
+		err = roleService.RevokeRole(context.Background(), *userID, *role)
+	} else {
+		err = roleService.GrantRole(context.Background(), *userID, *role)
+	}
+	if err != nil {
+		fmt.Println(errorx.FullError(err))
+		os.Exit(1)
+	}
+
+	if *revoke {
+		fmt.Printf("revoked %s from user %d\n", *role, *userID)
+	} else {
+		fmt.Printf("granted %s to user %d\n", *role, *userID)
+	}
+}
@@ -0,0 +1,32 @@
+// hashpasswords is a one-off command that hashes the user passwords which were stored in plaintext
+// before password hashing was introduced. Running it more than once is safe, hashed rows are skipped.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/devesh2997/consequent/cmd/flags"
+	"github.com/devesh2997/consequent/config"
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/containers"
+)
+
+var batchSize = flag.Int("batch-size", 500, "number of user passwords read per batch")
+
+func main() {
+	env := flags.GetEnvironment()
+	config.LoadConfig(env, ".")
+
+	identityService := containers.InjectIdentityService()
+
+	hashed, err := identityService.HashPlaintextPasswords(context.Background(), *batchSize)
+	if err != nil {
+		fmt.Println(errorx.FullError(err))
+		os.Exit(1)
+	}
+
+	fmt.Printf("hashed %d plaintext passwords\n", hashed)
+}
@@ -0,0 +1,35 @@
+// mergeusers merges a user into another, for support staff to combine the users of someone who has proved that they
+// own both, e.g. by signing in to each. Users with different emails or mobile numbers are not merged.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/devesh2997/consequent/cmd/flags"
+	"github.com/devesh2997/consequent/config"
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/containers"
+)
+
+var (
+	intoUserID = flag.Int64("into", 0, "id of the user that is kept")
+	fromUserID = flag.Int64("from", 0, "id of the user that is merged into the other and left in the merged status")
+)
+
+func main() {
+	env := flags.GetEnvironment()
+	config.LoadConfig(env, ".")
+
+	identityService := containers.InjectIdentityService()
+
+	user, err := identityService.MergeUsers(context.Background(), *intoUserID, *fromUserID)
+	if err != nil {
+		fmt.Println(errorx.FullError(err))
+		os.Exit(1)
+	}
+
+	fmt.Printf("merged user %d into user %d (email: %q, mobile: %q)\n", *fromUserID, user.ID, user.Email, user.Mobile)
+}
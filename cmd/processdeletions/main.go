@@ -0,0 +1,48 @@
+// processdeletions deletes the accounts whose deletion grace period has passed, and is meant to be run on a schedule.
+// Accounts that fail to be deleted are logged and retried on the next run. With -user-id, it deletes the account of
+// that user straight away instead, e.g. for deletions that have been asked for through support.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/devesh2997/consequent/cmd/flags"
+	"github.com/devesh2997/consequent/config"
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/containers"
+)
+
+var (
+	batchSize = flag.Int("batch-size", 100, "number of deletion requests read per batch")
+	userID    = flag.Int64("user-id", 0, "id of a user whose account is deleted without waiting for the grace period")
+)
+
+func main() {
+	env := flags.GetEnvironment()
+	config.LoadConfig(env, ".")
+
+	accountService := containers.InjectAccountService()
+
+	if *userID != 0 {
+		if err := accountService.DeleteAccount(context.Background(), *userID); err != nil {
+			fmt.Println(errorx.FullError(err))
+			os.Exit(1)
+		}
+
+		fmt.Printf("deleted the account of user %d\n", *userID)
+		return
+	}
+
+	deleted, err := accountService.ProcessDueDeletions(context.Background(), *batchSize)
+	if err != nil {
+		fmt.Println(errorx.FullError(err))
+	}
+
+	fmt.Printf("deleted %d accounts\n", deleted)
+	if err != nil {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,71 @@
+// registerclient registers an oauth client and prints its client id and, for confidential clients, its secret.
+// The secret is stored hashed and cannot be shown again. Service clients are registered with -service, and
+// authenticate with a signed jwt instead of a secret when -public-key-file is given.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/devesh2997/consequent/cmd/flags"
+	"github.com/devesh2997/consequent/config"
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/oauth/containers"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+	"github.com/devesh2997/consequent/oauth/domain/services"
+)
+
+var (
+	name          = flag.String("name", "", "name of the client shown to users")
+	redirectURIs  = flag.String("redirect-uris", "", "comma separated redirect uris of the client")
+	scopes        = flag.String("scopes", "", "space separated scopes that the client may request")
+	public        = flag.Bool("public", false, "register a public client, e.g. a single page or mobile app, which has no secret")
+	firstParty    = flag.Bool("first-party", false, "register one of our own apps, users are not asked for consent")
+	service       = flag.Bool("service", false, "register an internal service, which gets tokens of its own with the client credentials grant")
+	publicKeyFile = flag.String("public-key-file", "", "pem encoded public key of a service client that authenticates with a signed jwt")
+)
+
+func main() {
+	env := flags.GetEnvironment()
+	config.LoadConfig(env, ".")
+
+	oauthService := containers.InjectOAuthService()
+
+	var client *entities.Client
+	var secret string
+	var err error
+	if *service {
+		client, secret, err = registerServiceClient(oauthService)
+	} else {
+		client, secret, err = oauthService.RegisterClient(context.Background(), *name, splitList(*redirectURIs), strings.Fields(*scopes), !*public, *firstParty)
+	}
+	if err != nil {
+		fmt.Println(errorx.FullError(err))
+		os.Exit(1)
+	}
+
+	fmt.Printf("client_id: %s\n", client.ClientID)
+	if secret != "" {
+		fmt.Printf("client_secret: %s\n", secret)
+	}
+}
+
+func registerServiceClient(oauthService services.OAuthService) (*entities.Client, string, error) {
+	publicKey := ""
+	if *publicKeyFile != "" {
+		b, err := os.ReadFile(*publicKeyFile)
+		if err != nil {
+			return nil, "", err
+		}
+		publicKey = string(b)
+	}
+
+	return oauthService.RegisterServiceClient(context.Background(), *name, strings.Fields(*scopes), publicKey)
+}
+
+func splitList(list string) []string {
+	return strings.FieldsFunc(list, func(r rune) bool { return r == ',' })
+}
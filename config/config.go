@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/devesh2997/consequent/errorx"
 	"github.com/spf13/viper"
@@ -11,10 +12,26 @@ import (
 
 // AppConfig represents the application config that are defined in env files.
 type AppConfig struct {
-	Log           LogConfig     `mapstructure:"log"`
-	SQL           SQLConfig     `mapstructure:"sql"`
-	Factor2Config Factor2Config `mapstructure:"2factor"`
-	Port          string        `mapstructure:"port"`
+	Log            LogConfig            `mapstructure:"log"`
+	SQL            SQLConfig            `mapstructure:"sql"`
+	Factor2Config  Factor2Config        `mapstructure:"2factor"`
+	Port           string               `mapstructure:"port"`
+	PasswordHash   PasswordHashConfig   `mapstructure:"password_hash"`
+	Email          EmailConfig          `mapstructure:"email"`
+	PasswordReset  PasswordResetConfig  `mapstructure:"password_reset"`
+	PasswordPolicy PasswordPolicyConfig `mapstructure:"password_policy"`
+	OTP            OTPConfig            `mapstructure:"otp"`
+	JWT            JWTConfig            `mapstructure:"jwt"`
+	OIDC           OIDCConfig           `mapstructure:"oidc"`
+	// ExternalIdentity represents the providers that users can sign in with, such as google and apple
+	ExternalIdentity ExternalIdentityConfig `mapstructure:"external_identity"`
+	TwoFactor        TwoFactorConfig        `mapstructure:"two_factor"`
+	WebAuthn         WebAuthnConfig         `mapstructure:"webauthn"`
+	MagicLink        MagicLinkConfig        `mapstructure:"magic_link"`
+	AccountDeletion  AccountDeletionConfig  `mapstructure:"account_deletion"`
+	// TrustedProxies are the ips and cidrs of the proxies whose X-Forwarded-For header is used to find the ip of the
+	// client. The header is ignored when it is not set, and the ip of the connection is used instead.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
 }
 
 func (appConfig AppConfig) Validate() error {
@@ -30,6 +47,21 @@ func (appConfig AppConfig) Validate() error {
 	if err := appConfig.Factor2Config.Validate(); err != nil {
 		return err
 	}
+	if err := appConfig.PasswordHash.Validate(); err != nil {
+		return err
+	}
+	// email is only needed by the features that send emails, which are disabled when their link_url is not set.
+	if appConfig.PasswordReset.LinkURL != "" || appConfig.MagicLink.LinkURL != "" {
+		if err := appConfig.Email.Validate(); err != nil {
+			return err
+		}
+	}
+	if err := appConfig.JWT.Validate(); err != nil {
+		return err
+	}
+	if err := appConfig.WebAuthn.Validate(); err != nil {
+		return err
+	}
 
 	return nil
 }
@@ -130,6 +162,230 @@ func (factorConfig Factor2Config) Validate() error {
 	return nil
 }
 
+// PasswordHashConfig represents the algorithm and parameters used for hashing user passwords.
+// Parameters that are not set fall back to the defaults of the passwordhash package.
+type PasswordHashConfig struct {
+	// algorithm used for new hashes, argon2id (default) or bcrypt
+	Algorithm string         `mapstructure:"algorithm"`
+	Argon2id  Argon2idConfig `mapstructure:"argon2id"`
+	Bcrypt    BcryptConfig   `mapstructure:"bcrypt"`
+}
+
+type Argon2idConfig struct {
+	// memory in KiB
+	Memory      uint32 `mapstructure:"memory"`
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+	SaltLength  uint32 `mapstructure:"salt_length"`
+	KeyLength   uint32 `mapstructure:"key_length"`
+}
+
+type BcryptConfig struct {
+	Cost int `mapstructure:"cost"`
+}
+
+func (passwordHashConfig PasswordHashConfig) Validate() error {
+	switch passwordHashConfig.Algorithm {
+	case "", "argon2id", "bcrypt":
+	default:
+		return errorx.NewSystemError(-1, errors.New("(passwordhashconfig)algorithm must be argon2id or bcrypt"))
+	}
+
+	return nil
+}
+
+// EmailConfig represents the smtp server used for sending emails.
+type EmailConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	From     string `mapstructure:"from"`
+}
+
+func (emailConfig EmailConfig) Validate() error {
+	if emailConfig.Host == "" {
+		return errorx.NewSystemError(-1, errors.New("(emailconfig)host not found"))
+	}
+	if emailConfig.Port == 0 {
+		return errorx.NewSystemError(-1, errors.New("(emailconfig)port not found"))
+	}
+	if emailConfig.From == "" {
+		return errorx.NewSystemError(-1, errors.New("(emailconfig)from not found"))
+	}
+
+	return nil
+}
+
+// PasswordResetConfig represents password resets. Resets by email are disabled when link_url is not set, resets by
+// otp are always available.
+type PasswordResetConfig struct {
+	// LinkURL is the page that the emailed reset link points to. reset_id and token are added as query params.
+	LinkURL string `mapstructure:"link_url"`
+}
+
+// MagicLinkConfig represents sign in with links emailed to users. Magic links are disabled when link_url is not set.
+type MagicLinkConfig struct {
+	// LinkURL is the page that the emailed sign in link points to. The token is added as a query param.
+	LinkURL string `mapstructure:"link_url"`
+}
+
+// AccountDeletionConfig represents the deletion of accounts that users have asked to delete.
+type AccountDeletionConfig struct {
+	// GracePeriod is how long a user has to cancel the deletion of their account. It is 30 days when not set.
+	GracePeriod time.Duration `mapstructure:"grace_period"`
+}
+
+// PasswordPolicyConfig represents the rules for new passwords. Limits that are not set use the defaults of the
+// identity module.
+type PasswordPolicyConfig struct {
+	MinLength     int  `mapstructure:"min_length"`
+	RequireLetter bool `mapstructure:"require_letter"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	// number of previous passwords that cannot be reused
+	HistorySize int `mapstructure:"history_size"`
+}
+
+// OTPConfig represents the limits on otp verification. Limits that are not set use the defaults of the
+// identity module.
+type OTPConfig struct {
+	// number of wrong guesses after which an otp is blocked
+	MaxAttempts int `mapstructure:"max_attempts"`
+	// number of blocked otps within the lockout window after which a mobile number is locked out
+	LockoutThreshold int           `mapstructure:"lockout_threshold"`
+	LockoutWindow    time.Duration `mapstructure:"lockout_window"`
+	// limits on the number of otps sent per mobile number, per client ip and in total
+	SendLimitPerMobile RateLimitConfig `mapstructure:"send_limit_per_mobile"`
+	SendLimitPerIP     RateLimitConfig `mapstructure:"send_limit_per_ip"`
+	SendLimitGlobal    RateLimitConfig `mapstructure:"send_limit_global"`
+	// minimum time between two otps sent to the same mobile number
+	ResendInterval time.Duration `mapstructure:"resend_interval"`
+}
+
+// RateLimitConfig allows requests per window.
+type RateLimitConfig struct {
+	Requests int64         `mapstructure:"requests"`
+	Window   time.Duration `mapstructure:"window"`
+}
+
+// JWTConfig represents the keys that tokens are signed and verified with and the claims they must carry. When no keys
+// are configured, the key pair at identity/keys/1_private.pem and identity/keys/1_public.pem is used with kid 1.
+type JWTConfig struct {
+	Keys []JWTKeyConfig `mapstructure:"keys"`
+	// iss and aud claims of issued tokens, consequent by default
+	Issuer   string `mapstructure:"issuer"`
+	Audience string `mapstructure:"audience"`
+	// tolerance for clock differences when checking exp, nbf and iat, 30 seconds by default
+	ClockSkew time.Duration `mapstructure:"clock_skew"`
+	// where revoked access tokens are kept, sql (default) or memory. The memory denylist is not shared between
+	// instances of the app.
+	DenylistStore string `mapstructure:"denylist_store"`
+	// how often the key files are checked for changes, one minute by default
+	KeyRefreshInterval time.Duration `mapstructure:"key_refresh_interval"`
+}
+
+// OIDCConfig represents the openid connect provider. The jwt issuer must be the base url of the app for clients to
+// discover the provider at /.well-known/openid-configuration.
+type OIDCConfig struct {
+	// sign in page that forwards authorization requests to /oauth/authorize, /oauth/authorize by default
+	AuthorizationEndpoint string `mapstructure:"authorization_endpoint"`
+}
+
+// ExternalIdentityConfig represents the identity providers whose id tokens users can sign in with.
+type ExternalIdentityConfig struct {
+	Providers []ExternalIdentityProviderConfig `mapstructure:"providers"`
+}
+
+// ExternalIdentityProviderConfig represents a single identity provider. The issuers and the jwks url of the google and
+// apple providers are known, and only their client ids have to be set.
+type ExternalIdentityProviderConfig struct {
+	Name string `mapstructure:"name"`
+	// accepted iss claims
+	Issuers []string `mapstructure:"issuers"`
+	// client ids of our apps registered with the provider, accepted as the aud claim
+	ClientIDs []string `mapstructure:"client_ids"`
+	JWKSURL   string   `mapstructure:"jwks_url"`
+}
+
+// TwoFactorConfig represents two-factor authentication with totp.
+type TwoFactorConfig struct {
+	// name that authenticator apps show the account under, consequent by default
+	TOTPIssuer string `mapstructure:"totp_issuer"`
+}
+
+// WebAuthnConfig represents the relying party that passkeys are registered with. Passkeys are disabled when rp_id is
+// not set.
+type WebAuthnConfig struct {
+	// domain that passkeys are scoped to, such as example.com
+	RPID string `mapstructure:"rp_id"`
+	// name shown by authenticators, consequent by default
+	RPName string `mapstructure:"rp_name"`
+	// origins of the pages and apps that passkeys are used from, such as https://example.com
+	Origins []string `mapstructure:"origins"`
+}
+
+func (webAuthnConfig WebAuthnConfig) Validate() error {
+	if webAuthnConfig.RPID != "" && len(webAuthnConfig.Origins) == 0 {
+		return errorx.NewSystemError(-1, errors.New("(webauthnconfig)origins not found"))
+	}
+
+	return nil
+}
+
+// JWTKeyConfig represents a single signing key. A key is rotated out by adding a new active key and moving the
+// previous one to verify_only until the tokens signed by it have expired, after which it can be retired.
+type JWTKeyConfig struct {
+	ID string `mapstructure:"id"`
+	// active, verify_only or retired
+	State string `mapstructure:"state"`
+	// RS256 (default), ES256, EdDSA or HS256. Tokens signed with the key are only accepted with this algorithm.
+	Algorithm      string `mapstructure:"algorithm"`
+	PrivateKeyPath string `mapstructure:"private_key_path"`
+	PublicKeyPath  string `mapstructure:"public_key_path"`
+	// file holding the shared secret of HS256 keys, which only sign service tokens
+	SecretPath string `mapstructure:"secret_path"`
+}
+
+func (jwtConfig JWTConfig) Validate() error {
+	switch jwtConfig.DenylistStore {
+	case "", "sql", "memory":
+	default:
+		return errorx.NewSystemError(-1, errors.New("(jwtconfig)denylist_store must be sql or memory"))
+	}
+
+	activeKeys, activeHMACKeys := 0, 0
+	for _, keyConfig := range jwtConfig.Keys {
+		if keyConfig.ID == "" {
+			return errorx.NewSystemError(-1, errors.New("(jwtconfig)key id not found"))
+		}
+		switch keyConfig.Algorithm {
+		case "", "RS256", "ES256", "EdDSA", "HS256":
+		default:
+			return errorx.NewSystemError(-1, fmt.Errorf("(jwtconfig)key %s algorithm must be RS256, ES256, EdDSA or HS256", keyConfig.ID))
+		}
+		switch keyConfig.State {
+		case "active":
+			if keyConfig.Algorithm == "HS256" {
+				activeHMACKeys++
+			} else {
+				activeKeys++
+			}
+		case "verify_only", "retired":
+		default:
+			return errorx.NewSystemError(-1, fmt.Errorf("(jwtconfig)key %s state must be active, verify_only or retired", keyConfig.ID))
+		}
+	}
+	// user tokens must be verifiable with the jwks, an HS256 key can only be active for service tokens next to them.
+	if len(jwtConfig.Keys) > 0 && activeKeys != 1 {
+		return errorx.NewSystemError(-1, errors.New("(jwtconfig)exactly one RS256, ES256 or EdDSA key must be active"))
+	}
+	if activeHMACKeys > 1 {
+		return errorx.NewSystemError(-1, errors.New("(jwtconfig)at most one HS256 key can be active"))
+	}
+
+	return nil
+}
+
 // Config is ...
 var Config AppConfig
 
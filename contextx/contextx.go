@@ -1,28 +1,74 @@
 package contextx
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 type contextKey string
 
 var (
-	requestIDKey     contextKey = "request_id"
-	requestUserKey   contextKey = "request_user"
-	bearerTokenKey   contextKey = "bearer_token"
-	requestBodyKey   contextKey = "request_body"
-	requestHeaderKey contextKey = "request_header"
-	requestURLKey    contextKey = "request_url"
+	requestIDKey        contextKey = "request_id"
+	requestUserKey      contextKey = "request_user"
+	servicePrincipalKey contextKey = "service_principal"
+	bearerTokenKey      contextKey = "bearer_token"
+	requestBodyKey      contextKey = "request_body"
+	requestHeaderKey    contextKey = "request_header"
+	requestURLKey       contextKey = "request_url"
+	clientIPKey         contextKey = "client_ip"
+	userAgentKey        contextKey = "user_agent"
+	deviceNameKey       contextKey = "device_name"
 )
 
 type RequestUser struct {
 	ID     int64
 	Mobile string
 	Email  string
+	// ClientID and Scope are set when the user is represented by an oauth client.
+	ClientID string
+	Scope    string
+	// TokenID is the jti of the access token that the user was authorised with.
+	TokenID string
+	// APIKeyID is set when the user was authorised with an api key instead of an access token. Scope is then the
+	// scope of the key.
+	APIKeyID int64
+	// Roles and Permissions are those granted to the user.
+	Roles       []string
+	Permissions []string
 }
 
 func (user RequestUser) IsPresent() bool {
 	return user.ID != 0
 }
 
+// IsClient reports whether the request was made by an oauth client on behalf of the user, rather than by the user
+// themselves.
+func (user RequestUser) IsClient() bool {
+	return user.ClientID != ""
+}
+
+// HasScope reports whether the request may use the apis that the scope stands for. Requests that are not limited
+// to a scope, such as those with the access tokens of the user or with api keys without a scope, may use all of them.
+func (user RequestUser) HasScope(scope string) bool {
+	return user.Scope == "" || containsScope(user.Scope, scope)
+}
+
+// HasPermission reports whether the user has been granted the permission. Requests that are limited to a scope,
+// those of oauth clients and of scoped api keys, only have the permissions that are part of the scope as well.
+func (user RequestUser) HasPermission(permission string) bool {
+	if user.Scope != "" && !containsScope(user.Scope, permission) {
+		return false
+	}
+
+	for _, p := range user.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
 func WithRequestID(ctx context.Context, requestID string) context.Context {
 	contextWithRequestID := context.WithValue(ctx, requestIDKey, requestID)
 
@@ -56,6 +102,52 @@ func GetRequestUser(ctx context.Context) RequestUser {
 	return RequestUser{}
 }
 
+// ServicePrincipal is a service client that makes a request on its own behalf, rather than on behalf of a user.
+type ServicePrincipal struct {
+	ClientID string
+	Scope    string
+	// TokenID is the jti of the service token that the service was authorised with.
+	TokenID string
+}
+
+func (principal ServicePrincipal) IsPresent() bool {
+	return principal.ClientID != ""
+}
+
+// HasPermission reports whether the service has been granted the permission, as one of the scopes of its client.
+func (principal ServicePrincipal) HasPermission(permission string) bool {
+	return containsScope(principal.Scope, permission)
+}
+
+// containsScope reports whether the space separated scope contains the value.
+func containsScope(scope string, value string) bool {
+	for _, s := range strings.Fields(scope) {
+		if s == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func WithServicePrincipal(ctx context.Context, principal ServicePrincipal) context.Context {
+	contextWithServicePrincipal := context.WithValue(ctx, servicePrincipalKey, principal)
+
+	return contextWithServicePrincipal
+}
+
+// GetServicePrincipal returns the service client that made the request if it was made by a service rather than by
+// a user.
+func GetServicePrincipal(ctx context.Context) ServicePrincipal {
+	v := ctx.Value(servicePrincipalKey)
+
+	if principal, ok := v.(ServicePrincipal); ok {
+		return principal
+	}
+
+	return ServicePrincipal{}
+}
+
 func WithRequestHeader(ctx context.Context, header interface{}) context.Context {
 	contextWithRequestHeader := context.WithValue(ctx, requestHeaderKey, header)
 
@@ -112,3 +204,54 @@ func GetRequestURL(ctx context.Context) string {
 
 	return ""
 }
+
+func WithClientIP(ctx context.Context, clientIP string) context.Context {
+	contextWithClientIP := context.WithValue(ctx, clientIPKey, clientIP)
+
+	return contextWithClientIP
+}
+
+// GetClientIP returns the ip of the client that made the request if present.
+func GetClientIP(ctx context.Context) string {
+	v := ctx.Value(clientIPKey)
+
+	if clientIP, ok := v.(string); ok {
+		return clientIP
+	}
+
+	return ""
+}
+
+func WithUserAgent(ctx context.Context, userAgent string) context.Context {
+	contextWithUserAgent := context.WithValue(ctx, userAgentKey, userAgent)
+
+	return contextWithUserAgent
+}
+
+// GetUserAgent returns the user agent of the client that made the request if present.
+func GetUserAgent(ctx context.Context) string {
+	v := ctx.Value(userAgentKey)
+
+	if userAgent, ok := v.(string); ok {
+		return userAgent
+	}
+
+	return ""
+}
+
+func WithDeviceName(ctx context.Context, deviceName string) context.Context {
+	contextWithDeviceName := context.WithValue(ctx, deviceNameKey, deviceName)
+
+	return contextWithDeviceName
+}
+
+// GetDeviceName returns the name that the client gave to the device that made the request if present.
+func GetDeviceName(ctx context.Context) string {
+	v := ctx.Value(deviceNameKey)
+
+	if deviceName, ok := v.(string); ok {
+		return deviceName
+	}
+
+	return ""
+}
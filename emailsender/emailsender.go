@@ -0,0 +1,59 @@
+package emailsender
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/devesh2997/consequent/errorx"
+)
+
+type EmailSender interface {
+	Send(ctx context.Context, email string, subject string, body string) error
+}
+
+func NewSMTPEmailSender(host string, port int, username string, password string, from string) EmailSender {
+	return smtpSender{
+		host:     host,
+		port:     port,
+		username: username,
+		password: password,
+		from:     from,
+	}
+}
+
+type smtpSender struct {
+	host     string
+	port     int
+	username string
+	password string
+	from     string
+}
+
+func (s smtpSender) Send(ctx context.Context, email string, subject string, body string) error {
+	addr := fmt.Sprintf("%s:%d", s.host, s.port)
+
+	var auth smtp.Auth
+	if s.username != "" {
+		auth = smtp.PlainAuth("", s.username, s.password, s.host)
+	}
+
+	if err := smtp.SendMail(addr, auth, s.from, []string{email}, s.getMessage(email, subject, body)); err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	return nil
+}
+
+func (s smtpSender) getMessage(email string, subject string, body string) []byte {
+	headers := []string{
+		"From: " + s.from,
+		"To: " + email,
+		"Subject: " + subject,
+		"MIME-Version: 1.0",
+		"Content-Type: text/plain; charset=\"utf-8\"",
+	}
+
+	return []byte(strings.Join(headers, "\r\n") + "\r\n\r\n" + body)
+}
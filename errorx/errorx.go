@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"runtime"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 )
@@ -204,6 +205,26 @@ func (err UnauthorizedError) Error() string {
 	return "unauthorized " + err.msg
 }
 
+type TooManyRequestsError struct {
+	*stacker
+	Code int
+	msg  string
+	// RetryAt is the time after which the request may be retried.
+	RetryAt time.Time
+}
+
+func (tooManyRequestsError TooManyRequestsError) ErrorCode() int {
+	return tooManyRequestsError.Code
+}
+
+func NewTooManyRequestsError(Code int, msg string, retryAt time.Time) TooManyRequestsError {
+	return TooManyRequestsError{newStacker(), Code, msg, retryAt}
+}
+
+func (err TooManyRequestsError) Error() string {
+	return err.msg
+}
+
 type UnmarshallingError struct {
 	*stacker
 	unmarshallerType string
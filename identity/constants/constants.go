@@ -1,11 +1,47 @@
 package constants
 
 const (
-	USER_PASSWORD_STATUS_ACTIVE           = "active"
-	USER_LOGIN_MOBILE_OTP_STATUS_ACTIVE   = "active"
-	USER_LOGIN_MOBILE_OTP_STATUS_VERIFIED = "verified"
-	USER_LOGIN_MOBILE_OTP_STATUS_EXPIRED  = "expired"
-	REFRESH_TOKEN_STATUS_ACTIVE           = "active"
-	REFRESH_TOKEN_STATUS_EXPIRED          = "expired"
-	REFRESH_TOKEN_STATUS_REVOKED          = "revoked"
+	USER_PASSWORD_STATUS_ACTIVE            = "active"
+	USER_PASSWORD_STATUS_INACTIVE          = "inactive"
+	USER_LOGIN_MOBILE_OTP_STATUS_ACTIVE    = "active"
+	USER_LOGIN_MOBILE_OTP_STATUS_VERIFIED  = "verified"
+	USER_LOGIN_MOBILE_OTP_STATUS_EXPIRED   = "expired"
+	USER_LOGIN_MOBILE_OTP_STATUS_BLOCKED   = "blocked"
+	REFRESH_TOKEN_STATUS_ACTIVE            = "active"
+	REFRESH_TOKEN_STATUS_EXPIRED           = "expired"
+	REFRESH_TOKEN_STATUS_REVOKED           = "revoked"
+	REFRESH_TOKEN_STATUS_USED              = "used"
+	PASSWORD_RESET_TOKEN_STATUS_ACTIVE     = "active"
+	PASSWORD_RESET_TOKEN_STATUS_USED       = "used"
+	PASSWORD_RESET_TOKEN_STATUS_EXPIRED    = "expired"
+	PASSWORD_RESET_CHANNEL_OTP             = "otp"
+	PASSWORD_RESET_CHANNEL_EMAIL           = "email"
+	USER_TOTP_STATUS_PENDING               = "pending"
+	USER_TOTP_STATUS_ACTIVE                = "active"
+	USER_RECOVERY_CODE_STATUS_ACTIVE       = "active"
+	USER_RECOVERY_CODE_STATUS_USED         = "used"
+	SECOND_FACTOR_CHALLENGE_STATUS_ACTIVE  = "active"
+	SECOND_FACTOR_CHALLENGE_STATUS_USED    = "used"
+	SECOND_FACTOR_CHALLENGE_STATUS_BLOCKED = "blocked"
+	WEBAUTHN_CEREMONY_REGISTRATION         = "registration"
+	WEBAUTHN_CEREMONY_AUTHENTICATION       = "authentication"
+	WEBAUTHN_CHALLENGE_STATUS_ACTIVE       = "active"
+	WEBAUTHN_CHALLENGE_STATUS_USED         = "used"
+	MAGIC_LINK_TOKEN_STATUS_ACTIVE         = "active"
+	MAGIC_LINK_TOKEN_STATUS_USED           = "used"
+	API_KEY_STATUS_ACTIVE                  = "active"
+	API_KEY_STATUS_REVOKED                 = "revoked"
+	EMAIL_VERIFICATION_STATUS_ACTIVE       = "active"
+	EMAIL_VERIFICATION_STATUS_VERIFIED     = "verified"
+	EMAIL_VERIFICATION_STATUS_EXPIRED      = "expired"
+	EMAIL_VERIFICATION_STATUS_BLOCKED      = "blocked"
+	ACCOUNT_DELETION_STATUS_PENDING        = "pending"
+	ACCOUNT_DELETION_STATUS_CANCELLED      = "cancelled"
+	ACCOUNT_DELETION_STATUS_COMPLETED      = "completed"
+)
+
+// scopes that service clients must be granted to use the identity apis that are meant for resource servers.
+const (
+	// SCOPE_TOKENS_INTROSPECT allows a resource server to introspect tokens (RFC 7662 section 2.1).
+	SCOPE_TOKENS_INTROSPECT = "tokens:introspect"
 )
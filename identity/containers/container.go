@@ -1,13 +1,26 @@
 package containers
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	"github.com/devesh2997/consequent/config"
 	"github.com/devesh2997/consequent/datasources"
+	"github.com/devesh2997/consequent/emailsender"
 	"github.com/devesh2997/consequent/identity/data/repositories"
+	domainRepositories "github.com/devesh2997/consequent/identity/domain/repositories"
 	"github.com/devesh2997/consequent/identity/domain/services"
 	"github.com/devesh2997/consequent/identity/presentation/controllers"
+	"github.com/devesh2997/consequent/idtoken"
+	"github.com/devesh2997/consequent/keymanager"
+	"github.com/devesh2997/consequent/logger"
+	"github.com/devesh2997/consequent/magiclinksender"
 	"github.com/devesh2997/consequent/otpsender"
+	"github.com/devesh2997/consequent/passwordhash"
+	"github.com/devesh2997/consequent/ratelimit"
 	"github.com/devesh2997/consequent/user/containers"
+	"github.com/devesh2997/consequent/webauthn"
 )
 
 func InjectTokenService() services.TokenService {
@@ -17,8 +30,140 @@ func InjectTokenService() services.TokenService {
 	}
 
 	repo := repositories.NewTokenRepo(ds.SQLClients.GetGormDB())
+	userService := containers.InjectUserService()
+	roleService := containers.InjectRoleService()
+
+	return services.NewTokenService(repo, InjectAccessTokenDenylistRepo(), userService, roleService, InjectKeyManager(), InjectTokenPolicy())
+}
+
+func InjectAPIKeyService() services.APIKeyService {
+	ds, err := datasources.Get()
+	if err != nil {
+		panic(err)
+	}
+
+	repo := repositories.NewAPIKeyRepo(ds.SQLClients.GetGormDB())
+
+	return services.NewAPIKeyService(repo, containers.InjectUserService(), containers.InjectRoleService())
+}
+
+const defaultAccountDeletionGracePeriod = time.Hour * 24 * 30
+
+func InjectAccountService() services.AccountService {
+	ds, err := datasources.Get()
+	if err != nil {
+		panic(err)
+	}
+
+	repo := repositories.NewAccountRepo(ds.SQLClients.GetGormDB())
+
+	gracePeriod := config.Config.AccountDeletion.GracePeriod
+	if gracePeriod <= 0 {
+		gracePeriod = defaultAccountDeletionGracePeriod
+	}
+
+	return services.NewAccountService(repo, containers.InjectUserService(), containers.InjectRoleService(), InjectTokenService(), gracePeriod)
+}
+
+func InjectTokenPolicy() services.TokenPolicy {
+	jwtConfig := config.Config.JWT
+
+	return services.NewTokenPolicy(jwtConfig.Issuer, jwtConfig.Audience, jwtConfig.ClockSkew)
+}
+
+var memoryDenylistRepo domainRepositories.AccessTokenDenylistRepo
+var memoryDenylistRepoOnce sync.Once
+
+// InjectAccessTokenDenylistRepo returns the configured denylist of revoked access tokens.
+func InjectAccessTokenDenylistRepo() domainRepositories.AccessTokenDenylistRepo {
+	if config.Config.JWT.DenylistStore == "memory" {
+		memoryDenylistRepoOnce.Do(func() {
+			memoryDenylistRepo = repositories.NewMemoryAccessTokenDenylistRepo()
+		})
+
+		return memoryDenylistRepo
+	}
+
+	ds, err := datasources.Get()
+	if err != nil {
+		panic(err)
+	}
 
-	return services.NewTokenService(repo)
+	return repositories.NewAccessTokenDenylistRepo(ds.SQLClients.GetGormDB())
+}
+
+const defaultKeyRefreshInterval = time.Minute
+
+var keyManager keymanager.KeyManager
+var keyManagerOnce sync.Once
+
+// InjectKeyManager returns the key manager shared by the app. The keys are read when it is first injected and are
+// read again whenever the key files change.
+func InjectKeyManager() keymanager.KeyManager {
+	keyManagerOnce.Do(func() {
+		keyConfigs := []keymanager.KeyConfig{{
+			ID:             "1",
+			State:          keymanager.StateActive,
+			PrivateKeyPath: "identity/keys/1_private.pem",
+			PublicKeyPath:  "identity/keys/1_public.pem",
+		}}
+		if len(config.Config.JWT.Keys) > 0 {
+			keyConfigs = keyConfigs[:0]
+			for _, keyConfig := range config.Config.JWT.Keys {
+				keyConfigs = append(keyConfigs, keymanager.KeyConfig{
+					ID:             keyConfig.ID,
+					State:          keymanager.State(keyConfig.State),
+					Algorithm:      keyConfig.Algorithm,
+					PrivateKeyPath: keyConfig.PrivateKeyPath,
+					PublicKeyPath:  keyConfig.PublicKeyPath,
+					SecretPath:     keyConfig.SecretPath,
+				})
+			}
+		}
+
+		var err error
+		keyManager, err = keymanager.New(keyConfigs)
+		if err != nil {
+			panic(err)
+		}
+
+		refreshInterval := config.Config.JWT.KeyRefreshInterval
+		if refreshInterval <= 0 {
+			refreshInterval = defaultKeyRefreshInterval
+		}
+		keymanager.RefreshEvery(keyManager, refreshInterval, func(err error) {
+			logger.Log.Error(context.Background(), err)
+		})
+	})
+
+	return keyManager
+}
+
+var idTokenVerifier idtoken.Verifier
+var idTokenVerifierOnce sync.Once
+
+// InjectIDTokenVerifier returns the verifier of the id tokens of the configured external identity providers. It is
+// shared so that the jwks of the providers are only fetched once.
+func InjectIDTokenVerifier() idtoken.Verifier {
+	idTokenVerifierOnce.Do(func() {
+		providers := []idtoken.Provider{}
+		for _, providerConfig := range config.Config.ExternalIdentity.Providers {
+			providers = append(providers, idtoken.Provider{
+				Name:      providerConfig.Name,
+				Issuers:   providerConfig.Issuers,
+				ClientIDs: providerConfig.ClientIDs,
+				JWKSURL:   providerConfig.JWKSURL,
+			})
+		}
+
+		var err error
+		idTokenVerifier, err = idtoken.NewVerifier(providers, nil)
+		if err != nil {
+			panic(err)
+		}
+	})
+
+	return idTokenVerifier
 }
 
 func InjectIdentityService() services.IdentityService {
@@ -31,10 +176,78 @@ func InjectIdentityService() services.IdentityService {
 	userService := containers.InjectUserService()
 	tokenService := InjectTokenService()
 	otpSender := otpsender.New2FactorOTPSender(config.Config.Factor2Config.APIKey, config.Config.Factor2Config.OTPTemplateName)
+	emailConfig := config.Config.Email
+	emailSender := emailsender.NewSMTPEmailSender(emailConfig.Host, emailConfig.Port, emailConfig.Username, emailConfig.Password, emailConfig.From)
+	passwordHasher := InjectPasswordHasher()
+	policyConfig := config.Config.PasswordPolicy
+	passwordPolicy := services.NewPasswordPolicy(policyConfig.MinLength, policyConfig.RequireLetter, policyConfig.RequireDigit, policyConfig.HistorySize)
+
+	otpConfig := config.Config.OTP
+	otpPolicy := services.NewOTPPolicy(otpConfig.MaxAttempts, otpConfig.LockoutThreshold, otpConfig.LockoutWindow).
+		WithSendLimits(
+			ratelimit.Limit{Requests: otpConfig.SendLimitPerMobile.Requests, Window: otpConfig.SendLimitPerMobile.Window},
+			ratelimit.Limit{Requests: otpConfig.SendLimitPerIP.Requests, Window: otpConfig.SendLimitPerIP.Window},
+			ratelimit.Limit{Requests: otpConfig.SendLimitGlobal.Requests, Window: otpConfig.SendLimitGlobal.Window},
+			otpConfig.ResendInterval,
+		)
+	rateLimiter := ratelimit.NewLimiter(InjectRateLimitCounterStore())
+
+	totpIssuer := config.Config.TwoFactor.TOTPIssuer
+	if totpIssuer == "" {
+		totpIssuer = "consequent"
+	}
+
+	webAuthnConfig := config.Config.WebAuthn
+	relyingParty := webauthn.RelyingParty{ID: webAuthnConfig.RPID, Name: webAuthnConfig.RPName, Origins: webAuthnConfig.Origins}
+	if relyingParty.Name == "" {
+		relyingParty.Name = "consequent"
+	}
+
+	return services.NewIdentityService(repo, userService, tokenService, otpSender, emailSender, passwordHasher, passwordPolicy, otpPolicy, rateLimiter, InjectIDTokenVerifier(), totpIssuer, relyingParty, magiclinksender.NewEmailMagicLinkSender(emailSender), config.Config.PasswordReset.LinkURL, config.Config.MagicLink.LinkURL)
+}
+
+var counterStore ratelimit.CounterStore
+var counterStoreOnce sync.Once
+
+// InjectRateLimitCounterStore returns the counter store shared by every rate limiter of the app.
+func InjectRateLimitCounterStore() ratelimit.CounterStore {
+	counterStoreOnce.Do(func() {
+		counterStore = ratelimit.NewMemoryCounterStore()
+	})
+
+	return counterStore
+}
+
+func InjectPasswordHasher() passwordhash.PasswordHasher {
+	hashConfig := config.Config.PasswordHash
+	argon2idParams := passwordhash.Argon2idParams{
+		Memory:      hashConfig.Argon2id.Memory,
+		Iterations:  hashConfig.Argon2id.Iterations,
+		Parallelism: hashConfig.Argon2id.Parallelism,
+		SaltLength:  hashConfig.Argon2id.SaltLength,
+		KeyLength:   hashConfig.Argon2id.KeyLength,
+	}
+
+	passwordHasher, err := passwordhash.NewWithAlgorithm(hashConfig.Algorithm, argon2idParams, hashConfig.Bcrypt.Cost)
+	if err != nil {
+		panic(err)
+	}
+
+	return passwordHasher
+}
+
+func InjectKeyController() controllers.KeyController {
+	return controllers.NewKeyController(InjectKeyManager())
+}
+
+func InjectAPIKeyController() controllers.APIKeyController {
+	return controllers.NewAPIKeyController(InjectAPIKeyService())
+}
 
-	return services.NewIdentityService(repo, userService, tokenService, otpSender)
+func InjectAccountController() controllers.AccountController {
+	return controllers.NewAccountController(InjectAccountService())
 }
 
 func InjectIdentityController() controllers.IdentityController {
-	return controllers.NewIdentityController(InjectIdentityService())
+	return controllers.NewIdentityController(InjectIdentityService(), InjectTokenService())
 }
@@ -1,7 +1,19 @@
 package constants
 
 const (
-	TABLE_NAME_REFRESH_TOKENS         = "refresh_tokens"
-	TABLE_NAME_USER_PASSWORDS         = "user_passwords"
-	TABLE_NAME_USER_LOGIN_MOBILE_OTPS = "user_login_mobile_otps"
+	TABLE_NAME_REFRESH_TOKENS            = "refresh_tokens"
+	TABLE_NAME_USER_PASSWORDS            = "user_passwords"
+	TABLE_NAME_USER_LOGIN_MOBILE_OTPS    = "user_login_mobile_otps"
+	TABLE_NAME_PASSWORD_RESET_TOKENS     = "password_reset_tokens"
+	TABLE_NAME_ACCESS_TOKEN_DENYLIST     = "access_token_denylist"
+	TABLE_NAME_USER_EXTERNAL_IDENTITIES  = "user_external_identities"
+	TABLE_NAME_USER_TOTPS                = "user_totps"
+	TABLE_NAME_USER_RECOVERY_CODES       = "user_recovery_codes"
+	TABLE_NAME_SECOND_FACTOR_CHALLENGES  = "second_factor_challenges"
+	TABLE_NAME_WEBAUTHN_CREDENTIALS      = "webauthn_credentials"
+	TABLE_NAME_WEBAUTHN_CHALLENGES       = "webauthn_challenges"
+	TABLE_NAME_MAGIC_LINK_TOKENS         = "magic_link_tokens"
+	TABLE_NAME_API_KEYS                  = "api_keys"
+	TABLE_NAME_EMAIL_VERIFICATIONS       = "email_verifications"
+	TABLE_NAME_ACCOUNT_DELETION_REQUESTS = "account_deletion_requests"
 )
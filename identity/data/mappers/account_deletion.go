@@ -0,0 +1,127 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	oauthMappers "github.com/devesh2997/consequent/oauth/data/mappers"
+	oauthModels "github.com/devesh2997/consequent/oauth/data/models"
+	userMappers "github.com/devesh2997/consequent/user/data/mappers"
+	userModels "github.com/devesh2997/consequent/user/data/models"
+)
+
+type accountDeletionRequestMapper struct{}
+
+func NewAccountDeletionRequestMapper() accountDeletionRequestMapper {
+	return accountDeletionRequestMapper{}
+}
+
+func (accountDeletionRequestMapper) ToModel(entity entities.AccountDeletionRequest) models.AccountDeletionRequest {
+	return models.AccountDeletionRequest{
+		ID:          entity.ID,
+		UserID:      entity.UserID,
+		Status:      entity.Status,
+		ScheduledAt: entity.ScheduledAt,
+		CompletedAt: entity.CompletedAt,
+		CreatedAt:   entity.CreatedAt,
+		UpdatedAt:   entity.UpdatedAt,
+	}
+}
+
+func (accountDeletionRequestMapper) ToEntity(model models.AccountDeletionRequest) entities.AccountDeletionRequest {
+	return entities.AccountDeletionRequest{
+		ID:          model.ID,
+		UserID:      model.UserID,
+		Status:      model.Status,
+		ScheduledAt: model.ScheduledAt,
+		CompletedAt: model.CompletedAt,
+		CreatedAt:   model.CreatedAt,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
+
+type accountExportMapper struct{}
+
+func NewAccountExportMapper() accountExportMapper {
+	return accountExportMapper{}
+}
+
+// ToModel leaves out the secrets of the export, i.e. password hashes, otps and refresh tokens.
+func (accountExportMapper) ToModel(entity entities.AccountExport) models.AccountExport {
+	model := models.AccountExport{
+		User:               userMappers.NewUserMapper().ToModel(entity.User),
+		Roles:              make([]userModels.Role, 0, len(entity.Roles)),
+		Passwords:          make([]models.ExportedPassword, 0, len(entity.Passwords)),
+		MobileOTPs:         make([]models.ExportedMobileOTP, 0, len(entity.MobileOTPs)),
+		RefreshTokens:      make([]models.ExportedRefreshToken, 0, len(entity.RefreshTokens)),
+		PasswordResets:     make([]models.PasswordResetToken, 0, len(entity.PasswordResets)),
+		MagicLinks:         make([]models.MagicLinkToken, 0, len(entity.MagicLinks)),
+		EmailVerifications: make([]models.EmailVerification, 0, len(entity.EmailVerifications)),
+		ExternalIdentities: make([]models.UserExternalIdentity, 0, len(entity.ExternalIdentities)),
+		TOTPs:              make([]models.UserTOTP, 0, len(entity.TOTPs)),
+		Passkeys:           make([]models.WebAuthnCredential, 0, len(entity.Passkeys)),
+		APIKeys:            make([]models.APIKey, 0, len(entity.APIKeys)),
+		OAuthConsents:      make([]oauthModels.Consent, 0, len(entity.OAuthConsents)),
+		DeletionRequests:   make([]models.AccountDeletionRequest, 0, len(entity.DeletionRequests)),
+		ExportedAt:         entity.ExportedAt,
+	}
+
+	for _, role := range entity.Roles {
+		model.Roles = append(model.Roles, userMappers.NewRoleMapper().ToModel(role))
+	}
+	for _, password := range entity.Passwords {
+		model.Passwords = append(model.Passwords, models.ExportedPassword{ID: password.ID, Status: password.Status})
+	}
+	for _, otp := range entity.MobileOTPs {
+		model.MobileOTPs = append(model.MobileOTPs, models.ExportedMobileOTP{
+			ID:             otp.ID,
+			Mobile:         otp.Mobile,
+			Status:         otp.Status,
+			FailedAttempts: otp.FailedAttempts,
+			CreatedAt:      otp.CreatedAt,
+			ExpiryAt:       otp.ExpiryAt,
+		})
+	}
+	for _, refreshToken := range entity.RefreshTokens {
+		model.RefreshTokens = append(model.RefreshTokens, models.ExportedRefreshToken{
+			SessionID:        refreshToken.FamilyID,
+			ClientID:         refreshToken.ClientID,
+			Scope:            refreshToken.Scope,
+			DeviceName:       refreshToken.DeviceName,
+			UserAgent:        refreshToken.UserAgent,
+			IPAddress:        refreshToken.IPAddress,
+			Status:           refreshToken.Status,
+			SessionCreatedAt: refreshToken.SessionCreatedAt,
+			CreatedAt:        refreshToken.CreatedAt,
+			ExpiryAt:         refreshToken.ExpiryAt,
+		})
+	}
+	for _, passwordReset := range entity.PasswordResets {
+		model.PasswordResets = append(model.PasswordResets, NewPasswordResetTokenMapper().ToModel(passwordReset))
+	}
+	for _, magicLink := range entity.MagicLinks {
+		model.MagicLinks = append(model.MagicLinks, NewMagicLinkTokenMapper().ToModel(magicLink))
+	}
+	for _, emailVerification := range entity.EmailVerifications {
+		model.EmailVerifications = append(model.EmailVerifications, NewEmailVerificationMapper().ToModel(emailVerification))
+	}
+	for _, externalIdentity := range entity.ExternalIdentities {
+		model.ExternalIdentities = append(model.ExternalIdentities, NewUserExternalIdentityMapper().ToModel(externalIdentity))
+	}
+	for _, totp := range entity.TOTPs {
+		model.TOTPs = append(model.TOTPs, NewUserTOTPMapper().ToModel(totp))
+	}
+	for _, passkey := range entity.Passkeys {
+		model.Passkeys = append(model.Passkeys, NewWebAuthnCredentialMapper().ToModel(passkey))
+	}
+	for _, apiKey := range entity.APIKeys {
+		model.APIKeys = append(model.APIKeys, NewAPIKeyMapper().ToModel(apiKey))
+	}
+	for _, consent := range entity.OAuthConsents {
+		model.OAuthConsents = append(model.OAuthConsents, oauthMappers.NewConsentMapper().ToModel(consent))
+	}
+	for _, request := range entity.DeletionRequests {
+		model.DeletionRequests = append(model.DeletionRequests, NewAccountDeletionRequestMapper().ToModel(request))
+	}
+
+	return model
+}
@@ -0,0 +1,62 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+type apiKeyMapper struct{}
+
+func NewAPIKeyMapper() apiKeyMapper {
+	return apiKeyMapper{}
+}
+
+func (apiKeyMapper) ToModel(entity entities.APIKey) models.APIKey {
+	return models.APIKey{
+		ID:        entity.ID,
+		UserID:    entity.UserID,
+		Name:      entity.Name,
+		Prefix:    entity.Prefix,
+		KeyHash:   entity.KeyHash,
+		Scope:     entity.Scope,
+		Status:    entity.Status,
+		ExpiryAt:  entity.ExpiryAt,
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func (apiKeyMapper) ToEntity(model models.APIKey) entities.APIKey {
+	return entities.APIKey{
+		ID:        model.ID,
+		UserID:    model.UserID,
+		Name:      model.Name,
+		Prefix:    model.Prefix,
+		KeyHash:   model.KeyHash,
+		Scope:     model.Scope,
+		Status:    model.Status,
+		ExpiryAt:  model.ExpiryAt,
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
+
+type createdAPIKeyMapper struct{}
+
+func NewCreatedAPIKeyMapper() createdAPIKeyMapper {
+	return createdAPIKeyMapper{}
+}
+
+func (createdAPIKeyMapper) ToModel(entity entities.CreatedAPIKey) models.CreatedAPIKey {
+	return models.CreatedAPIKey{
+		APIKey: NewAPIKeyMapper().ToModel(entity.APIKey),
+		Key:    entity.Key,
+	}
+}
+
+func (createdAPIKeyMapper) ToEntity(model models.CreatedAPIKey) entities.CreatedAPIKey {
+	return entities.CreatedAPIKey{
+		APIKey: NewAPIKeyMapper().ToEntity(model.APIKey),
+		Key:    model.Key,
+	}
+}
@@ -0,0 +1,44 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+type emailVerificationMapper struct{}
+
+func NewEmailVerificationMapper() emailVerificationMapper {
+	return emailVerificationMapper{}
+}
+
+func (emailVerificationMapper) ToModel(entity entities.EmailVerification) models.EmailVerification {
+	return models.EmailVerification{
+		ID:             entity.ID,
+		VerificationID: entity.VerificationID,
+		UserID:         entity.UserID,
+		Email:          entity.Email,
+		CodeHash:       entity.CodeHash,
+		Status:         entity.Status,
+		FailedAttempts: entity.FailedAttempts,
+		MaxAttempts:    entity.MaxAttempts,
+		CreatedAt:      entity.CreatedAt,
+		ExpiryAt:       entity.ExpiryAt,
+		UpdatedAt:      entity.UpdatedAt,
+	}
+}
+
+func (emailVerificationMapper) ToEntity(model models.EmailVerification) entities.EmailVerification {
+	return entities.EmailVerification{
+		ID:             model.ID,
+		VerificationID: model.VerificationID,
+		UserID:         model.UserID,
+		Email:          model.Email,
+		CodeHash:       model.CodeHash,
+		Status:         model.Status,
+		FailedAttempts: model.FailedAttempts,
+		MaxAttempts:    model.MaxAttempts,
+		CreatedAt:      model.CreatedAt,
+		ExpiryAt:       model.ExpiryAt,
+		UpdatedAt:      model.UpdatedAt,
+	}
+}
@@ -0,0 +1,36 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+type magicLinkTokenMapper struct{}
+
+func NewMagicLinkTokenMapper() magicLinkTokenMapper {
+	return magicLinkTokenMapper{}
+}
+
+func (magicLinkTokenMapper) ToModel(entity entities.MagicLinkToken) models.MagicLinkToken {
+	return models.MagicLinkToken{
+		ID:        entity.ID,
+		TokenID:   entity.TokenID,
+		Email:     entity.Email,
+		Status:    entity.Status,
+		CreatedAt: entity.CreatedAt,
+		ExpiryAt:  entity.ExpiryAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func (magicLinkTokenMapper) ToEntity(model models.MagicLinkToken) entities.MagicLinkToken {
+	return entities.MagicLinkToken{
+		ID:        model.ID,
+		TokenID:   model.TokenID,
+		Email:     model.Email,
+		Status:    model.Status,
+		CreatedAt: model.CreatedAt,
+		ExpiryAt:  model.ExpiryAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
@@ -0,0 +1,42 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+type passwordResetTokenMapper struct{}
+
+func NewPasswordResetTokenMapper() passwordResetTokenMapper {
+	return passwordResetTokenMapper{}
+}
+
+func (passwordResetTokenMapper) ToModel(entity entities.PasswordResetToken) models.PasswordResetToken {
+	return models.PasswordResetToken{
+		ID:        entity.ID,
+		UserID:    entity.UserID,
+		ResetID:   entity.ResetID,
+		TokenHash: entity.TokenHash,
+		Channel:   entity.Channel,
+		Attempts:  entity.Attempts,
+		Status:    entity.Status,
+		CreatedAt: entity.CreatedAt,
+		ExpiryAt:  entity.ExpiryAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func (passwordResetTokenMapper) ToEntity(model models.PasswordResetToken) entities.PasswordResetToken {
+	return entities.PasswordResetToken{
+		ID:        model.ID,
+		UserID:    model.UserID,
+		ResetID:   model.ResetID,
+		TokenHash: model.TokenHash,
+		Channel:   model.Channel,
+		Attempts:  model.Attempts,
+		Status:    model.Status,
+		CreatedAt: model.CreatedAt,
+		ExpiryAt:  model.ExpiryAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
@@ -0,0 +1,46 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+type secondFactorChallengeMapper struct{}
+
+func NewSecondFactorChallengeMapper() secondFactorChallengeMapper {
+	return secondFactorChallengeMapper{}
+}
+
+func (secondFactorChallengeMapper) ToModel(entity entities.SecondFactorChallenge) models.SecondFactorChallenge {
+	return models.SecondFactorChallenge{
+		ID:        entity.ID,
+		UserID:    entity.UserID,
+		TokenHash: entity.TokenHash,
+		Attempts:  entity.Attempts,
+		Status:    entity.Status,
+		CreatedAt: entity.CreatedAt,
+		ExpiryAt:  entity.ExpiryAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func (secondFactorChallengeMapper) ToEntity(model models.SecondFactorChallenge) entities.SecondFactorChallenge {
+	return entities.SecondFactorChallenge{
+		ID:        model.ID,
+		UserID:    model.UserID,
+		TokenHash: model.TokenHash,
+		Attempts:  model.Attempts,
+		Status:    model.Status,
+		CreatedAt: model.CreatedAt,
+		ExpiryAt:  model.ExpiryAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
+
+func (secondFactorChallengeMapper) ToResponseModel(result entities.SignInResult) models.SecondFactorChallengeResponse {
+	return models.SecondFactorChallengeResponse{
+		SecondFactorRequired: true,
+		ChallengeToken:       result.ChallengeToken,
+		ExpiryAt:             result.ChallengeExpiryAt,
+	}
+}
@@ -0,0 +1,40 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+type sessionMapper struct{}
+
+func NewSessionMapper() sessionMapper {
+	return sessionMapper{}
+}
+
+func (sessionMapper) ToEntity(model models.Session) entities.Session {
+	return entities.Session{
+		ID:         model.ID,
+		ClientID:   model.ClientID,
+		DeviceName: model.DeviceName,
+		UserAgent:  model.UserAgent,
+		IPAddress:  model.IPAddress,
+		CreatedAt:  model.CreatedAt,
+		LastUsedAt: model.LastUsedAt,
+		ExpiryAt:   model.ExpiryAt,
+		IsCurrent:  model.IsCurrent,
+	}
+}
+
+func (sessionMapper) ToModel(entity entities.Session) models.Session {
+	return models.Session{
+		ID:         entity.ID,
+		ClientID:   entity.ClientID,
+		DeviceName: entity.DeviceName,
+		UserAgent:  entity.UserAgent,
+		IPAddress:  entity.IPAddress,
+		CreatedAt:  entity.CreatedAt,
+		LastUsedAt: entity.LastUsedAt,
+		ExpiryAt:   entity.ExpiryAt,
+		IsCurrent:  entity.IsCurrent,
+	}
+}
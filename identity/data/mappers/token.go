@@ -13,23 +13,43 @@ func NewRefreshTokenMapper() refreshTokenMapper {
 
 func (refreshTokenMapper) ToEntity(model models.RefreshToken) entities.RefreshToken {
 	return entities.RefreshToken{
-		ID:        model.ID,
-		Token:     model.Token,
-		Status:    model.Status,
-		CreatedAt: model.CreatedAt,
-		ExpiryAt:  model.ExpiryAt,
-		UpdatedAt: model.UpdatedAt,
+		ID:                  model.ID,
+		UserID:              model.UserID,
+		Token:               model.Token,
+		FamilyID:            model.FamilyID,
+		AccessTokenID:       model.AccessTokenID,
+		AccessTokenExpiryAt: model.AccessTokenExpiryAt,
+		ClientID:            model.ClientID,
+		Scope:               model.Scope,
+		UserAgent:           model.UserAgent,
+		IPAddress:           model.IPAddress,
+		DeviceName:          model.DeviceName,
+		SessionCreatedAt:    model.SessionCreatedAt,
+		Status:              model.Status,
+		CreatedAt:           model.CreatedAt,
+		ExpiryAt:            model.ExpiryAt,
+		UpdatedAt:           model.UpdatedAt,
 	}
 }
 
 func (refreshTokenMapper) ToModel(entity entities.RefreshToken) models.RefreshToken {
 	return models.RefreshToken{
-		ID:        entity.ID,
-		Token:     entity.Token,
-		Status:    entity.Status,
-		CreatedAt: entity.CreatedAt,
-		ExpiryAt:  entity.ExpiryAt,
-		UpdatedAt: entity.UpdatedAt,
+		ID:                  entity.ID,
+		UserID:              entity.UserID,
+		Token:               entity.Token,
+		FamilyID:            entity.FamilyID,
+		AccessTokenID:       entity.AccessTokenID,
+		AccessTokenExpiryAt: entity.AccessTokenExpiryAt,
+		ClientID:            entity.ClientID,
+		Scope:               entity.Scope,
+		UserAgent:           entity.UserAgent,
+		IPAddress:           entity.IPAddress,
+		DeviceName:          entity.DeviceName,
+		SessionCreatedAt:    entity.SessionCreatedAt,
+		Status:              entity.Status,
+		CreatedAt:           entity.CreatedAt,
+		ExpiryAt:            entity.ExpiryAt,
+		UpdatedAt:           entity.UpdatedAt,
 	}
 }
 
@@ -0,0 +1,60 @@
+package mappers
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+type tokenIntrospectionMapper struct{}
+
+func NewTokenIntrospectionMapper() tokenIntrospectionMapper {
+	return tokenIntrospectionMapper{}
+}
+
+func (tokenIntrospectionMapper) ToEntity(model models.TokenIntrospection) entities.TokenIntrospection {
+	return entities.TokenIntrospection{
+		Active:    model.Active,
+		TokenType: model.TokenType,
+		Subject:   model.Subject,
+		Username:  model.Username,
+		Issuer:    model.Issuer,
+		Audience:  model.Audience,
+		TokenID:   model.TokenID,
+		IssuedAt:  fromUnix(model.IssuedAt),
+		NotBefore: fromUnix(model.NotBefore),
+		ExpiryAt:  fromUnix(model.ExpiryAt),
+	}
+}
+
+func (tokenIntrospectionMapper) ToModel(entity entities.TokenIntrospection) models.TokenIntrospection {
+	return models.TokenIntrospection{
+		Active:    entity.Active,
+		TokenType: entity.TokenType,
+		Subject:   entity.Subject,
+		Username:  entity.Username,
+		Issuer:    entity.Issuer,
+		Audience:  entity.Audience,
+		TokenID:   entity.TokenID,
+		IssuedAt:  toUnix(entity.IssuedAt),
+		NotBefore: toUnix(entity.NotBefore),
+		ExpiryAt:  toUnix(entity.ExpiryAt),
+	}
+}
+
+func fromUnix(seconds int64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+
+	return time.Unix(seconds, 0)
+}
+
+func toUnix(t time.Time) int64 {
+	if t.IsZero() {
+		return 0
+	}
+
+	return t.Unix()
+}
@@ -0,0 +1,36 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+type userExternalIdentityMapper struct{}
+
+func NewUserExternalIdentityMapper() userExternalIdentityMapper {
+	return userExternalIdentityMapper{}
+}
+
+func (userExternalIdentityMapper) ToModel(entity entities.UserExternalIdentity) models.UserExternalIdentity {
+	return models.UserExternalIdentity{
+		ID:        entity.ID,
+		UserID:    entity.UserID,
+		Provider:  entity.Provider,
+		Subject:   entity.Subject,
+		Email:     entity.Email,
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
+
+func (userExternalIdentityMapper) ToEntity(model models.UserExternalIdentity) entities.UserExternalIdentity {
+	return entities.UserExternalIdentity{
+		ID:        model.ID,
+		UserID:    model.UserID,
+		Provider:  model.Provider,
+		Subject:   model.Subject,
+		Email:     model.Email,
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
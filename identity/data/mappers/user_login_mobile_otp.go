@@ -18,6 +18,8 @@ func (userLoginMobileOTP) ToModel(entity entities.UserLoginMobileOTP) models.Use
 		Mobile:         entity.Mobile,
 		OTP:            entity.OTP,
 		Status:         entity.Status,
+		FailedAttempts: entity.FailedAttempts,
+		MaxAttempts:    entity.MaxAttempts,
 		CreatedAt:      entity.CreatedAt,
 		ExpiryAt:       entity.ExpiryAt,
 		UpdatedAt:      entity.UpdatedAt,
@@ -31,6 +33,8 @@ func (userLoginMobileOTP) ToEntity(model models.UserLoginMobileOTP) entities.Use
 		Mobile:         model.Mobile,
 		OTP:            model.OTP,
 		Status:         model.Status,
+		FailedAttempts: model.FailedAttempts,
+		MaxAttempts:    model.MaxAttempts,
 		CreatedAt:      model.CreatedAt,
 		ExpiryAt:       model.ExpiryAt,
 		UpdatedAt:      model.UpdatedAt,
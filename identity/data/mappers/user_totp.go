@@ -0,0 +1,66 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+type userTOTPMapper struct{}
+
+func NewUserTOTPMapper() userTOTPMapper {
+	return userTOTPMapper{}
+}
+
+func (userTOTPMapper) ToModel(entity entities.UserTOTP) models.UserTOTP {
+	return models.UserTOTP{
+		ID:              entity.ID,
+		UserID:          entity.UserID,
+		Secret:          entity.Secret,
+		Status:          entity.Status,
+		LastUsedCounter: entity.LastUsedCounter,
+		CreatedAt:       entity.CreatedAt,
+		UpdatedAt:       entity.UpdatedAt,
+	}
+}
+
+func (userTOTPMapper) ToEntity(model models.UserTOTP) entities.UserTOTP {
+	return entities.UserTOTP{
+		ID:              model.ID,
+		UserID:          model.UserID,
+		Secret:          model.Secret,
+		Status:          model.Status,
+		LastUsedCounter: model.LastUsedCounter,
+		CreatedAt:       model.CreatedAt,
+		UpdatedAt:       model.UpdatedAt,
+	}
+}
+
+type totpEnrollmentMapper struct{}
+
+func NewTOTPEnrollmentMapper() totpEnrollmentMapper {
+	return totpEnrollmentMapper{}
+}
+
+func (totpEnrollmentMapper) ToModel(entity entities.TOTPEnrollment) models.TOTPEnrollment {
+	return models.TOTPEnrollment{
+		Secret: entity.Secret,
+		URI:    entity.URI,
+	}
+}
+
+type userRecoveryCodeMapper struct{}
+
+func NewUserRecoveryCodeMapper() userRecoveryCodeMapper {
+	return userRecoveryCodeMapper{}
+}
+
+func (userRecoveryCodeMapper) ToModel(entity entities.UserRecoveryCode) models.UserRecoveryCode {
+	return models.UserRecoveryCode{
+		ID:        entity.ID,
+		UserID:    entity.UserID,
+		CodeHash:  entity.CodeHash,
+		Status:    entity.Status,
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
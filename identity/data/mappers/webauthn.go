@@ -0,0 +1,96 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+type webAuthnCredentialMapper struct{}
+
+func NewWebAuthnCredentialMapper() webAuthnCredentialMapper {
+	return webAuthnCredentialMapper{}
+}
+
+func (webAuthnCredentialMapper) ToModel(entity entities.WebAuthnCredential) models.WebAuthnCredential {
+	return models.WebAuthnCredential{
+		ID:                entity.ID,
+		UserID:            entity.UserID,
+		CredentialID:      entity.CredentialID,
+		PublicKey:         entity.PublicKey,
+		SignCount:         entity.SignCount,
+		AAGUID:            entity.AAGUID,
+		AttestationFormat: entity.AttestationFormat,
+		LastUsedAt:        entity.LastUsedAt,
+		CreatedAt:         entity.CreatedAt,
+		UpdatedAt:         entity.UpdatedAt,
+	}
+}
+
+func (webAuthnCredentialMapper) ToEntity(model models.WebAuthnCredential) entities.WebAuthnCredential {
+	return entities.WebAuthnCredential{
+		ID:                model.ID,
+		UserID:            model.UserID,
+		CredentialID:      model.CredentialID,
+		PublicKey:         model.PublicKey,
+		SignCount:         model.SignCount,
+		AAGUID:            model.AAGUID,
+		AttestationFormat: model.AttestationFormat,
+		LastUsedAt:        model.LastUsedAt,
+		CreatedAt:         model.CreatedAt,
+		UpdatedAt:         model.UpdatedAt,
+	}
+}
+
+type webAuthnChallengeMapper struct{}
+
+func NewWebAuthnChallengeMapper() webAuthnChallengeMapper {
+	return webAuthnChallengeMapper{}
+}
+
+func (webAuthnChallengeMapper) ToModel(entity entities.WebAuthnChallenge) models.WebAuthnChallenge {
+	return models.WebAuthnChallenge{
+		ID:          entity.ID,
+		ChallengeID: entity.ChallengeID,
+		Challenge:   entity.Challenge,
+		UserID:      entity.UserID,
+		Ceremony:    entity.Ceremony,
+		Status:      entity.Status,
+		CreatedAt:   entity.CreatedAt,
+		ExpiryAt:    entity.ExpiryAt,
+		UpdatedAt:   entity.UpdatedAt,
+	}
+}
+
+func (webAuthnChallengeMapper) ToEntity(model models.WebAuthnChallenge) entities.WebAuthnChallenge {
+	return entities.WebAuthnChallenge{
+		ID:          model.ID,
+		ChallengeID: model.ChallengeID,
+		Challenge:   model.Challenge,
+		UserID:      model.UserID,
+		Ceremony:    model.Ceremony,
+		Status:      model.Status,
+		CreatedAt:   model.CreatedAt,
+		ExpiryAt:    model.ExpiryAt,
+		UpdatedAt:   model.UpdatedAt,
+	}
+}
+
+type passkeyOptionsMapper struct{}
+
+func NewPasskeyOptionsMapper() passkeyOptionsMapper {
+	return passkeyOptionsMapper{}
+}
+
+func (passkeyOptionsMapper) ToRegistrationModel(entity entities.PasskeyRegistrationOptions) models.PasskeyRegistrationOptions {
+	return models.PasskeyRegistrationOptions{
+		ChallengeID: entity.ChallengeID,
+		PublicKey:   entity.Options,
+	}
+}
+
+func (passkeyOptionsMapper) ToLoginModel(entity entities.PasskeyLoginOptions) models.PasskeyLoginOptions {
+	return models.PasskeyLoginOptions{
+		ChallengeID: entity.ChallengeID,
+		PublicKey:   entity.Options,
+	}
+}
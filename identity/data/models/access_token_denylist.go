@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/constants"
+)
+
+type DeniedAccessToken struct {
+	ID        int64     `json:"id" gorm:"column:id"`
+	TokenID   string    `json:"token_id" gorm:"column:token_id"`
+	ExpiryAt  time.Time `json:"expiry_at" gorm:"column:expiry_at"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+func (DeniedAccessToken) TableName() string {
+	return constants.TABLE_NAME_ACCESS_TOKEN_DENYLIST
+}
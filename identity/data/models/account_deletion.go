@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/constants"
+	oauthModels "github.com/devesh2997/consequent/oauth/data/models"
+	userModels "github.com/devesh2997/consequent/user/data/models"
+)
+
+type AccountDeletionRequest struct {
+	ID          int64      `json:"id" gorm:"column:id"`
+	UserID      int64      `json:"-" gorm:"column:user_id"`
+	Status      string     `json:"status" gorm:"column:status"`
+	ScheduledAt time.Time  `json:"scheduled_at" gorm:"column:scheduled_at"`
+	CompletedAt *time.Time `json:"completed_at" gorm:"column:completed_at"`
+	CreatedAt   time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt   time.Time  `json:"-" gorm:"column:updated_at"`
+}
+
+func (AccountDeletionRequest) TableName() string {
+	return constants.TABLE_NAME_ACCOUNT_DELETION_REQUESTS
+}
+
+type AccountExport struct {
+	User               userModels.User          `json:"user"`
+	Roles              []userModels.Role        `json:"roles"`
+	Passwords          []ExportedPassword       `json:"passwords"`
+	MobileOTPs         []ExportedMobileOTP      `json:"mobile_otps"`
+	RefreshTokens      []ExportedRefreshToken   `json:"refresh_tokens"`
+	PasswordResets     []PasswordResetToken     `json:"password_resets"`
+	MagicLinks         []MagicLinkToken         `json:"magic_links"`
+	EmailVerifications []EmailVerification      `json:"email_verifications"`
+	ExternalIdentities []UserExternalIdentity   `json:"external_identities"`
+	TOTPs              []UserTOTP               `json:"totps"`
+	Passkeys           []WebAuthnCredential     `json:"passkeys"`
+	APIKeys            []APIKey                 `json:"api_keys"`
+	OAuthConsents      []oauthModels.Consent    `json:"oauth_consents"`
+	DeletionRequests   []AccountDeletionRequest `json:"deletion_requests"`
+	ExportedAt         time.Time                `json:"exported_at"`
+}
+
+// ExportedPassword is a password of a user without its hash.
+type ExportedPassword struct {
+	ID     int64  `json:"id"`
+	Status string `json:"status"`
+}
+
+// ExportedMobileOTP is an otp sent to the mobile of a user without the otp itself.
+type ExportedMobileOTP struct {
+	ID             int64     `json:"id"`
+	Mobile         string    `json:"mobile"`
+	Status         string    `json:"status"`
+	FailedAttempts int       `json:"failed_attempts"`
+	CreatedAt      time.Time `json:"created_at"`
+	ExpiryAt       time.Time `json:"expiry_at"`
+}
+
+// ExportedRefreshToken is a refresh token issued to a user without the token itself.
+type ExportedRefreshToken struct {
+	SessionID        string    `json:"session_id"`
+	ClientID         string    `json:"client_id,omitempty"`
+	Scope            string    `json:"scope,omitempty"`
+	DeviceName       string    `json:"device_name"`
+	UserAgent        string    `json:"user_agent"`
+	IPAddress        string    `json:"ip_address"`
+	Status           string    `json:"status"`
+	SessionCreatedAt time.Time `json:"session_created_at"`
+	CreatedAt        time.Time `json:"created_at"`
+	ExpiryAt         time.Time `json:"expiry_at"`
+}
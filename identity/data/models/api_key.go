@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/constants"
+)
+
+type APIKey struct {
+	ID        int64      `json:"id" gorm:"column:id"`
+	UserID    int64      `json:"-" gorm:"column:user_id"`
+	Name      string     `json:"name" gorm:"column:name"`
+	Prefix    string     `json:"prefix" gorm:"column:prefix"`
+	KeyHash   string     `json:"-" gorm:"column:key_hash"`
+	Scope     string     `json:"scope" gorm:"column:scope"`
+	Status    string     `json:"status" gorm:"column:status"`
+	ExpiryAt  *time.Time `json:"expiry_at" gorm:"column:expiry_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time  `json:"-" gorm:"column:updated_at"`
+}
+
+func (APIKey) TableName() string {
+	return constants.TABLE_NAME_API_KEYS
+}
+
+type CreatedAPIKey struct {
+	APIKey APIKey `json:"api_key"`
+	Key    string `json:"key"`
+}
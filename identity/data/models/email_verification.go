@@ -0,0 +1,25 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/constants"
+)
+
+type EmailVerification struct {
+	ID             int64     `json:"id" gorm:"column:id"`
+	VerificationID string    `json:"verification_id" gorm:"column:verification_id"`
+	UserID         int64     `json:"user_id" gorm:"column:user_id"`
+	Email          string    `json:"email" gorm:"column:email"`
+	CodeHash       string    `json:"-" gorm:"column:code_hash"`
+	Status         string    `json:"status" gorm:"column:status"`
+	FailedAttempts int       `json:"failed_attempts" gorm:"column:failed_attempts"`
+	MaxAttempts    int       `json:"max_attempts" gorm:"column:max_attempts"`
+	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at"`
+	ExpiryAt       time.Time `json:"expiry_at" gorm:"column:expiry_at"`
+	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (EmailVerification) TableName() string {
+	return constants.TABLE_NAME_EMAIL_VERIFICATIONS
+}
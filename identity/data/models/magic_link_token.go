@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/constants"
+)
+
+type MagicLinkToken struct {
+	ID        int64     `json:"id" gorm:"column:id"`
+	TokenID   string    `json:"token_id" gorm:"column:token_id"`
+	Email     string    `json:"email" gorm:"column:email"`
+	Status    string    `json:"status" gorm:"column:status"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	ExpiryAt  time.Time `json:"expiry_at" gorm:"column:expiry_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (MagicLinkToken) TableName() string {
+	return constants.TABLE_NAME_MAGIC_LINK_TOKENS
+}
@@ -0,0 +1,24 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/constants"
+)
+
+type PasswordResetToken struct {
+	ID        int64     `json:"id" gorm:"column:id"`
+	UserID    int64     `json:"user_id" gorm:"column:user_id"`
+	ResetID   string    `json:"reset_id" gorm:"column:reset_id"`
+	TokenHash string    `json:"-" gorm:"column:token_hash"`
+	Channel   string    `json:"channel" gorm:"column:channel"`
+	Attempts  int       `json:"attempts" gorm:"column:attempts"`
+	Status    string    `json:"status" gorm:"column:status"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	ExpiryAt  time.Time `json:"expiry_at" gorm:"column:expiry_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (PasswordResetToken) TableName() string {
+	return constants.TABLE_NAME_PASSWORD_RESET_TOKENS
+}
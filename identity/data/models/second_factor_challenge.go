@@ -0,0 +1,28 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/constants"
+)
+
+type SecondFactorChallenge struct {
+	ID        int64     `json:"id" gorm:"column:id"`
+	UserID    int64     `json:"user_id" gorm:"column:user_id"`
+	TokenHash string    `json:"-" gorm:"column:token_hash"`
+	Attempts  int       `json:"attempts" gorm:"column:attempts"`
+	Status    string    `json:"status" gorm:"column:status"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	ExpiryAt  time.Time `json:"expiry_at" gorm:"column:expiry_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (SecondFactorChallenge) TableName() string {
+	return constants.TABLE_NAME_SECOND_FACTOR_CHALLENGES
+}
+
+type SecondFactorChallengeResponse struct {
+	SecondFactorRequired bool      `json:"second_factor_required"`
+	ChallengeToken       string    `json:"challenge_token"`
+	ExpiryAt             time.Time `json:"expiry_at"`
+}
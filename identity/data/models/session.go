@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+type Session struct {
+	ID         string    `json:"id"`
+	ClientID   string    `json:"client_id,omitempty"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IPAddress  string    `json:"ip_address"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	ExpiryAt   time.Time `json:"expiry_at"`
+	IsCurrent  bool      `json:"is_current"`
+}
@@ -12,12 +12,22 @@ type JWT struct {
 }
 
 type RefreshToken struct {
-	ID        int64     `json:"-" gorm:"column:id"`
-	Token     string    `json:"token" gorm:"column:token"`
-	Status    string    `json:"-" gorm:"column:status"`
-	CreatedAt time.Time `json:"-" gorm:"column:created_at"`
-	ExpiryAt  time.Time `json:"expiry_at" gorm:"column:expiry_at"`
-	UpdatedAt time.Time `json:"-" gorm:"column:updated_at"`
+	ID                  int64     `json:"-" gorm:"column:id"`
+	UserID              int64     `json:"-" gorm:"column:user_id"`
+	Token               string    `json:"token" gorm:"column:token"`
+	FamilyID            string    `json:"-" gorm:"column:family_id"`
+	AccessTokenID       string    `json:"-" gorm:"column:access_token_id"`
+	AccessTokenExpiryAt time.Time `json:"-" gorm:"column:access_token_expiry_at"`
+	ClientID            string    `json:"-" gorm:"column:client_id"`
+	Scope               string    `json:"-" gorm:"column:scope"`
+	UserAgent           string    `json:"-" gorm:"column:user_agent"`
+	IPAddress           string    `json:"-" gorm:"column:ip_address"`
+	DeviceName          string    `json:"-" gorm:"column:device_name"`
+	SessionCreatedAt    time.Time `json:"-" gorm:"column:session_created_at"`
+	Status              string    `json:"-" gorm:"column:status"`
+	CreatedAt           time.Time `json:"-" gorm:"column:created_at"`
+	ExpiryAt            time.Time `json:"expiry_at" gorm:"column:expiry_at"`
+	UpdatedAt           time.Time `json:"-" gorm:"column:updated_at"`
 }
 
 func (RefreshToken) TableName() string {
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/constants"
+)
+
+type UserExternalIdentity struct {
+	ID        int64     `json:"id" gorm:"column:id"`
+	UserID    int64     `json:"user_id" gorm:"column:user_id"`
+	Provider  string    `json:"provider" gorm:"column:provider"`
+	Subject   string    `json:"subject" gorm:"column:subject"`
+	Email     string    `json:"email" gorm:"column:email"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (UserExternalIdentity) TableName() string {
+	return constants.TABLE_NAME_USER_EXTERNAL_IDENTITIES
+}
@@ -12,6 +12,8 @@ type UserLoginMobileOTP struct {
 	Mobile         string    `json:"mobile" gorm:"column:mobile"`
 	OTP            int       `json:"otp" gorm:"column:otp"`
 	Status         string    `json:"status" gorm:"column:status"`
+	FailedAttempts int       `json:"failed_attempts" gorm:"column:failed_attempts"`
+	MaxAttempts    int       `json:"max_attempts" gorm:"column:max_attempts"`
 	CreatedAt      time.Time `json:"created_at" gorm:"column:created_at"`
 	ExpiryAt       time.Time `json:"expiry_at" gorm:"column:expiry_at"`
 	UpdatedAt      time.Time `json:"updated_at" gorm:"column:updated_at"`
@@ -0,0 +1,43 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/constants"
+)
+
+type UserTOTP struct {
+	ID              int64     `json:"id" gorm:"column:id"`
+	UserID          int64     `json:"user_id" gorm:"column:user_id"`
+	Secret          string    `json:"-" gorm:"column:secret"`
+	Status          string    `json:"status" gorm:"column:status"`
+	LastUsedCounter int64     `json:"-" gorm:"column:last_used_counter"`
+	CreatedAt       time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt       time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (UserTOTP) TableName() string {
+	return constants.TABLE_NAME_USER_TOTPS
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type UserRecoveryCode struct {
+	ID        int64     `json:"id" gorm:"column:id"`
+	UserID    int64     `json:"user_id" gorm:"column:user_id"`
+	CodeHash  string    `json:"-" gorm:"column:code_hash"`
+	Status    string    `json:"status" gorm:"column:status"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (UserRecoveryCode) TableName() string {
+	return constants.TABLE_NAME_USER_RECOVERY_CODES
+}
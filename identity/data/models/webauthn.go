@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/constants"
+	"github.com/devesh2997/consequent/webauthn"
+)
+
+type WebAuthnCredential struct {
+	ID                int64     `json:"id" gorm:"column:id"`
+	UserID            int64     `json:"user_id" gorm:"column:user_id"`
+	CredentialID      string    `json:"credential_id" gorm:"column:credential_id"`
+	PublicKey         []byte    `json:"-" gorm:"column:public_key"`
+	SignCount         int64     `json:"-" gorm:"column:sign_count"`
+	AAGUID            string    `json:"aaguid" gorm:"column:aaguid"`
+	AttestationFormat string    `json:"attestation_format" gorm:"column:attestation_format"`
+	LastUsedAt        time.Time `json:"last_used_at" gorm:"column:last_used_at"`
+	CreatedAt         time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt         time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (WebAuthnCredential) TableName() string {
+	return constants.TABLE_NAME_WEBAUTHN_CREDENTIALS
+}
+
+type WebAuthnChallenge struct {
+	ID          int64     `json:"id" gorm:"column:id"`
+	ChallengeID string    `json:"challenge_id" gorm:"column:challenge_id"`
+	Challenge   string    `json:"-" gorm:"column:challenge"`
+	UserID      int64     `json:"user_id" gorm:"column:user_id"`
+	Ceremony    string    `json:"ceremony" gorm:"column:ceremony"`
+	Status      string    `json:"status" gorm:"column:status"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at"`
+	ExpiryAt    time.Time `json:"expiry_at" gorm:"column:expiry_at"`
+	UpdatedAt   time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (WebAuthnChallenge) TableName() string {
+	return constants.TABLE_NAME_WEBAUTHN_CHALLENGES
+}
+
+type PasskeyRegistrationOptions struct {
+	ChallengeID string                   `json:"challenge_id"`
+	PublicKey   webauthn.CreationOptions `json:"public_key"`
+}
+
+type PasskeyLoginOptions struct {
+	ChallengeID string                  `json:"challenge_id"`
+	PublicKey   webauthn.RequestOptions `json:"public_key"`
+}
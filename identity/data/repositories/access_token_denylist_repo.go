@@ -0,0 +1,51 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type accessTokenDenylistRepo struct {
+	db *gorm.DB
+}
+
+// NewAccessTokenDenylistRepo returns a denylist kept in the database, which is shared by all instances of the app.
+func NewAccessTokenDenylistRepo(db *gorm.DB) repositories.AccessTokenDenylistRepo {
+	return accessTokenDenylistRepo{db: db}
+}
+
+func (repo accessTokenDenylistRepo) Deny(ctx context.Context, tokenID string, expiryAt time.Time) error {
+	now := time.Now()
+	if !expiryAt.After(now) {
+		return nil
+	}
+
+	deniedToken := models.DeniedAccessToken{TokenID: tokenID, ExpiryAt: expiryAt, CreatedAt: now}
+	err := repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&deniedToken).Error
+	if err != nil {
+		return err
+	}
+
+	// entries of expired tokens are not needed anymore.
+	err = repo.db.Where("expiry_at <= ?", now).Delete(&models.DeniedAccessToken{}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo accessTokenDenylistRepo) IsDenied(ctx context.Context, tokenID string) (bool, error) {
+	deniedToken := models.DeniedAccessToken{}
+	res := repo.db.Where("token_id = ? AND expiry_at > ?", tokenID, time.Now()).Limit(1).Find(&deniedToken)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
@@ -0,0 +1,261 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/devesh2997/consequent/identity/constants"
+	"github.com/devesh2997/consequent/identity/data/mappers"
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	oauthMappers "github.com/devesh2997/consequent/oauth/data/mappers"
+	oauthModels "github.com/devesh2997/consequent/oauth/data/models"
+	userConstants "github.com/devesh2997/consequent/user/constants"
+	userDataConstants "github.com/devesh2997/consequent/user/data/constants"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+	"gorm.io/gorm"
+)
+
+type accountRepo struct {
+	db *gorm.DB
+}
+
+func NewAccountRepo(db *gorm.DB) repositories.AccountRepo {
+	return accountRepo{db: db}
+}
+
+func (repo accountRepo) SaveAccountDeletionRequest(ctx context.Context, request entities.AccountDeletionRequest) error {
+	model := mappers.NewAccountDeletionRequestMapper().ToModel(request)
+	model.UpdatedAt = time.Now()
+	if err := repo.db.Save(&model).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo accountRepo) GetPendingAccountDeletionRequest(ctx context.Context, userID int64) (*entities.AccountDeletionRequest, error) {
+	request := models.AccountDeletionRequest{}
+	res := repo.db.Where("user_id = ? AND status = ?", userID, constants.ACCOUNT_DELETION_STATUS_PENDING).
+		Order("id DESC").
+		Limit(1).
+		Find(&request)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrAccountDeletionRequestNotFound
+	}
+
+	entity := mappers.NewAccountDeletionRequestMapper().ToEntity(request)
+
+	return &entity, nil
+}
+
+func (repo accountRepo) CancelAccountDeletionRequest(ctx context.Context, userID int64) (bool, error) {
+	res := repo.db.Model(&models.AccountDeletionRequest{}).
+		Where("user_id = ? AND status = ?", userID, constants.ACCOUNT_DELETION_STATUS_PENDING).
+		Updates(map[string]interface{}{
+			"status":     constants.ACCOUNT_DELETION_STATUS_CANCELLED,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected > 0, nil
+}
+
+func (repo accountRepo) GetDueAccountDeletionRequests(ctx context.Context, before time.Time, afterID int64, limit int) ([]entities.AccountDeletionRequest, error) {
+	requests := []models.AccountDeletionRequest{}
+	err := repo.db.Where("status = ? AND scheduled_at <= ? AND id > ?", constants.ACCOUNT_DELETION_STATUS_PENDING, before, afterID).
+		Order("id").
+		Limit(limit).
+		Find(&requests).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	requestEntities := make([]entities.AccountDeletionRequest, 0, len(requests))
+	for _, request := range requests {
+		requestEntities = append(requestEntities, mappers.NewAccountDeletionRequestMapper().ToEntity(request))
+	}
+
+	return requestEntities, nil
+}
+
+func (repo accountRepo) DeleteAccount(ctx context.Context, user userEntities.User, requestID int64) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		// the request is completed first, so that an account is not deleted after its deletion has been cancelled.
+		if requestID != 0 {
+			completedAt := time.Now()
+			res := tx.Model(&models.AccountDeletionRequest{}).
+				Where("id = ? AND status = ?", requestID, constants.ACCOUNT_DELETION_STATUS_PENDING).
+				Updates(map[string]interface{}{
+					"status":       constants.ACCOUNT_DELETION_STATUS_COMPLETED,
+					"completed_at": completedAt,
+					"updated_at":   completedAt,
+				})
+			if res.Error != nil {
+				return res.Error
+			}
+			if res.RowsAffected == 0 {
+				return repositories.ErrAccountDeletionRequestNotFound
+			}
+		}
+
+		for _, table := range userTables {
+			if err := tx.Table(table).Where("user_id = ?", user.ID).Delete(map[string]interface{}{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// otps and magic links are held against the mobile and email rather than the user.
+		if user.Mobile != "" {
+			if err := tx.Where("mobile = ?", user.Mobile).Delete(&models.UserLoginMobileOTP{}).Error; err != nil {
+				return err
+			}
+		}
+		if user.Email != "" {
+			if err := tx.Where("email = ?", user.Email).Delete(&models.MagicLinkToken{}).Error; err != nil {
+				return err
+			}
+		}
+
+		// the row of the user is kept, so that its id is never reused, but nothing that identifies the user is left.
+		err := tx.Table(userDataConstants.TABLE_NAME_USERS).Where("id = ?", user.ID).Updates(map[string]interface{}{
+			"email":          "",
+			"email_verified": false,
+			"mobile":         "",
+			"name":           "",
+			"gender":         "",
+			"status":         userConstants.USER_STATUS_DELETED,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		// the identifiers of merged users are cleared when they are merged, the rest of their personal data is not.
+		return tx.Table(userDataConstants.TABLE_NAME_USERS).Where("merged_into_id = ?", user.ID).Updates(map[string]interface{}{
+			"name":   "",
+			"gender": "",
+		}).Error
+	})
+}
+
+func (repo accountRepo) GetAccountExport(ctx context.Context, user userEntities.User) (*entities.AccountExport, error) {
+	export := entities.AccountExport{User: user, ExportedAt: time.Now()}
+
+	passwords := []models.UserPassword{}
+	if err := repo.findUserRows(user.ID, &passwords); err != nil {
+		return nil, err
+	}
+	for _, password := range passwords {
+		export.Passwords = append(export.Passwords, mappers.NewUserPasswordMapper().ToEntity(password))
+	}
+
+	if user.Mobile != "" {
+		otps := []models.UserLoginMobileOTP{}
+		if err := repo.db.Where("mobile = ?", user.Mobile).Order("id").Find(&otps).Error; err != nil {
+			return nil, err
+		}
+		for _, otp := range otps {
+			export.MobileOTPs = append(export.MobileOTPs, mappers.NewUserLoginMobileOTP().ToEntity(otp))
+		}
+	}
+
+	refreshTokens := []models.RefreshToken{}
+	if err := repo.findUserRows(user.ID, &refreshTokens); err != nil {
+		return nil, err
+	}
+	for _, refreshToken := range refreshTokens {
+		export.RefreshTokens = append(export.RefreshTokens, mappers.NewRefreshTokenMapper().ToEntity(refreshToken))
+	}
+
+	passwordResets := []models.PasswordResetToken{}
+	if err := repo.findUserRows(user.ID, &passwordResets); err != nil {
+		return nil, err
+	}
+	for _, passwordReset := range passwordResets {
+		export.PasswordResets = append(export.PasswordResets, mappers.NewPasswordResetTokenMapper().ToEntity(passwordReset))
+	}
+
+	if user.Email != "" {
+		magicLinks := []models.MagicLinkToken{}
+		if err := repo.db.Where("email = ?", user.Email).Order("id").Find(&magicLinks).Error; err != nil {
+			return nil, err
+		}
+		for _, magicLink := range magicLinks {
+			export.MagicLinks = append(export.MagicLinks, mappers.NewMagicLinkTokenMapper().ToEntity(magicLink))
+		}
+	}
+
+	emailVerifications := []models.EmailVerification{}
+	if err := repo.findUserRows(user.ID, &emailVerifications); err != nil {
+		return nil, err
+	}
+	for _, emailVerification := range emailVerifications {
+		export.EmailVerifications = append(export.EmailVerifications, mappers.NewEmailVerificationMapper().ToEntity(emailVerification))
+	}
+
+	externalIdentities := []models.UserExternalIdentity{}
+	if err := repo.findUserRows(user.ID, &externalIdentities); err != nil {
+		return nil, err
+	}
+	for _, externalIdentity := range externalIdentities {
+		export.ExternalIdentities = append(export.ExternalIdentities, mappers.NewUserExternalIdentityMapper().ToEntity(externalIdentity))
+	}
+
+	totps := []models.UserTOTP{}
+	if err := repo.findUserRows(user.ID, &totps); err != nil {
+		return nil, err
+	}
+	for _, totp := range totps {
+		export.TOTPs = append(export.TOTPs, mappers.NewUserTOTPMapper().ToEntity(totp))
+	}
+
+	passkeys := []models.WebAuthnCredential{}
+	if err := repo.findUserRows(user.ID, &passkeys); err != nil {
+		return nil, err
+	}
+	for _, passkey := range passkeys {
+		export.Passkeys = append(export.Passkeys, mappers.NewWebAuthnCredentialMapper().ToEntity(passkey))
+	}
+
+	apiKeys := []models.APIKey{}
+	if err := repo.findUserRows(user.ID, &apiKeys); err != nil {
+		return nil, err
+	}
+	for _, apiKey := range apiKeys {
+		export.APIKeys = append(export.APIKeys, mappers.NewAPIKeyMapper().ToEntity(apiKey))
+	}
+
+	consents := []oauthModels.Consent{}
+	if err := repo.findUserRows(user.ID, &consents); err != nil {
+		return nil, err
+	}
+	for _, consent := range consents {
+		export.OAuthConsents = append(export.OAuthConsents, oauthMappers.NewConsentMapper().ToEntity(consent))
+	}
+
+	requests := []models.AccountDeletionRequest{}
+	if err := repo.findUserRows(user.ID, &requests); err != nil {
+		return nil, err
+	}
+	for _, request := range requests {
+		export.DeletionRequests = append(export.DeletionRequests, mappers.NewAccountDeletionRequestMapper().ToEntity(request))
+	}
+
+	return &export, nil
+}
+
+// findUserRows reads the rows of the user from the table of the models, oldest first.
+func (repo accountRepo) findUserRows(userID int64, rows interface{}) error {
+	err := repo.db.Where("user_id = ?", userID).Order("id").Find(rows).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return err
+	}
+
+	return nil
+}
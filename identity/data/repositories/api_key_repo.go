@@ -0,0 +1,77 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/devesh2997/consequent/identity/constants"
+	"github.com/devesh2997/consequent/identity/data/mappers"
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"gorm.io/gorm"
+)
+
+type apiKeyRepo struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepo(db *gorm.DB) repositories.APIKeyRepo {
+	return apiKeyRepo{db: db}
+}
+
+func (repo apiKeyRepo) SaveAPIKey(ctx context.Context, apiKey entities.APIKey) error {
+	model := mappers.NewAPIKeyMapper().ToModel(apiKey)
+	model.UpdatedAt = time.Now()
+	if err := repo.db.Save(&model).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo apiKeyRepo) GetAPIKeyByHash(ctx context.Context, keyHash string) (*entities.APIKey, error) {
+	apiKey := models.APIKey{}
+	res := repo.db.Where("key_hash = ?", keyHash).Find(&apiKey)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrAPIKeyNotFound
+	}
+
+	entity := mappers.NewAPIKeyMapper().ToEntity(apiKey)
+
+	return &entity, nil
+}
+
+func (repo apiKeyRepo) GetUserAPIKeys(ctx context.Context, userID int64) ([]entities.APIKey, error) {
+	apiKeys := []models.APIKey{}
+	err := repo.db.Where("user_id = ? AND status <> ?", userID, constants.API_KEY_STATUS_REVOKED).
+		Order("id DESC").
+		Find(&apiKeys).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	apiKeyEntities := make([]entities.APIKey, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		apiKeyEntities = append(apiKeyEntities, mappers.NewAPIKeyMapper().ToEntity(apiKey))
+	}
+
+	return apiKeyEntities, nil
+}
+
+func (repo apiKeyRepo) RevokeAPIKey(ctx context.Context, userID int64, id int64) (bool, error) {
+	res := repo.db.Model(&models.APIKey{}).
+		Where("id = ? AND user_id = ? AND status = ?", id, userID, constants.API_KEY_STATUS_ACTIVE).
+		Updates(map[string]interface{}{
+			"status":     constants.API_KEY_STATUS_REVOKED,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
@@ -2,8 +2,10 @@ package repositories
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"github.com/devesh2997/consequent/errorx"
 	"github.com/devesh2997/consequent/identity/constants"
 	"github.com/devesh2997/consequent/identity/data/mappers"
 	"github.com/devesh2997/consequent/identity/data/models"
@@ -22,9 +24,12 @@ type identityRepo struct {
 
 func (repo identityRepo) GetActiveUserPassword(ctx context.Context, userID int64) (*entities.UserPassword, error) {
 	userPassword := models.UserPassword{}
-	err := repo.db.Where("status = ?", constants.USER_PASSWORD_STATUS_ACTIVE).First(&userPassword).Error
-	if err != nil {
-		return nil, err
+	res := repo.db.Where("user_id = ? AND status = ?", userID, constants.USER_PASSWORD_STATUS_ACTIVE).Find(&userPassword)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrUserPasswordNotFound
 	}
 
 	userPasswordEntity := mappers.NewUserPasswordMapper().ToEntity(userPassword)
@@ -32,6 +37,49 @@ func (repo identityRepo) GetActiveUserPassword(ctx context.Context, userID int64
 	return &userPasswordEntity, nil
 }
 
+func (repo identityRepo) GetRecentUserPasswords(ctx context.Context, userID int64, limit int) ([]entities.UserPassword, error) {
+	userPasswords := []models.UserPassword{}
+	err := repo.db.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&userPasswords).Error
+	if err != nil {
+		return nil, err
+	}
+
+	userPasswordEntities := make([]entities.UserPassword, 0, len(userPasswords))
+	for _, userPassword := range userPasswords {
+		userPasswordEntities = append(userPasswordEntities, mappers.NewUserPasswordMapper().ToEntity(userPassword))
+	}
+
+	return userPasswordEntities, nil
+}
+
+func (repo identityRepo) GetUserPasswordsAfterID(ctx context.Context, afterID int64, limit int) ([]entities.UserPassword, error) {
+	userPasswords := []models.UserPassword{}
+	err := repo.db.Where("id > ?", afterID).Order("id").Limit(limit).Find(&userPasswords).Error
+	if err != nil {
+		return nil, err
+	}
+
+	userPasswordEntities := make([]entities.UserPassword, 0, len(userPasswords))
+	for _, userPassword := range userPasswords {
+		userPasswordEntities = append(userPasswordEntities, mappers.NewUserPasswordMapper().ToEntity(userPassword))
+	}
+
+	return userPasswordEntities, nil
+}
+
+func (repo identityRepo) UpdateUserPassword(ctx context.Context, userPassword entities.UserPassword) error {
+	if userPassword.ID == 0 {
+		return errorx.NewSystemError(-1, errors.New("user password id is required"))
+	}
+	userPasswordModel := mappers.NewUserPasswordMapper().ToModel(userPassword)
+	err := repo.db.Save(&userPasswordModel).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
 func (repo identityRepo) SaveUserPassword(ctx context.Context, userPassword entities.UserPassword) error {
 	userPasswordModel := mappers.NewUserPasswordMapper().ToModel(userPassword)
 	err := repo.db.Create(&userPasswordModel).Error
@@ -63,3 +111,461 @@ func (repo identityRepo) GetUserLoginMobileOTP(ctx context.Context, verification
 
 	return &entity, nil
 }
+
+func (repo identityRepo) RecordUserLoginMobileOTPFailure(ctx context.Context, id int64) (bool, error) {
+	res := repo.db.Model(&models.UserLoginMobileOTP{}).
+		Where("id = ? AND status = ? AND failed_attempts < max_attempts", id, constants.USER_LOGIN_MOBILE_OTP_STATUS_ACTIVE).
+		Updates(map[string]interface{}{
+			"failed_attempts": gorm.Expr("failed_attempts + 1"),
+			"updated_at":      time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+
+	err := repo.db.Model(&models.UserLoginMobileOTP{}).
+		Where("id = ? AND failed_attempts >= max_attempts", id).
+		Update("status", constants.USER_LOGIN_MOBILE_OTP_STATUS_BLOCKED).Error
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo identityRepo) MarkUserLoginMobileOTPVerified(ctx context.Context, id int64) (bool, error) {
+	res := repo.db.Model(&models.UserLoginMobileOTP{}).
+		Where("id = ? AND status = ? AND failed_attempts < max_attempts", id, constants.USER_LOGIN_MOBILE_OTP_STATUS_ACTIVE).
+		Updates(map[string]interface{}{
+			"status":     constants.USER_LOGIN_MOBILE_OTP_STATUS_VERIFIED,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (repo identityRepo) CountUserLoginMobileOTPs(ctx context.Context, mobile string, status string, updatedSince time.Time) (int64, error) {
+	var count int64
+	err := repo.db.Model(&models.UserLoginMobileOTP{}).
+		Where("mobile = ? AND status = ? AND updated_at >= ?", mobile, status, updatedSince).
+		Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (repo identityRepo) SavePasswordResetToken(ctx context.Context, token entities.PasswordResetToken) error {
+	model := mappers.NewPasswordResetTokenMapper().ToModel(token)
+	model.UpdatedAt = time.Now()
+	if err := repo.db.Save(&model).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo identityRepo) GetPasswordResetToken(ctx context.Context, resetID string) (*entities.PasswordResetToken, error) {
+	token := models.PasswordResetToken{}
+	res := repo.db.Where("reset_id = ?", resetID).Find(&token)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrPasswordResetTokenNotFound
+	}
+
+	entity := mappers.NewPasswordResetTokenMapper().ToEntity(token)
+
+	return &entity, nil
+}
+
+func (repo identityRepo) RecordPasswordResetFailure(ctx context.Context, id int64, maxAttempts int) error {
+	err := repo.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND status = ?", id, constants.PASSWORD_RESET_TOKEN_STATUS_ACTIVE).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"updated_at": time.Now(),
+		}).Error
+	if err != nil {
+		return err
+	}
+
+	return repo.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND status = ? AND attempts >= ?", id, constants.PASSWORD_RESET_TOKEN_STATUS_ACTIVE, maxAttempts).
+		Update("status", constants.PASSWORD_RESET_TOKEN_STATUS_EXPIRED).Error
+}
+
+func (repo identityRepo) MarkPasswordResetTokenUsed(ctx context.Context, id int64) (bool, error) {
+	res := repo.db.Model(&models.PasswordResetToken{}).
+		Where("id = ? AND status = ? AND expiry_at > ?", id, constants.PASSWORD_RESET_TOKEN_STATUS_ACTIVE, time.Now()).
+		Updates(map[string]interface{}{
+			"status":     constants.PASSWORD_RESET_TOKEN_STATUS_USED,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (repo identityRepo) GetUserExternalIdentity(ctx context.Context, provider string, subject string) (*entities.UserExternalIdentity, error) {
+	identity := models.UserExternalIdentity{}
+	res := repo.db.Where("provider = ? AND subject = ?", provider, subject).Find(&identity)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrUserExternalIdentityNotFound
+	}
+
+	entity := mappers.NewUserExternalIdentityMapper().ToEntity(identity)
+
+	return &entity, nil
+}
+
+func (repo identityRepo) SaveUserExternalIdentity(ctx context.Context, identity entities.UserExternalIdentity) error {
+	model := mappers.NewUserExternalIdentityMapper().ToModel(identity)
+	model.UpdatedAt = time.Now()
+	if err := repo.db.Save(&model).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo identityRepo) GetUserTOTP(ctx context.Context, userID int64) (*entities.UserTOTP, error) {
+	userTOTP := models.UserTOTP{}
+	res := repo.db.Where("user_id = ?", userID).Find(&userTOTP)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrUserTOTPNotFound
+	}
+
+	entity := mappers.NewUserTOTPMapper().ToEntity(userTOTP)
+
+	return &entity, nil
+}
+
+func (repo identityRepo) SaveUserTOTP(ctx context.Context, userTOTP entities.UserTOTP) error {
+	model := mappers.NewUserTOTPMapper().ToModel(userTOTP)
+	model.UpdatedAt = time.Now()
+	if err := repo.db.Save(&model).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo identityRepo) MarkUserTOTPCounterUsed(ctx context.Context, id int64, counter int64) (bool, error) {
+	res := repo.db.Model(&models.UserTOTP{}).
+		Where("id = ? AND last_used_counter < ?", id, counter).
+		Updates(map[string]interface{}{
+			"last_used_counter": counter,
+			"updated_at":        time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (repo identityRepo) ReplaceUserRecoveryCodes(ctx context.Context, userID int64, codes []entities.UserRecoveryCode) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		codeModels := make([]models.UserRecoveryCode, 0, len(codes))
+		for _, code := range codes {
+			codeModels = append(codeModels, mappers.NewUserRecoveryCodeMapper().ToModel(code))
+		}
+		if len(codeModels) == 0 {
+			return nil
+		}
+
+		return tx.Create(&codeModels).Error
+	})
+}
+
+func (repo identityRepo) UseUserRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	res := repo.db.Model(&models.UserRecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND status = ?", userID, codeHash, constants.USER_RECOVERY_CODE_STATUS_ACTIVE).
+		Updates(map[string]interface{}{
+			"status":     constants.USER_RECOVERY_CODE_STATUS_USED,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (repo identityRepo) SaveSecondFactorChallenge(ctx context.Context, challenge entities.SecondFactorChallenge) error {
+	model := mappers.NewSecondFactorChallengeMapper().ToModel(challenge)
+	model.UpdatedAt = time.Now()
+	if err := repo.db.Save(&model).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo identityRepo) GetSecondFactorChallenge(ctx context.Context, tokenHash string) (*entities.SecondFactorChallenge, error) {
+	challenge := models.SecondFactorChallenge{}
+	res := repo.db.Where("token_hash = ?", tokenHash).Find(&challenge)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrSecondFactorChallengeNotFound
+	}
+
+	entity := mappers.NewSecondFactorChallengeMapper().ToEntity(challenge)
+
+	return &entity, nil
+}
+
+func (repo identityRepo) RecordSecondFactorChallengeFailure(ctx context.Context, id int64, maxAttempts int) (bool, error) {
+	res := repo.db.Model(&models.SecondFactorChallenge{}).
+		Where("id = ? AND status = ? AND attempts < ?", id, constants.SECOND_FACTOR_CHALLENGE_STATUS_ACTIVE, maxAttempts).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+
+	err := repo.db.Model(&models.SecondFactorChallenge{}).
+		Where("id = ? AND attempts >= ?", id, maxAttempts).
+		Update("status", constants.SECOND_FACTOR_CHALLENGE_STATUS_BLOCKED).Error
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo identityRepo) MarkSecondFactorChallengeUsed(ctx context.Context, id int64) (bool, error) {
+	res := repo.db.Model(&models.SecondFactorChallenge{}).
+		Where("id = ? AND status = ?", id, constants.SECOND_FACTOR_CHALLENGE_STATUS_ACTIVE).
+		Updates(map[string]interface{}{
+			"status":     constants.SECOND_FACTOR_CHALLENGE_STATUS_USED,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (repo identityRepo) CountSecondFactorFailures(ctx context.Context, userID int64, updatedSince time.Time) (int64, error) {
+	var failures int64
+	err := repo.db.Model(&models.SecondFactorChallenge{}).
+		Select("COALESCE(SUM(attempts), 0)").
+		Where("user_id = ? AND status <> ? AND updated_at >= ?", userID, constants.SECOND_FACTOR_CHALLENGE_STATUS_USED, updatedSince).
+		Scan(&failures).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return failures, nil
+}
+
+func (repo identityRepo) SaveWebAuthnChallenge(ctx context.Context, challenge entities.WebAuthnChallenge) error {
+	model := mappers.NewWebAuthnChallengeMapper().ToModel(challenge)
+	model.UpdatedAt = time.Now()
+	if err := repo.db.Save(&model).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo identityRepo) GetWebAuthnChallenge(ctx context.Context, challengeID string) (*entities.WebAuthnChallenge, error) {
+	challenge := models.WebAuthnChallenge{}
+	res := repo.db.Where("challenge_id = ?", challengeID).Find(&challenge)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrWebAuthnChallengeNotFound
+	}
+
+	entity := mappers.NewWebAuthnChallengeMapper().ToEntity(challenge)
+
+	return &entity, nil
+}
+
+func (repo identityRepo) MarkWebAuthnChallengeUsed(ctx context.Context, id int64) (bool, error) {
+	res := repo.db.Model(&models.WebAuthnChallenge{}).
+		Where("id = ? AND status = ?", id, constants.WEBAUTHN_CHALLENGE_STATUS_ACTIVE).
+		Updates(map[string]interface{}{
+			"status":     constants.WEBAUTHN_CHALLENGE_STATUS_USED,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (repo identityRepo) SaveWebAuthnCredential(ctx context.Context, credential entities.WebAuthnCredential) error {
+	model := mappers.NewWebAuthnCredentialMapper().ToModel(credential)
+	model.UpdatedAt = time.Now()
+	if err := repo.db.Save(&model).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo identityRepo) GetWebAuthnCredential(ctx context.Context, credentialID string) (*entities.WebAuthnCredential, error) {
+	credential := models.WebAuthnCredential{}
+	res := repo.db.Where("credential_id = ?", credentialID).Find(&credential)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrWebAuthnCredentialNotFound
+	}
+
+	entity := mappers.NewWebAuthnCredentialMapper().ToEntity(credential)
+
+	return &entity, nil
+}
+
+func (repo identityRepo) GetUserWebAuthnCredentials(ctx context.Context, userID int64) ([]entities.WebAuthnCredential, error) {
+	credentialModels := []models.WebAuthnCredential{}
+	err := repo.db.Where("user_id = ?", userID).Order("id").Find(&credentialModels).Error
+	if err != nil {
+		return nil, err
+	}
+
+	credentials := make([]entities.WebAuthnCredential, 0, len(credentialModels))
+	for _, credential := range credentialModels {
+		credentials = append(credentials, mappers.NewWebAuthnCredentialMapper().ToEntity(credential))
+	}
+
+	return credentials, nil
+}
+
+func (repo identityRepo) UpdateWebAuthnCredentialSignCount(ctx context.Context, id int64, previousSignCount int64, signCount int64) (bool, error) {
+	res := repo.db.Model(&models.WebAuthnCredential{}).
+		Where("id = ? AND sign_count = ?", id, previousSignCount).
+		Updates(map[string]interface{}{
+			"sign_count":   signCount,
+			"last_used_at": time.Now(),
+			"updated_at":   time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (repo identityRepo) SaveMagicLinkToken(ctx context.Context, token entities.MagicLinkToken) error {
+	model := mappers.NewMagicLinkTokenMapper().ToModel(token)
+	model.UpdatedAt = time.Now()
+	if err := repo.db.Save(&model).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo identityRepo) MarkMagicLinkTokenUsed(ctx context.Context, tokenID string) (bool, error) {
+	res := repo.db.Model(&models.MagicLinkToken{}).
+		Where("token_id = ? AND status = ?", tokenID, constants.MAGIC_LINK_TOKEN_STATUS_ACTIVE).
+		Updates(map[string]interface{}{
+			"status":     constants.MAGIC_LINK_TOKEN_STATUS_USED,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (repo identityRepo) SaveEmailVerification(ctx context.Context, verification entities.EmailVerification) error {
+	model := mappers.NewEmailVerificationMapper().ToModel(verification)
+	model.UpdatedAt = time.Now()
+	if err := repo.db.Save(&model).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo identityRepo) GetEmailVerification(ctx context.Context, verificationID string) (*entities.EmailVerification, error) {
+	verification := models.EmailVerification{}
+	res := repo.db.Where("verification_id = ?", verificationID).Find(&verification)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrEmailVerificationNotFound
+	}
+
+	entity := mappers.NewEmailVerificationMapper().ToEntity(verification)
+
+	return &entity, nil
+}
+
+func (repo identityRepo) RecordEmailVerificationFailure(ctx context.Context, id int64) (bool, error) {
+	res := repo.db.Model(&models.EmailVerification{}).
+		Where("id = ? AND status = ? AND failed_attempts < max_attempts", id, constants.EMAIL_VERIFICATION_STATUS_ACTIVE).
+		Updates(map[string]interface{}{
+			"failed_attempts": gorm.Expr("failed_attempts + 1"),
+			"updated_at":      time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+
+	err := repo.db.Model(&models.EmailVerification{}).
+		Where("id = ? AND failed_attempts >= max_attempts", id).
+		Update("status", constants.EMAIL_VERIFICATION_STATUS_BLOCKED).Error
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (repo identityRepo) MarkEmailVerificationVerified(ctx context.Context, id int64) (bool, error) {
+	res := repo.db.Model(&models.EmailVerification{}).
+		Where("id = ? AND status = ? AND failed_attempts < max_attempts", id, constants.EMAIL_VERIFICATION_STATUS_ACTIVE).
+		Updates(map[string]interface{}{
+			"status":     constants.EMAIL_VERIFICATION_STATUS_VERIFIED,
+			"updated_at": time.Now(),
+		})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
@@ -0,0 +1,58 @@
+package repositories
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+)
+
+// denylistSweepInterval is how often expired entries are removed from the memory denylist.
+const denylistSweepInterval = time.Minute
+
+// NewMemoryAccessTokenDenylistRepo returns a denylist kept in the memory of the current process. Revocations are
+// only seen by this instance of the app.
+func NewMemoryAccessTokenDenylistRepo() repositories.AccessTokenDenylistRepo {
+	return &memoryAccessTokenDenylistRepo{expiries: map[string]time.Time{}}
+}
+
+type memoryAccessTokenDenylistRepo struct {
+	mu        sync.Mutex
+	expiries  map[string]time.Time
+	lastSweep time.Time
+}
+
+func (repo *memoryAccessTokenDenylistRepo) Deny(ctx context.Context, tokenID string, expiryAt time.Time) error {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	now := time.Now()
+	repo.sweep(now)
+	if expiryAt.After(now) {
+		repo.expiries[tokenID] = expiryAt
+	}
+
+	return nil
+}
+
+func (repo *memoryAccessTokenDenylistRepo) IsDenied(ctx context.Context, tokenID string) (bool, error) {
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	expiryAt, ok := repo.expiries[tokenID]
+
+	return ok && time.Now().Before(expiryAt), nil
+}
+
+func (repo *memoryAccessTokenDenylistRepo) sweep(now time.Time) {
+	if now.Sub(repo.lastSweep) < denylistSweepInterval {
+		return
+	}
+	for tokenID, expiryAt := range repo.expiries {
+		if !now.Before(expiryAt) {
+			delete(repo.expiries, tokenID)
+		}
+	}
+	repo.lastSweep = now
+}
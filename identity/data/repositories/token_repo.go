@@ -2,9 +2,9 @@ package repositories
 
 import (
 	"context"
-	"io/ioutil"
 	"time"
 
+	"github.com/devesh2997/consequent/identity/constants"
 	"github.com/devesh2997/consequent/identity/data/mappers"
 	"github.com/devesh2997/consequent/identity/data/models"
 	"github.com/devesh2997/consequent/identity/domain/entities"
@@ -33,9 +33,12 @@ func (repo tokenRepo) SaveRefreshToken(ctx context.Context, token entities.Refre
 
 func (repo tokenRepo) GetRefreshToken(ctx context.Context, token string) (*entities.RefreshToken, error) {
 	refreshToken := models.RefreshToken{}
-	err := repo.db.Where("token = ?", token).First(&refreshToken).Error
-	if err != nil {
-		return nil, err
+	res := repo.db.Where("token = ?", token).Find(&refreshToken)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrRefreshTokenNotFound
 	}
 
 	refreshTokenEntity := mappers.NewRefreshTokenMapper().ToEntity(refreshToken)
@@ -43,10 +46,84 @@ func (repo tokenRepo) GetRefreshToken(ctx context.Context, token string) (*entit
 	return &refreshTokenEntity, nil
 }
 
-func (repo tokenRepo) GetPrivateKey() ([]byte, error) {
-	return ioutil.ReadFile("identity/keys/1_private.pem") // TODO (devesh2997) | a better approach needed for this
+func (repo tokenRepo) MarkRefreshTokenUsed(ctx context.Context, id int64) (bool, error) {
+	res := repo.db.Model(&models.RefreshToken{}).
+		Where("id = ? AND status = ?", id, constants.REFRESH_TOKEN_STATUS_ACTIVE).
+		Updates(map[string]interface{}{"status": constants.REFRESH_TOKEN_STATUS_USED, "updated_at": time.Now()})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (repo tokenRepo) GetUserActiveRefreshTokens(ctx context.Context, userID int64) ([]entities.RefreshToken, error) {
+	refreshTokens := []models.RefreshToken{}
+	err := repo.db.Where("user_id = ? AND status = ? AND expiry_at > ?", userID, constants.REFRESH_TOKEN_STATUS_ACTIVE, time.Now()).
+		Order("created_at DESC").
+		Find(&refreshTokens).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	refreshTokenEntities := make([]entities.RefreshToken, 0, len(refreshTokens))
+	for _, refreshToken := range refreshTokens {
+		refreshTokenEntities = append(refreshTokenEntities, mappers.NewRefreshTokenMapper().ToEntity(refreshToken))
+	}
+
+	return refreshTokenEntities, nil
 }
 
-func (repo tokenRepo) GetPublicKey() ([]byte, error) {
-	return ioutil.ReadFile("identity/keys/1_public.pem") // TODO (devesh2997) | a better approach needed for this
+func (repo tokenRepo) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	// tokens issued before refresh token families have no family, they must not be revoked as one.
+	if familyID == "" {
+		return nil
+	}
+
+	err := repo.db.Model(&models.RefreshToken{}).
+		Where("family_id = ? AND status = ?", familyID, constants.REFRESH_TOKEN_STATUS_ACTIVE).
+		Updates(map[string]interface{}{"status": constants.REFRESH_TOKEN_STATUS_REVOKED, "updated_at": time.Now()}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo tokenRepo) RevokeUserRefreshTokens(ctx context.Context, userID int64) error {
+	err := repo.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND status = ?", userID, constants.REFRESH_TOKEN_STATUS_ACTIVE).
+		Updates(map[string]interface{}{"status": constants.REFRESH_TOKEN_STATUS_REVOKED, "updated_at": time.Now()}).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo tokenRepo) GetFamilyRefreshTokensWithUnexpiredAccessToken(ctx context.Context, familyID string) ([]entities.RefreshToken, error) {
+	if familyID == "" {
+		return []entities.RefreshToken{}, nil
+	}
+
+	return repo.getRefreshTokensWithUnexpiredAccessToken(repo.db.Where("family_id = ?", familyID))
+}
+
+func (repo tokenRepo) GetUserRefreshTokensWithUnexpiredAccessToken(ctx context.Context, userID int64) ([]entities.RefreshToken, error) {
+	return repo.getRefreshTokensWithUnexpiredAccessToken(repo.db.Where("user_id = ?", userID))
+}
+
+func (repo tokenRepo) getRefreshTokensWithUnexpiredAccessToken(query *gorm.DB) ([]entities.RefreshToken, error) {
+	refreshTokens := []models.RefreshToken{}
+	err := query.Where("access_token_id <> '' AND access_token_expiry_at > ?", time.Now()).Find(&refreshTokens).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	refreshTokenEntities := make([]entities.RefreshToken, 0, len(refreshTokens))
+	for _, refreshToken := range refreshTokens {
+		refreshTokenEntities = append(refreshTokenEntities, mappers.NewRefreshTokenMapper().ToEntity(refreshToken))
+	}
+
+	return refreshTokenEntities, nil
 }
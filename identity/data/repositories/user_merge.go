@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"context"
+
+	"github.com/devesh2997/consequent/identity/constants"
+	identityDataConstants "github.com/devesh2997/consequent/identity/data/constants"
+	"github.com/devesh2997/consequent/identity/data/models"
+	oauthDataConstants "github.com/devesh2997/consequent/oauth/data/constants"
+	userConstants "github.com/devesh2997/consequent/user/constants"
+	userDataConstants "github.com/devesh2997/consequent/user/data/constants"
+	userMappers "github.com/devesh2997/consequent/user/data/mappers"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+	"gorm.io/gorm"
+)
+
+// userTables are the tables whose rows belong to a user through their user_id column. Their rows are moved as they
+// are when a user is merged into another, and deleted along with the account of the user.
+var userTables = []string{
+	identityDataConstants.TABLE_NAME_REFRESH_TOKENS,
+	identityDataConstants.TABLE_NAME_USER_PASSWORDS,
+	identityDataConstants.TABLE_NAME_PASSWORD_RESET_TOKENS,
+	identityDataConstants.TABLE_NAME_USER_EXTERNAL_IDENTITIES,
+	identityDataConstants.TABLE_NAME_USER_TOTPS,
+	identityDataConstants.TABLE_NAME_USER_RECOVERY_CODES,
+	identityDataConstants.TABLE_NAME_SECOND_FACTOR_CHALLENGES,
+	identityDataConstants.TABLE_NAME_WEBAUTHN_CREDENTIALS,
+	identityDataConstants.TABLE_NAME_WEBAUTHN_CHALLENGES,
+	identityDataConstants.TABLE_NAME_API_KEYS,
+	identityDataConstants.TABLE_NAME_EMAIL_VERIFICATIONS,
+	oauthDataConstants.TABLE_NAME_OAUTH_AUTHORIZATION_CODES,
+	oauthDataConstants.TABLE_NAME_OAUTH_CONSENTS,
+	userDataConstants.TABLE_NAME_USER_ROLES,
+}
+
+func (repo identityRepo) MergeUsers(ctx context.Context, user userEntities.User, fromUserID int64) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := dropDuplicateUserRows(tx, user.ID, fromUserID); err != nil {
+			return err
+		}
+
+		for _, table := range userTables {
+			if err := tx.Table(table).Where("user_id = ?", fromUserID).Update("user_id", user.ID).Error; err != nil {
+				return err
+			}
+		}
+
+		// the identifiers of the merged user are cleared first, so that they are never on both users.
+		err := tx.Table(userDataConstants.TABLE_NAME_USERS).Where("id = ?", fromUserID).Updates(map[string]interface{}{
+			"email":          "",
+			"email_verified": false,
+			"mobile":         "",
+			"status":         userConstants.USER_STATUS_MERGED,
+			"merged_into_id": user.ID,
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		userModel := userMappers.NewUserMapper().ToModel(user)
+
+		return tx.Save(&userModel).Error
+	})
+}
+
+// dropDuplicateUserRows removes the rows of the merged user that the user already has an equivalent of, and would
+// otherwise break the unique keys of their tables or leave the user with two active passwords.
+func dropDuplicateUserRows(tx *gorm.DB, userID int64, fromUserID int64) error {
+	var activePasswords int64
+	err := tx.Model(&models.UserPassword{}).
+		Where("user_id = ? AND status = ?", userID, constants.USER_PASSWORD_STATUS_ACTIVE).
+		Count(&activePasswords).Error
+	if err != nil {
+		return err
+	}
+	if activePasswords > 0 {
+		err := tx.Model(&models.UserPassword{}).
+			Where("user_id = ? AND status = ?", fromUserID, constants.USER_PASSWORD_STATUS_ACTIVE).
+			Update("status", constants.USER_PASSWORD_STATUS_INACTIVE).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	// a user has a single totp, an active one is kept over a pending enrollment. The recovery codes belong to the
+	// totp, they are kept or dropped along with it.
+	totps := []models.UserTOTP{}
+	if err := tx.Where("user_id IN ?", []int64{userID, fromUserID}).Find(&totps).Error; err != nil {
+		return err
+	}
+	if len(totps) == 2 {
+		active := map[int64]bool{}
+		for _, totp := range totps {
+			active[totp.UserID] = totp.Status == constants.USER_TOTP_STATUS_ACTIVE
+		}
+		dropUserID := fromUserID
+		if active[fromUserID] && !active[userID] {
+			dropUserID = userID
+		}
+		if err := tx.Where("user_id = ?", dropUserID).Delete(&models.UserTOTP{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ?", dropUserID).Delete(&models.UserRecoveryCode{}).Error; err != nil {
+			return err
+		}
+	}
+
+	if err := dropDuplicates(tx, oauthDataConstants.TABLE_NAME_OAUTH_CONSENTS, "client_id", userID, fromUserID); err != nil {
+		return err
+	}
+
+	return dropDuplicates(tx, userDataConstants.TABLE_NAME_USER_ROLES, "role_id", userID, fromUserID)
+}
+
+// dropDuplicates removes the rows of the merged user that have the same value in the column as a row of the user.
+func dropDuplicates(tx *gorm.DB, table string, column string, userID int64, fromUserID int64) error {
+	values := []string{}
+	if err := tx.Table(table).Where("user_id = ?", userID).Pluck(column, &values).Error; err != nil {
+		return err
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	return tx.Table(table).Where("user_id = ? AND "+column+" IN ?", fromUserID, values).Delete(map[string]interface{}{}).Error
+}
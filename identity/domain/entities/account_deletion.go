@@ -0,0 +1,50 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/constants"
+	oauthEntities "github.com/devesh2997/consequent/oauth/domain/entities"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+)
+
+// AccountDeletionRequest is a user's request to delete their account, which is carried out once ScheduledAt has
+// passed unless the user cancels it before then.
+type AccountDeletionRequest struct {
+	ID          int64
+	UserID      int64
+	Status      string
+	ScheduledAt time.Time
+	// CompletedAt is nil until the account has been deleted.
+	CompletedAt *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+func (request AccountDeletionRequest) IsPending() bool {
+	return request.Status == constants.ACCOUNT_DELETION_STATUS_PENDING
+}
+
+func (request AccountDeletionRequest) IsDue() bool {
+	return !time.Now().Before(request.ScheduledAt)
+}
+
+// AccountExport is everything that is held about a user, for the user to download. Secrets such as password hashes
+// and otps are left out when it is mapped to its model.
+type AccountExport struct {
+	User               userEntities.User
+	Roles              []userEntities.Role
+	Passwords          []UserPassword
+	MobileOTPs         []UserLoginMobileOTP
+	RefreshTokens      []RefreshToken
+	PasswordResets     []PasswordResetToken
+	MagicLinks         []MagicLinkToken
+	EmailVerifications []EmailVerification
+	ExternalIdentities []UserExternalIdentity
+	TOTPs              []UserTOTP
+	Passkeys           []WebAuthnCredential
+	APIKeys            []APIKey
+	OAuthConsents      []oauthEntities.Consent
+	DeletionRequests   []AccountDeletionRequest
+	ExportedAt         time.Time
+}
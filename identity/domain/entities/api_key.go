@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/constants"
+)
+
+// APIKey is a long-lived credential that a user creates for scripts and integrations. Only the hash of the key is
+// stored, the prefix is kept so that users can tell their keys apart.
+type APIKey struct {
+	ID      int64
+	UserID  int64
+	Name    string
+	Prefix  string
+	KeyHash string
+	// Scope limits what the key can be used for, the key has the full access of the user when it is empty.
+	Scope  string
+	Status string
+	// ExpiryAt is nil for keys that do not expire.
+	ExpiryAt  *time.Time
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (apiKey APIKey) IsActive() bool {
+	return apiKey.Status == constants.API_KEY_STATUS_ACTIVE
+}
+
+func (apiKey APIKey) HasExpired() bool {
+	return apiKey.ExpiryAt != nil && time.Now().After(*apiKey.ExpiryAt)
+}
+
+// CreatedAPIKey is a new api key along with the key itself, which is only ever returned once.
+type CreatedAPIKey struct {
+	APIKey APIKey
+	Key    string
+}
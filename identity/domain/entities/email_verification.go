@@ -0,0 +1,34 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/constants"
+)
+
+// EmailVerification is a code emailed to a signed in user, to verify an email before it is linked to the user.
+type EmailVerification struct {
+	ID             int64
+	VerificationID string
+	UserID         int64
+	Email          string
+	CodeHash       string
+	Status         string
+	FailedAttempts int
+	MaxAttempts    int
+	CreatedAt      time.Time
+	ExpiryAt       time.Time
+	UpdatedAt      time.Time
+}
+
+func (verification EmailVerification) IsActive() bool {
+	return verification.Status == constants.EMAIL_VERIFICATION_STATUS_ACTIVE
+}
+
+func (verification EmailVerification) IsBlocked() bool {
+	return verification.Status == constants.EMAIL_VERIFICATION_STATUS_BLOCKED
+}
+
+func (verification EmailVerification) HasExpired() bool {
+	return time.Now().After(verification.ExpiryAt)
+}
@@ -0,0 +1,14 @@
+package entities
+
+import "time"
+
+// MagicLinkToken records a magic link token by its jti, so that the link can only be used once.
+type MagicLinkToken struct {
+	ID        int64
+	TokenID   string
+	Email     string
+	Status    string
+	CreatedAt time.Time
+	ExpiryAt  time.Time
+	UpdatedAt time.Time
+}
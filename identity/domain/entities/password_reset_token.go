@@ -0,0 +1,28 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/constants"
+)
+
+type PasswordResetToken struct {
+	ID        int64
+	UserID    int64
+	ResetID   string
+	TokenHash string
+	Channel   string
+	Attempts  int
+	Status    string
+	CreatedAt time.Time
+	ExpiryAt  time.Time
+	UpdatedAt time.Time
+}
+
+func (token PasswordResetToken) IsActive() bool {
+	return token.Status == constants.PASSWORD_RESET_TOKEN_STATUS_ACTIVE
+}
+
+func (token PasswordResetToken) HasExpired() bool {
+	return time.Now().After(token.ExpiryAt)
+}
@@ -0,0 +1,39 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/constants"
+)
+
+// SecondFactorChallenge is issued when the password of a user with two-factor authentication has been verified. It
+// is exchanged for tokens along with a totp or recovery code.
+type SecondFactorChallenge struct {
+	ID        int64
+	UserID    int64
+	TokenHash string
+	Attempts  int
+	Status    string
+	CreatedAt time.Time
+	ExpiryAt  time.Time
+	UpdatedAt time.Time
+}
+
+func (challenge SecondFactorChallenge) IsActive() bool {
+	return challenge.Status == constants.SECOND_FACTOR_CHALLENGE_STATUS_ACTIVE
+}
+
+func (challenge SecondFactorChallenge) HasExpired() bool {
+	return time.Now().After(challenge.ExpiryAt)
+}
+
+// SignInResult is either the tokens of the user, or the challenge token when a second factor is required.
+type SignInResult struct {
+	Token             *Token
+	ChallengeToken    string
+	ChallengeExpiryAt time.Time
+}
+
+func (result SignInResult) IsSecondFactorRequired() bool {
+	return result.Token == nil
+}
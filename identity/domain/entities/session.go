@@ -0,0 +1,20 @@
+package entities
+
+import "time"
+
+// Session is a signed in device of a user, i.e. a refresh token family with an active refresh token.
+type Session struct {
+	// ID is the family id of the session's refresh tokens.
+	ID string
+	// ClientID is set for sessions of oauth clients.
+	ClientID   string
+	DeviceName string
+	UserAgent  string
+	IPAddress  string
+	CreatedAt  time.Time
+	// LastUsedAt is when the session was last refreshed, or created if it has not been refreshed yet.
+	LastUsedAt time.Time
+	ExpiryAt   time.Time
+	// IsCurrent tells whether the session is the one that the request was made with.
+	IsCurrent bool
+}
@@ -1,6 +1,10 @@
 package entities
 
-import "time"
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/constants"
+)
 
 type JWT struct {
 	Token    string
@@ -8,12 +12,39 @@ type JWT struct {
 }
 
 type RefreshToken struct {
-	ID        int64
-	Token     string
-	Status    string
-	CreatedAt time.Time
-	ExpiryAt  time.Time
-	UpdatedAt time.Time
+	ID       int64
+	UserID   int64
+	Token    string
+	FamilyID string
+	// AccessTokenID and AccessTokenExpiryAt identify the jwt that was issued along with the refresh token, so that
+	// it can be denylisted when the session is revoked.
+	AccessTokenID       string
+	AccessTokenExpiryAt time.Time
+	// ClientID and Scope are set for tokens issued to oauth clients.
+	ClientID string
+	Scope    string
+	// UserAgent, IPAddress and DeviceName describe the client that the refresh token was issued to.
+	UserAgent  string
+	IPAddress  string
+	DeviceName string
+	// SessionCreatedAt is when the first token of the family was issued, i.e. when the user signed in.
+	SessionCreatedAt time.Time
+	Status           string
+	CreatedAt        time.Time
+	ExpiryAt         time.Time
+	UpdatedAt        time.Time
+}
+
+func (token RefreshToken) IsActive() bool {
+	return token.Status == constants.REFRESH_TOKEN_STATUS_ACTIVE
+}
+
+func (token RefreshToken) IsUsed() bool {
+	return token.Status == constants.REFRESH_TOKEN_STATUS_USED
+}
+
+func (token RefreshToken) HasExpired() bool {
+	return time.Now().After(token.ExpiryAt)
 }
 
 type Token struct {
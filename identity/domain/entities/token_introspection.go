@@ -0,0 +1,17 @@
+package entities
+
+import "time"
+
+// TokenIntrospection is what is known about a token (RFC 7662). Only Active is set for tokens that are not active.
+type TokenIntrospection struct {
+	Active    bool
+	TokenType string
+	Subject   string
+	Username  string
+	Issuer    string
+	Audience  string
+	TokenID   string
+	IssuedAt  time.Time
+	NotBefore time.Time
+	ExpiryAt  time.Time
+}
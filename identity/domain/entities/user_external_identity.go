@@ -0,0 +1,15 @@
+package entities
+
+import "time"
+
+// UserExternalIdentity links a user to their account with an external identity provider, identified by the subject
+// of the provider's id tokens.
+type UserExternalIdentity struct {
+	ID        int64
+	UserID    int64
+	Provider  string
+	Subject   string
+	Email     string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
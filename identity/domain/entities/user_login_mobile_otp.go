@@ -12,6 +12,8 @@ type UserLoginMobileOTP struct {
 	Mobile         string
 	OTP            int
 	Status         string
+	FailedAttempts int
+	MaxAttempts    int
 	CreatedAt      time.Time
 	ExpiryAt       time.Time
 	UpdatedAt      time.Time
@@ -21,6 +23,14 @@ func (otp UserLoginMobileOTP) IsActive() bool {
 	return otp.Status == constants.USER_LOGIN_MOBILE_OTP_STATUS_ACTIVE
 }
 
+func (otp UserLoginMobileOTP) IsBlocked() bool {
+	return otp.Status == constants.USER_LOGIN_MOBILE_OTP_STATUS_BLOCKED
+}
+
+func (otp UserLoginMobileOTP) HasAttemptsLeft() bool {
+	return otp.FailedAttempts < otp.MaxAttempts
+}
+
 func (otp UserLoginMobileOTP) HasExpired() bool {
 	return time.Now().After(otp.ExpiryAt)
 }
@@ -0,0 +1,38 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/constants"
+)
+
+// UserTOTP is the totp secret of a user. It is pending until the user confirms the enrollment with a first code.
+type UserTOTP struct {
+	ID     int64
+	UserID int64
+	Secret string
+	Status string
+	// LastUsedCounter is the time step of the last code that was accepted, codes of earlier steps are refused.
+	LastUsedCounter int64
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+}
+
+func (userTOTP UserTOTP) IsActive() bool {
+	return userTOTP.Status == constants.USER_TOTP_STATUS_ACTIVE
+}
+
+// TOTPEnrollment is what an authenticator app is set up with.
+type TOTPEnrollment struct {
+	Secret string
+	URI    string
+}
+
+type UserRecoveryCode struct {
+	ID        int64
+	UserID    int64
+	CodeHash  string
+	Status    string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
@@ -0,0 +1,70 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/identity/constants"
+	"github.com/devesh2997/consequent/webauthn"
+)
+
+// WebAuthnCredential is a passkey of a user. CredentialID is the base64url encoded id of the credential, and PublicKey
+// its cose encoded public key.
+type WebAuthnCredential struct {
+	ID                int64
+	UserID            int64
+	CredentialID      string
+	PublicKey         []byte
+	SignCount         int64
+	AAGUID            string
+	AttestationFormat string
+	LastUsedAt        time.Time
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// WebAuthnChallenge is the challenge of a registration or authentication ceremony. UserID is only set for
+// registrations.
+type WebAuthnChallenge struct {
+	ID          int64
+	ChallengeID string
+	Challenge   string
+	UserID      int64
+	Ceremony    string
+	Status      string
+	CreatedAt   time.Time
+	ExpiryAt    time.Time
+	UpdatedAt   time.Time
+}
+
+func (challenge WebAuthnChallenge) IsActive() bool {
+	return challenge.Status == constants.WEBAUTHN_CHALLENGE_STATUS_ACTIVE
+}
+
+func (challenge WebAuthnChallenge) HasExpired() bool {
+	return time.Now().After(challenge.ExpiryAt)
+}
+
+type PasskeyRegistrationOptions struct {
+	ChallengeID string
+	Options     webauthn.CreationOptions
+}
+
+type PasskeyLoginOptions struct {
+	ChallengeID string
+	Options     webauthn.RequestOptions
+}
+
+// PasskeyRegistration is the response of the authenticator to a registration ceremony.
+type PasskeyRegistration struct {
+	ClientDataJSON    []byte
+	AttestationObject []byte
+}
+
+// PasskeyAssertion is the response of the authenticator to an authentication ceremony.
+type PasskeyAssertion struct {
+	CredentialID      []byte
+	ClientDataJSON    []byte
+	AuthenticatorData []byte
+	Signature         []byte
+	UserHandle        []byte
+}
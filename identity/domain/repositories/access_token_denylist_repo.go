@@ -0,0 +1,14 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+// AccessTokenDenylistRepo keeps the ids (jti) of access tokens that have been revoked before they expired.
+type AccessTokenDenylistRepo interface {
+	// Deny adds the token id to the denylist. The entry is dropped once expiryAt has passed, since the token is
+	// rejected for having expired from then on.
+	Deny(ctx context.Context, tokenID string, expiryAt time.Time) error
+	IsDenied(ctx context.Context, tokenID string) (bool, error)
+}
@@ -0,0 +1,31 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+)
+
+var (
+	ErrAccountDeletionRequestNotFound = errors.New("account deletion request not found")
+)
+
+type AccountRepo interface {
+	SaveAccountDeletionRequest(ctx context.Context, request entities.AccountDeletionRequest) error
+	GetPendingAccountDeletionRequest(ctx context.Context, userID int64) (*entities.AccountDeletionRequest, error)
+	// CancelAccountDeletionRequest moves the pending deletion request of the user to the cancelled status. It returns
+	// false if the user has no pending deletion request.
+	CancelAccountDeletionRequest(ctx context.Context, userID int64) (bool, error)
+	// GetDueAccountDeletionRequests returns up to limit pending deletion requests that are scheduled before the time,
+	// with an id greater than afterID, in the order of their ids.
+	GetDueAccountDeletionRequests(ctx context.Context, before time.Time, afterID int64, limit int) ([]entities.AccountDeletionRequest, error)
+	// DeleteAccount deletes the credentials and the other rows of the user, clears the personal data of the user and
+	// of the users merged into it, and completes the pending deletion request with the id when it is not zero. It
+	// returns ErrAccountDeletionRequestNotFound, without deleting anything, if the request is no longer pending.
+	DeleteAccount(ctx context.Context, user userEntities.User, requestID int64) error
+	// GetAccountExport returns the rows held about the user. The roles of the user are not read.
+	GetAccountExport(ctx context.Context, user userEntities.User) (*entities.AccountExport, error)
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/devesh2997/consequent/identity/domain/entities"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+)
+
+type APIKeyRepo interface {
+	SaveAPIKey(ctx context.Context, apiKey entities.APIKey) error
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*entities.APIKey, error)
+	// GetUserAPIKeys returns the api keys of the user that have not been revoked, newest first.
+	GetUserAPIKeys(ctx context.Context, userID int64) ([]entities.APIKey, error)
+	// RevokeAPIKey moves the active api key of the user to the revoked status. It returns false if the user has no
+	// active api key with the id.
+	RevokeAPIKey(ctx context.Context, userID int64, id int64) (bool, error)
+}
@@ -2,13 +2,97 @@ package repositories
 
 import (
 	"context"
+	"errors"
+	"time"
 
 	"github.com/devesh2997/consequent/identity/domain/entities"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+)
+
+var (
+	ErrUserPasswordNotFound          = errors.New("user password not found")
+	ErrPasswordResetTokenNotFound    = errors.New("password reset token not found")
+	ErrUserExternalIdentityNotFound  = errors.New("user external identity not found")
+	ErrUserTOTPNotFound              = errors.New("user totp not found")
+	ErrSecondFactorChallengeNotFound = errors.New("second factor challenge not found")
+	ErrWebAuthnChallengeNotFound     = errors.New("webauthn challenge not found")
+	ErrWebAuthnCredentialNotFound    = errors.New("webauthn credential not found")
+	ErrEmailVerificationNotFound     = errors.New("email verification not found")
 )
 
 type IdentityRepo interface {
 	SaveUserPassword(ctx context.Context, userPassword entities.UserPassword) error
+	UpdateUserPassword(ctx context.Context, userPassword entities.UserPassword) error
 	GetActiveUserPassword(ctx context.Context, userID int64) (*entities.UserPassword, error)
+	// GetRecentUserPasswords returns the last limit passwords of the user, active or not, newest first.
+	GetRecentUserPasswords(ctx context.Context, userID int64, limit int) ([]entities.UserPassword, error)
+	// GetUserPasswordsAfterID returns at most limit user passwords with an id greater than afterID, ordered by id.
+	GetUserPasswordsAfterID(ctx context.Context, afterID int64, limit int) ([]entities.UserPassword, error)
 	SaveUserLoginMobileOTP(ctx context.Context, otp entities.UserLoginMobileOTP) error
 	GetUserLoginMobileOTP(ctx context.Context, verificationID string) (*entities.UserLoginMobileOTP, error)
+	// RecordUserLoginMobileOTPFailure increments the failed attempts of the otp, and blocks it once it reaches its
+	// max attempts. It returns false if the otp had no attempts left.
+	RecordUserLoginMobileOTPFailure(ctx context.Context, id int64) (bool, error)
+	// MarkUserLoginMobileOTPVerified moves an active otp with attempts left to the verified status. It returns
+	// false if the otp could not be verified anymore.
+	MarkUserLoginMobileOTPVerified(ctx context.Context, id int64) (bool, error)
+	CountUserLoginMobileOTPs(ctx context.Context, mobile string, status string, updatedSince time.Time) (int64, error)
+	SavePasswordResetToken(ctx context.Context, token entities.PasswordResetToken) error
+	GetPasswordResetToken(ctx context.Context, resetID string) (*entities.PasswordResetToken, error)
+	// RecordPasswordResetFailure increments the attempts of the active reset token, and expires it once it reaches
+	// maxAttempts.
+	RecordPasswordResetFailure(ctx context.Context, id int64, maxAttempts int) error
+	// MarkPasswordResetTokenUsed moves an active, unexpired reset token to the used status. It returns false if the
+	// token could not be used anymore, e.g. because it was used by a concurrent request.
+	MarkPasswordResetTokenUsed(ctx context.Context, id int64) (bool, error)
+	GetUserExternalIdentity(ctx context.Context, provider string, subject string) (*entities.UserExternalIdentity, error)
+	SaveUserExternalIdentity(ctx context.Context, identity entities.UserExternalIdentity) error
+	GetUserTOTP(ctx context.Context, userID int64) (*entities.UserTOTP, error)
+	SaveUserTOTP(ctx context.Context, userTOTP entities.UserTOTP) error
+	// MarkUserTOTPCounterUsed records that the code of the given time step has been used. It returns false if a
+	// code of the same or a later step has already been used.
+	MarkUserTOTPCounterUsed(ctx context.Context, id int64, counter int64) (bool, error)
+	// ReplaceUserRecoveryCodes removes the recovery codes of the user and saves the given ones.
+	ReplaceUserRecoveryCodes(ctx context.Context, userID int64, codes []entities.UserRecoveryCode) error
+	// UseUserRecoveryCode moves an active recovery code of the user to the used status. It returns false if the user
+	// has no active recovery code with the hash.
+	UseUserRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+	SaveSecondFactorChallenge(ctx context.Context, challenge entities.SecondFactorChallenge) error
+	GetSecondFactorChallenge(ctx context.Context, tokenHash string) (*entities.SecondFactorChallenge, error)
+	// RecordSecondFactorChallengeFailure increments the attempts of an active challenge, and blocks it once it
+	// reaches maxAttempts. It returns false if the challenge had no attempts left.
+	RecordSecondFactorChallengeFailure(ctx context.Context, id int64, maxAttempts int) (bool, error)
+	// MarkSecondFactorChallengeUsed moves an active challenge to the used status. It returns false if the challenge
+	// was not active anymore.
+	MarkSecondFactorChallengeUsed(ctx context.Context, id int64) (bool, error)
+	// CountSecondFactorFailures returns the failed attempts of the challenges of the user that have been updated
+	// since the given time. Attempts of challenges that were passed in the end are not counted.
+	CountSecondFactorFailures(ctx context.Context, userID int64, updatedSince time.Time) (int64, error)
+	SaveWebAuthnChallenge(ctx context.Context, challenge entities.WebAuthnChallenge) error
+	GetWebAuthnChallenge(ctx context.Context, challengeID string) (*entities.WebAuthnChallenge, error)
+	// MarkWebAuthnChallengeUsed moves an active challenge to the used status. It returns false if the challenge was
+	// not active anymore.
+	MarkWebAuthnChallengeUsed(ctx context.Context, id int64) (bool, error)
+	SaveWebAuthnCredential(ctx context.Context, credential entities.WebAuthnCredential) error
+	GetWebAuthnCredential(ctx context.Context, credentialID string) (*entities.WebAuthnCredential, error)
+	GetUserWebAuthnCredentials(ctx context.Context, userID int64) ([]entities.WebAuthnCredential, error)
+	// UpdateWebAuthnCredentialSignCount records a use of the credential. It returns false if the sign count has been
+	// changed by a concurrent use since it was read.
+	UpdateWebAuthnCredentialSignCount(ctx context.Context, id int64, previousSignCount int64, signCount int64) (bool, error)
+	SaveMagicLinkToken(ctx context.Context, token entities.MagicLinkToken) error
+	// MarkMagicLinkTokenUsed moves the active magic link token with the jti to the used status. It returns false if
+	// there is no such token or it has already been used.
+	MarkMagicLinkTokenUsed(ctx context.Context, tokenID string) (bool, error)
+	SaveEmailVerification(ctx context.Context, verification entities.EmailVerification) error
+	GetEmailVerification(ctx context.Context, verificationID string) (*entities.EmailVerification, error)
+	// RecordEmailVerificationFailure increments the failed attempts of the verification, and blocks it once it
+	// reaches its max attempts. It returns false if the verification had no attempts left.
+	RecordEmailVerificationFailure(ctx context.Context, id int64) (bool, error)
+	// MarkEmailVerificationVerified moves an active verification with attempts left to the verified status. It
+	// returns false if the verification could not be verified anymore.
+	MarkEmailVerificationVerified(ctx context.Context, id int64) (bool, error)
+	// MergeUsers saves the user and moves every row of the other user to it, in a single transaction. Rows that
+	// the user already has an equivalent of, such as a totp or a consent to the same client, are dropped instead.
+	// The other user is left without an email or a mobile, in the merged status.
+	MergeUsers(ctx context.Context, user userEntities.User, fromUserID int64) error
 }
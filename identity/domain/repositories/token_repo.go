@@ -2,13 +2,30 @@ package repositories
 
 import (
 	"context"
+	"errors"
 
 	"github.com/devesh2997/consequent/identity/domain/entities"
 )
 
+var (
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+)
+
 type TokenRepo interface {
 	SaveRefreshToken(ctx context.Context, token entities.RefreshToken) error
 	GetRefreshToken(ctx context.Context, token string) (*entities.RefreshToken, error)
-	GetPrivateKey() ([]byte, error)
-	GetPublicKey() ([]byte, error)
+	// MarkRefreshTokenUsed moves an active refresh token to the used status. It returns false if the token
+	// was not active anymore, i.e. it has already been exchanged by a concurrent request.
+	MarkRefreshTokenUsed(ctx context.Context, id int64) (bool, error)
+	// GetUserActiveRefreshTokens returns the active and unexpired refresh tokens of the user, which is one per
+	// session, most recently issued first.
+	GetUserActiveRefreshTokens(ctx context.Context, userID int64) ([]entities.RefreshToken, error)
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int64) error
+	// GetFamilyRefreshTokensWithUnexpiredAccessToken returns the refresh tokens of the family, in any status, whose
+	// access token has not expired yet.
+	GetFamilyRefreshTokensWithUnexpiredAccessToken(ctx context.Context, familyID string) ([]entities.RefreshToken, error)
+	// GetUserRefreshTokensWithUnexpiredAccessToken returns the refresh tokens of the user, in any status, whose
+	// access token has not expired yet.
+	GetUserRefreshTokensWithUnexpiredAccessToken(ctx context.Context, userID int64) ([]entities.RefreshToken, error)
 }
@@ -0,0 +1,301 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/constants"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"github.com/devesh2997/consequent/logger"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+	userRepositories "github.com/devesh2997/consequent/user/domain/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	emailVerificationExpiryDuration = time.Minute * 15
+	emailVerificationMaxAttempts    = 5
+	emailVerificationCodeDigits     = 6
+	emailVerificationEmailSubject   = "Verify your email"
+	// mergeTokenMaxAge is how long ago the access token that proves the ownership of the user to merge may have been
+	// issued, so that the owner has signed in to it just before.
+	mergeTokenMaxAge = time.Minute * 5
+)
+
+func (service identityService) SendEmailLinkCode(ctx context.Context, userID int64, email string) (string, error) {
+	if !service.isEmailValid(email) {
+		return "", errInvalidEmail()
+	}
+
+	user, err := service.userService.FindByID(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+	// an email that the user signed up with can still be verified, any other email is only linked if there is none.
+	if user.Email != "" && (user.Email != email || user.EmailVerified) {
+		return "", errEmailAlreadyLinked()
+	}
+
+	allowed, retryAt, err := service.allowSend(ctx, "email_link", "email:"+email)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		return "", errEmailLinkSendRateLimited(retryAt)
+	}
+
+	code, err := service.generateOTP(emailVerificationCodeDigits)
+	if err != nil {
+		return "", errorx.NewSystemError(-1, err)
+	}
+
+	verificationID := uuid.New().String()
+	err = service.repo.SaveEmailVerification(ctx, entities.EmailVerification{
+		VerificationID: verificationID,
+		UserID:         userID,
+		Email:          email,
+		CodeHash:       service.hashSecret(strconv.Itoa(code)),
+		Status:         constants.EMAIL_VERIFICATION_STATUS_ACTIVE,
+		MaxAttempts:    emailVerificationMaxAttempts,
+		CreatedAt:      time.Now(),
+		ExpiryAt:       time.Now().Add(emailVerificationExpiryDuration),
+	})
+	if err != nil {
+		return "", errorx.NewSystemError(-1, err)
+	}
+
+	go service.sendEmailLinkCode(email, code)
+
+	return verificationID, nil
+}
+
+func (service identityService) LinkEmail(ctx context.Context, userID int64, verificationID string, code string, mergeToken string) (*userEntities.User, error) {
+	verification, err := service.verifyEmail(ctx, userID, verificationID, code)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := service.userService.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Email == verification.Email {
+		if user.EmailVerified {
+			return user, nil
+		}
+
+		user.EmailVerified = true
+		if err := service.userService.Update(ctx, *user); err != nil {
+			return nil, err
+		}
+
+		return user, nil
+	}
+	if user.Email != "" {
+		return nil, errEmailAlreadyLinked()
+	}
+
+	owner, err := service.userService.FindByEmail(ctx, verification.Email)
+	if err != nil && err != userRepositories.ErrUserNotFound {
+		return nil, err
+	}
+	if owner != nil {
+		if mergeToken == "" {
+			return nil, errEmailBelongsToAnotherUser()
+		}
+		if err := service.verifyMergeToken(ctx, mergeToken, owner.ID); err != nil {
+			return nil, err
+		}
+
+		return service.MergeUsers(ctx, userID, owner.ID)
+	}
+
+	user.Email = verification.Email
+	user.EmailVerified = true
+	if err := service.userService.Update(ctx, *user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (service identityService) LinkMobile(ctx context.Context, userID int64, verificationID string, mobileNumber string, otp int, mergeToken string) (*userEntities.User, error) {
+	user, err := service.userService.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.Mobile != "" && user.Mobile != mobileNumber {
+		return nil, errMobileAlreadyLinked()
+	}
+
+	if err := service.verifyOTP(ctx, verificationID, mobileNumber, otp); err != nil {
+		return nil, err
+	}
+	if user.Mobile == mobileNumber {
+		return user, nil
+	}
+
+	owner, err := service.userService.FindByMobile(ctx, mobileNumber)
+	if err != nil && err != userRepositories.ErrUserNotFound {
+		return nil, err
+	}
+	if owner != nil {
+		if mergeToken == "" {
+			return nil, errMobileBelongsToAnotherUser()
+		}
+		if err := service.verifyMergeToken(ctx, mergeToken, owner.ID); err != nil {
+			return nil, err
+		}
+
+		return service.MergeUsers(ctx, userID, owner.ID)
+	}
+
+	user.Mobile = mobileNumber
+	if err := service.userService.Update(ctx, *user); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+func (service identityService) MergeUsers(ctx context.Context, intoUserID int64, fromUserID int64) (*userEntities.User, error) {
+	if intoUserID == fromUserID {
+		return nil, errUsersCannotBeMerged("a user cannot be merged into itself")
+	}
+
+	user, err := service.findUserToMerge(ctx, intoUserID)
+	if err != nil {
+		return nil, err
+	}
+	fromUser, err := service.findUserToMerge(ctx, fromUserID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Email != "" && fromUser.Email != "" && user.Email != fromUser.Email {
+		return nil, errUsersCannotBeMerged("the users have different emails")
+	}
+	if user.Mobile != "" && fromUser.Mobile != "" && user.Mobile != fromUser.Mobile {
+		return nil, errUsersCannotBeMerged("the users have different mobile numbers")
+	}
+
+	mergedUser := *user
+	if mergedUser.Email == "" {
+		mergedUser.Email = fromUser.Email
+	}
+	if mergedUser.Email == fromUser.Email {
+		mergedUser.EmailVerified = user.EmailVerified || fromUser.EmailVerified
+	}
+	if mergedUser.Mobile == "" {
+		mergedUser.Mobile = fromUser.Mobile
+	}
+	if mergedUser.Name == "" {
+		mergedUser.Name = fromUser.Name
+	}
+	if mergedUser.Gender == "" {
+		mergedUser.Gender = fromUser.Gender
+	}
+
+	// the tokens of the merged user would otherwise keep acting as it, and its refresh tokens are moved to the user.
+	if err := service.tokenService.RevokeAll(ctx, fromUser.ID); err != nil {
+		return nil, err
+	}
+	if err := service.repo.MergeUsers(ctx, mergedUser, fromUser.ID); err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return &mergedUser, nil
+}
+
+// verifyMergeToken checks that the token is an access token of the user that was issued to the user themselves,
+// rather than to an oauth client, within mergeTokenMaxAge. Proving the ownership of an email or a mobile number is
+// not enough to merge its user, the owner has to sign in to it as well.
+func (service identityService) verifyMergeToken(ctx context.Context, mergeToken string, userID int64) error {
+	claims, err := service.tokenService.Validate(ctx, mergeToken)
+	if err != nil {
+		return errInvalidMergeToken()
+	}
+	if claims.User.ID != userID || claims.ClientID != "" || time.Since(time.Unix(claims.IssuedAt, 0)) > mergeTokenMaxAge {
+		return errInvalidMergeToken()
+	}
+
+	return nil
+}
+
+// findUserToMerge returns the user, unless it cannot take part in a merge. Suspended users are never merged, so
+// that a suspension cannot be escaped by merging into another user.
+func (service identityService) findUserToMerge(ctx context.Context, userID int64) (*userEntities.User, error) {
+	user, err := service.userService.FindByID(ctx, userID)
+	if err != nil && err != userRepositories.ErrUserNotFound {
+		return nil, err
+	}
+	if err == userRepositories.ErrUserNotFound || user.IsMerged() {
+		return nil, errUsersCannotBeMerged(fmt.Sprintf("user %d does not exist", userID))
+	}
+	if user.IsSuspended() {
+		return nil, errUserSuspended()
+	}
+
+	return user, nil
+}
+
+// verifyEmail checks the code of the email verification of the user, and consumes the verification.
+func (service identityService) verifyEmail(ctx context.Context, userID int64, verificationID string, code string) (*entities.EmailVerification, error) {
+	verification, err := service.repo.GetEmailVerification(ctx, verificationID)
+	if err != nil && err != repositories.ErrEmailVerificationNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrEmailVerificationNotFound || verification.UserID != userID {
+		return nil, errInvalidEmailVerification()
+	}
+	if verification.IsBlocked() {
+		return nil, errEmailVerificationAttemptsExceeded()
+	}
+	if !verification.IsActive() {
+		return nil, errInvalidEmailVerification()
+	}
+
+	if verification.HasExpired() {
+		verification.Status = constants.EMAIL_VERIFICATION_STATUS_EXPIRED
+		if err := service.repo.SaveEmailVerification(ctx, *verification); err != nil {
+			return nil, errorx.NewSystemError(-1, err)
+		}
+
+		return nil, errInvalidEmailVerification()
+	}
+
+	if subtle.ConstantTimeCompare([]byte(verification.CodeHash), []byte(service.hashSecret(code))) != 1 {
+		recorded, err := service.repo.RecordEmailVerificationFailure(ctx, verification.ID)
+		if err != nil {
+			return nil, errorx.NewSystemError(-1, err)
+		}
+		if !recorded || verification.FailedAttempts+1 >= verification.MaxAttempts {
+			return nil, errEmailVerificationAttemptsExceeded()
+		}
+
+		return nil, errInvalidEmailVerificationCode()
+	}
+
+	verified, err := service.repo.MarkEmailVerificationVerified(ctx, verification.ID)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if !verified {
+		return nil, errInvalidEmailVerification()
+	}
+
+	return verification, nil
+}
+
+func (service identityService) sendEmailLinkCode(email string, code int) {
+	ctx := context.TODO()
+	body := fmt.Sprintf("Your verification code is %d. It expires in %d minutes.\n", code, int(emailVerificationExpiryDuration.Minutes()))
+	if err := service.emailSender.Send(ctx, email, emailVerificationEmailSubject, body); err != nil {
+		logger.Log.Error(ctx, err)
+	}
+}
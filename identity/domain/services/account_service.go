@@ -0,0 +1,175 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/constants"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"github.com/devesh2997/consequent/logger"
+	userServices "github.com/devesh2997/consequent/user/domain/services"
+)
+
+type AccountService interface {
+	// RequestDeletion schedules the deletion of the account of the user once the grace period has passed. The
+	// pending request is returned as it is if the user has already asked for the deletion.
+	RequestDeletion(ctx context.Context, userID int64) (*entities.AccountDeletionRequest, error)
+	CancelDeletion(ctx context.Context, userID int64) error
+	// GetDeletionRequest returns the pending deletion request of the user.
+	GetDeletionRequest(ctx context.Context, userID int64) (*entities.AccountDeletionRequest, error)
+	// ProcessDueDeletions deletes the accounts whose grace period has passed, reading batchSize requests at a time, and
+	// returns how many were deleted. Accounts that fail to be deleted are logged and left pending for the next run.
+	ProcessDueDeletions(ctx context.Context, batchSize int) (int, error)
+	// DeleteAccount deletes the account of the user straight away, completing its pending deletion request if there
+	// is one. Every session and api key of the user is revoked, and the personal data of the user is cleared.
+	DeleteAccount(ctx context.Context, userID int64) error
+	// Export returns everything that is held about the user.
+	Export(ctx context.Context, userID int64) (*entities.AccountExport, error)
+}
+
+func NewAccountService(repo repositories.AccountRepo, userService userServices.UserService, roleService userServices.RoleService, tokenService TokenService, gracePeriod time.Duration) AccountService {
+	return accountService{
+		repo:         repo,
+		userService:  userService,
+		roleService:  roleService,
+		tokenService: tokenService,
+		gracePeriod:  gracePeriod,
+	}
+}
+
+type accountService struct {
+	repo         repositories.AccountRepo
+	userService  userServices.UserService
+	roleService  userServices.RoleService
+	tokenService TokenService
+	gracePeriod  time.Duration
+}
+
+func (service accountService) RequestDeletion(ctx context.Context, userID int64) (*entities.AccountDeletionRequest, error) {
+	request, err := service.repo.GetPendingAccountDeletionRequest(ctx, userID)
+	if err != nil && err != repositories.ErrAccountDeletionRequestNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == nil {
+		return request, nil
+	}
+
+	now := time.Now()
+	err = service.repo.SaveAccountDeletionRequest(ctx, entities.AccountDeletionRequest{
+		UserID:      userID,
+		Status:      constants.ACCOUNT_DELETION_STATUS_PENDING,
+		ScheduledAt: now.Add(service.gracePeriod),
+		CreatedAt:   now,
+	})
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return service.GetDeletionRequest(ctx, userID)
+}
+
+func (service accountService) CancelDeletion(ctx context.Context, userID int64) error {
+	cancelled, err := service.repo.CancelAccountDeletionRequest(ctx, userID)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+	if !cancelled {
+		return errAccountDeletionNotRequested()
+	}
+
+	return nil
+}
+
+func (service accountService) GetDeletionRequest(ctx context.Context, userID int64) (*entities.AccountDeletionRequest, error) {
+	request, err := service.repo.GetPendingAccountDeletionRequest(ctx, userID)
+	if err != nil && err != repositories.ErrAccountDeletionRequestNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrAccountDeletionRequestNotFound {
+		return nil, errAccountDeletionNotRequested()
+	}
+
+	return request, nil
+}
+
+func (service accountService) ProcessDueDeletions(ctx context.Context, batchSize int) (int, error) {
+	deleted := 0
+	// requests that fail are skipped by their id, so that they do not stop the rest from being processed.
+	afterID := int64(0)
+	now := time.Now()
+	for {
+		requests, err := service.repo.GetDueAccountDeletionRequests(ctx, now, afterID, batchSize)
+		if err != nil {
+			return deleted, errorx.NewSystemError(-1, err)
+		}
+		if len(requests) == 0 {
+			return deleted, nil
+		}
+
+		for _, request := range requests {
+			afterID = request.ID
+			if err := service.deleteAccount(ctx, request.UserID, request.ID); err != nil {
+				logger.Log.Error(ctx, err)
+				continue
+			}
+			deleted++
+		}
+	}
+}
+
+func (service accountService) DeleteAccount(ctx context.Context, userID int64) error {
+	requestID := int64(0)
+	request, err := service.repo.GetPendingAccountDeletionRequest(ctx, userID)
+	if err != nil && err != repositories.ErrAccountDeletionRequestNotFound {
+		return errorx.NewSystemError(-1, err)
+	}
+	if err == nil {
+		requestID = request.ID
+	}
+
+	return service.deleteAccount(ctx, userID, requestID)
+}
+
+func (service accountService) Export(ctx context.Context, userID int64) (*entities.AccountExport, error) {
+	user, err := service.userService.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	export, err := service.repo.GetAccountExport(ctx, *user)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	export.Roles, err = service.roleService.GetUserRoles(ctx, userID)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return export, nil
+}
+
+// deleteAccount revokes the sessions of the user before their refresh tokens are deleted, since the access tokens
+// issued with them are denylisted through the refresh tokens.
+func (service accountService) deleteAccount(ctx context.Context, userID int64, requestID int64) error {
+	user, err := service.userService.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := service.tokenService.RevokeAll(ctx, user.ID); err != nil {
+		return err
+	}
+
+	err = service.repo.DeleteAccount(ctx, *user, requestID)
+	if err != nil && err != repositories.ErrAccountDeletionRequestNotFound {
+		return errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrAccountDeletionRequestNotFound {
+		return errAccountDeletionNotRequested()
+	}
+
+	return nil
+}
@@ -0,0 +1,150 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/constants"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+	userRepositories "github.com/devesh2997/consequent/user/domain/repositories"
+	userServices "github.com/devesh2997/consequent/user/domain/services"
+)
+
+const (
+	// apiKeyPrefix makes api keys recognisable, for instance by secret scanners.
+	apiKeyPrefix        = "csq_"
+	apiKeyBytes         = 32
+	apiKeyDisplayLength = 12
+	apiKeyMaxNameLength = 100
+	apiKeyMaxScopeSize  = 1000
+)
+
+type APIKeyService interface {
+	// Create creates an api key for the user, limited to the scope if it is not empty. Keys with a zero expiresIn do
+	// not expire. The key itself is only ever returned here.
+	Create(ctx context.Context, userID int64, name string, scope string, expiresIn time.Duration) (*entities.CreatedAPIKey, error)
+	// List returns the api keys of the user that have not been revoked, newest first.
+	List(ctx context.Context, userID int64) ([]entities.APIKey, error)
+	Revoke(ctx context.Context, userID int64, id int64) error
+	// Validate checks that the key is active and unexpired, and returns it along with its user and the roles of the
+	// user.
+	Validate(ctx context.Context, key string) (*entities.APIKey, *userEntities.User, []userEntities.Role, error)
+}
+
+func NewAPIKeyService(repo repositories.APIKeyRepo, userService userServices.UserService, roleService userServices.RoleService) APIKeyService {
+	return apiKeyService{repo: repo, userService: userService, roleService: roleService}
+}
+
+type apiKeyService struct {
+	repo        repositories.APIKeyRepo
+	userService userServices.UserService
+	roleService userServices.RoleService
+}
+
+func (service apiKeyService) Create(ctx context.Context, userID int64, name string, scope string, expiresIn time.Duration) (*entities.CreatedAPIKey, error) {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > apiKeyMaxNameLength {
+		return nil, errInvalidAPIKeyName()
+	}
+	if expiresIn < 0 {
+		return nil, errInvalidAPIKeyExpiry()
+	}
+	scope = strings.Join(strings.Fields(scope), " ")
+	if len(scope) > apiKeyMaxScopeSize {
+		return nil, errInvalidAPIKeyScope()
+	}
+
+	secret := make([]byte, apiKeyBytes)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	key := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(secret)
+
+	apiKey := entities.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    key[:apiKeyDisplayLength],
+		KeyHash:   service.hashKey(key),
+		Scope:     scope,
+		Status:    constants.API_KEY_STATUS_ACTIVE,
+		CreatedAt: time.Now(),
+	}
+	if expiresIn > 0 {
+		expiryAt := time.Now().Add(expiresIn)
+		apiKey.ExpiryAt = &expiryAt
+	}
+	if err := service.repo.SaveAPIKey(ctx, apiKey); err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return &entities.CreatedAPIKey{APIKey: apiKey, Key: key}, nil
+}
+
+func (service apiKeyService) List(ctx context.Context, userID int64) ([]entities.APIKey, error) {
+	apiKeys, err := service.repo.GetUserAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return apiKeys, nil
+}
+
+func (service apiKeyService) Revoke(ctx context.Context, userID int64, id int64) error {
+	revoked, err := service.repo.RevokeAPIKey(ctx, userID, id)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+	if !revoked {
+		return errAPIKeyNotFound()
+	}
+
+	return nil
+}
+
+func (service apiKeyService) Validate(ctx context.Context, key string) (*entities.APIKey, *userEntities.User, []userEntities.Role, error) {
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return nil, nil, nil, errInvalidAPIKey()
+	}
+
+	apiKey, err := service.repo.GetAPIKeyByHash(ctx, service.hashKey(key))
+	if err != nil && err != repositories.ErrAPIKeyNotFound {
+		return nil, nil, nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrAPIKeyNotFound || !apiKey.IsActive() || apiKey.HasExpired() {
+		return nil, nil, nil, errInvalidAPIKey()
+	}
+
+	user, err := service.userService.FindByID(ctx, apiKey.UserID)
+	if err != nil && err != userRepositories.ErrUserNotFound {
+		return nil, nil, nil, err
+	}
+	if err == userRepositories.ErrUserNotFound {
+		return nil, nil, nil, errInvalidAPIKey()
+	}
+	if user.IsSuspended() {
+		return nil, nil, nil, errUserSuspended()
+	}
+
+	// unlike access tokens, api keys are long lived, so the roles are read on every request.
+	roles, err := service.roleService.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		return nil, nil, nil, errorx.NewSystemError(-1, err)
+	}
+
+	return apiKey, user, roles, nil
+}
+
+// hashKey hashes api keys, which are random enough to not need a slow hash, and are looked up by their hash.
+func (apiKeyService) hashKey(key string) string {
+	hash := sha256.Sum256([]byte(key))
+
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,65 @@
+package services
+
+import (
+	"context"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+)
+
+func (service identityService) ChangePassword(ctx context.Context, userID int64, currentPassword string, newPassword string) (*entities.Token, error) {
+	activePassword, err := service.repo.GetActiveUserPassword(ctx, userID)
+	if err != nil && err != repositories.ErrUserPasswordNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrUserPasswordNotFound {
+		return nil, errPasswordNotSet()
+	}
+
+	matches, err := service.passwordHasher.Verify(currentPassword, activePassword.Password)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if !matches {
+		return nil, errWrongPassword()
+	}
+
+	if err := service.passwordPolicy.validate(newPassword); err != nil {
+		return nil, err
+	}
+	if err := service.checkPasswordHistory(ctx, userID, newPassword); err != nil {
+		return nil, err
+	}
+
+	user, err := service.userService.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := service.replaceUserPassword(ctx, userID, newPassword); err != nil {
+		return nil, err
+	}
+
+	return service.tokenService.Generate(ctx, *user)
+}
+
+// checkPasswordHistory refuses newPassword if it matches any of the user's recent passwords.
+func (service identityService) checkPasswordHistory(ctx context.Context, userID int64, newPassword string) error {
+	recentPasswords, err := service.repo.GetRecentUserPasswords(ctx, userID, service.passwordPolicy.HistorySize)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	for _, recentPassword := range recentPasswords {
+		matches, err := service.passwordHasher.Verify(newPassword, recentPassword.Password)
+		if err != nil {
+			return errorx.NewSystemError(-1, err)
+		}
+		if matches {
+			return errPasswordRecentlyUsed()
+		}
+	}
+
+	return nil
+}
@@ -1,6 +1,28 @@
 package services
 
-import "github.com/devesh2997/consequent/errorx"
+import (
+	"fmt"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+)
+
+// codes of the errors that clients are expected to handle differently from other failures.
+const (
+	errCodeOTPAttemptsExceeded = 1001
+	errCodeMobileLockedOut     = 1002
+	errCodeOTPSendRateLimited  = 1003
+	// magic links share the send limits of otps, but not their error code
+	errCodeMagicLinkSendRateLimited = 1004
+	errCodeEmailLinkSendRateLimited = 1005
+	// the identifier can still be linked by merging the users, which clients are expected to offer.
+	errCodeIdentifierBelongsToAnotherUser = 1006
+	// the user that has the email of the id token has to sign in and link the identity provider explicitly.
+	errCodeExternalIdentityNotLinked = 1007
+	errCodeSecondFactorLockedOut     = 1008
+	// password resets share the send limits of otps and links, but not their error codes
+	errCodePasswordResetSendRateLimited = 1009
+)
 
 var (
 	errUserAlreadyExistsForEmail = func() error {
@@ -21,10 +43,181 @@ var (
 	errOTPHasExpired = func() error {
 		return errorx.NewBusinessError(-1, "otp has expired")
 	}
+	errOTPAttemptsExceeded = func() error {
+		return errorx.NewBusinessError(errCodeOTPAttemptsExceeded, "too many wrong attempts, please request a new otp")
+	}
+	errOTPSendRateLimited = func(retryAt time.Time) error {
+		return errorx.NewTooManyRequestsError(errCodeOTPSendRateLimited, "too many otp requests, please try again later", retryAt)
+	}
+	errMobileLockedOut = func() error {
+		return errorx.NewBusinessError(errCodeMobileLockedOut, "too many failed otp verifications for this mobile number, please try again later")
+	}
 	errUserNotFoundForEmail = func() error {
 		return errorx.NewBusinessError(-1, "user not found for email")
 	}
 	errInvalidPassword = func() error {
 		return errorx.NewBusinessError(-1, "invalid password")
 	}
+	errUnknownIdentityProvider = func() error {
+		return errorx.NewBusinessError(-1, "unknown identity provider")
+	}
+	errInvalidIDToken = func() error {
+		return errorx.NewUnauthorizedError(-1, "invalid id token")
+	}
+	errInvalidRefreshToken = func() error {
+		return errorx.NewUnauthorizedError(-1, "invalid refresh token")
+	}
+	errPasswordTooShort = func(minLength int) error {
+		return errorx.NewBusinessError(-1, fmt.Sprintf("password must be at least %d characters long", minLength))
+	}
+	errPasswordLetterRequired = func() error {
+		return errorx.NewBusinessError(-1, "password must contain a letter")
+	}
+	errPasswordDigitRequired = func() error {
+		return errorx.NewBusinessError(-1, "password must contain a digit")
+	}
+	errPasswordRecentlyUsed = func() error {
+		return errorx.NewBusinessError(-1, "password has been used recently, please choose a different one")
+	}
+	errPasswordNotSet = func() error {
+		return errorx.NewBusinessError(-1, "password has not been set for the user")
+	}
+	errUserNotFoundForMobile = func() error {
+		return errorx.NewBusinessError(-1, "user not found for mobile number")
+	}
+	errInvalidPasswordResetToken = func() error {
+		return errorx.NewBusinessError(-1, "invalid password reset token")
+	}
+	errPasswordResetTokenHasExpired = func() error {
+		return errorx.NewBusinessError(-1, "password reset token has expired")
+	}
+	errUserSuspended = func() error {
+		return errorx.NewUnauthorizedError(-1, "user has been suspended")
+	}
+	errRefreshTokenReused = func() error {
+		return errorx.NewUnauthorizedError(-1, "refresh token has already been used")
+	}
+	errInvalidToken = func() error {
+		return errorx.NewUnauthorizedError(-1, "invalid token")
+	}
+	errTokenExpired = func() error {
+		return errorx.NewUnauthorizedError(-1, "token has expired")
+	}
+	errTokenNotValidYet = func() error {
+		return errorx.NewUnauthorizedError(-1, "token is not valid yet")
+	}
+	errInvalidTokenIssuer = func() error {
+		return errorx.NewUnauthorizedError(-1, "token has an invalid issuer")
+	}
+	errInvalidTokenAudience = func() error {
+		return errorx.NewUnauthorizedError(-1, "token has an invalid audience")
+	}
+	errTOTPAlreadyEnabled = func() error {
+		return errorx.NewBusinessError(-1, "two-factor authentication is already enabled")
+	}
+	errTOTPNotEnrolled = func() error {
+		return errorx.NewBusinessError(-1, "two-factor authentication is not enrolled")
+	}
+	errInvalidTOTPCode = func() error {
+		return errorx.NewBusinessError(-1, "invalid authentication code")
+	}
+	errInvalidRecoveryCode = func() error {
+		return errorx.NewBusinessError(-1, "invalid recovery code")
+	}
+	errInvalidSecondFactorChallenge = func() error {
+		return errorx.NewUnauthorizedError(-1, "invalid or expired second factor challenge, please sign in again")
+	}
+	errPasskeysNotConfigured = func() error {
+		return errorx.NewBusinessError(-1, "passkeys are not available")
+	}
+	errInvalidPasskeyChallenge = func() error {
+		return errorx.NewBusinessError(-1, "invalid or expired passkey challenge, please try again")
+	}
+	errInvalidPasskey = func(err error) error {
+		return errorx.NewUnauthorizedError(-1, "passkey could not be verified: "+err.Error())
+	}
+	errPasskeyNotFound = func() error {
+		return errorx.NewUnauthorizedError(-1, "passkey not found")
+	}
+	errPasskeyAlreadyRegistered = func() error {
+		return errorx.NewBusinessError(-1, "passkey is already registered")
+	}
+	errPasswordResetByEmailNotConfigured = func() error {
+		return errorx.NewBusinessError(-1, "password reset by email is not available, please use your mobile number")
+	}
+	errPasswordResetSendRateLimited = func(retryAt time.Time) error {
+		return errorx.NewTooManyRequestsError(errCodePasswordResetSendRateLimited, "too many password reset requests, please try again later", retryAt)
+	}
+	errMagicLinksNotConfigured = func() error {
+		return errorx.NewBusinessError(-1, "sign in with a link is not available")
+	}
+	errMagicLinkSendRateLimited = func(retryAt time.Time) error {
+		return errorx.NewTooManyRequestsError(errCodeMagicLinkSendRateLimited, "too many sign in link requests, please try again later", retryAt)
+	}
+	errInvalidMagicLink = func() error {
+		return errorx.NewUnauthorizedError(-1, "invalid, expired or already used sign in link, please request a new one")
+	}
+	errSessionNotFound = func() error {
+		return errorx.NewNotFoundError(-1, "session", "sql")
+	}
+	errInvalidAPIKeyName = func() error {
+		return errorx.NewBusinessError(-1, "api key name is required and can be at most 100 characters long")
+	}
+	errInvalidAPIKeyExpiry = func() error {
+		return errorx.NewBusinessError(-1, "api key expiry cannot be in the past")
+	}
+	errInvalidAPIKeyScope = func() error {
+		return errorx.NewBusinessError(-1, "api key scope is too long")
+	}
+	errAPIKeyNotFound = func() error {
+		return errorx.NewNotFoundError(-1, "api key", "sql")
+	}
+	errInvalidAPIKey = func() error {
+		return errorx.NewUnauthorizedError(-1, "invalid, expired or revoked api key")
+	}
+	errTokenRevoked = func() error {
+		return errorx.NewUnauthorizedError(-1, "token has been revoked")
+	}
+	errEmailLinkSendRateLimited = func(retryAt time.Time) error {
+		return errorx.NewTooManyRequestsError(errCodeEmailLinkSendRateLimited, "too many verification code requests, please try again later", retryAt)
+	}
+	errEmailAlreadyLinked = func() error {
+		return errorx.NewBusinessError(-1, "an email is already linked to the user")
+	}
+	errMobileAlreadyLinked = func() error {
+		return errorx.NewBusinessError(-1, "a mobile number is already linked to the user")
+	}
+	errEmailBelongsToAnotherUser = func() error {
+		return errorx.NewBusinessError(errCodeIdentifierBelongsToAnotherUser, "the email belongs to another user, sign in to it and verify the email again with its access token as the merge token to combine the users")
+	}
+	errMobileBelongsToAnotherUser = func() error {
+		return errorx.NewBusinessError(errCodeIdentifierBelongsToAnotherUser, "the mobile number belongs to another user, sign in to it and verify the mobile number again with its access token as the merge token to combine the users")
+	}
+	errInvalidEmailVerification = func() error {
+		return errorx.NewBusinessError(-1, "invalid or expired email verification, please request a new code")
+	}
+	errInvalidEmailVerificationCode = func() error {
+		return errorx.NewBusinessError(-1, "invalid verification code")
+	}
+	errEmailVerificationAttemptsExceeded = func() error {
+		return errorx.NewBusinessError(-1, "too many wrong attempts, please request a new code")
+	}
+	errInvalidMergeToken = func() error {
+		return errorx.NewUnauthorizedError(-1, "the merge token must be an access token of the other user from a sign in within the last few minutes")
+	}
+	errUsersCannotBeMerged = func(reason string) error {
+		return errorx.NewBusinessError(-1, "users cannot be merged: "+reason)
+	}
+	errSecondFactorLockedOut = func() error {
+		return errorx.NewBusinessError(errCodeSecondFactorLockedOut, "too many failed two-factor verifications, please try again later")
+	}
+	errExternalIdentityNotLinked = func() error {
+		return errorx.NewBusinessError(errCodeExternalIdentityNotLinked, "a user with this email already exists, sign in to it to link the identity provider")
+	}
+	errExternalIdentityLinkedToAnotherUser = func() error {
+		return errorx.NewBusinessError(-1, "the identity provider account is already linked to another user")
+	}
+	errAccountDeletionNotRequested = func() error {
+		return errorx.NewNotFoundError(-1, "account deletion request", "sql")
+	}
 )
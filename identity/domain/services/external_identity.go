@@ -0,0 +1,126 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"github.com/devesh2997/consequent/idtoken"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+	userRepositories "github.com/devesh2997/consequent/user/domain/repositories"
+)
+
+func (service identityService) SignInWithIDToken(ctx context.Context, provider string, idToken string, nonce string) (*entities.SignInResult, error) {
+	claims, err := service.verifyIDToken(ctx, provider, idToken, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := service.findOrCreateExternalUser(ctx, provider, *claims)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsSuspended() {
+		return nil, errUserSuspended()
+	}
+
+	return service.signInResult(ctx, *user)
+}
+
+func (service identityService) LinkExternalIdentity(ctx context.Context, userID int64, provider string, idToken string, nonce string) error {
+	claims, err := service.verifyIDToken(ctx, provider, idToken, nonce)
+	if err != nil {
+		return err
+	}
+
+	externalIdentity, err := service.repo.GetUserExternalIdentity(ctx, provider, claims.Subject)
+	if err != nil && err != repositories.ErrUserExternalIdentityNotFound {
+		return errorx.NewSystemError(-1, err)
+	}
+	if err == nil {
+		if externalIdentity.UserID != userID {
+			return errExternalIdentityLinkedToAnotherUser()
+		}
+
+		return nil
+	}
+
+	err = service.repo.SaveUserExternalIdentity(ctx, entities.UserExternalIdentity{
+		UserID:    userID,
+		Provider:  provider,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	return nil
+}
+
+func (service identityService) verifyIDToken(ctx context.Context, provider string, idToken string, nonce string) (*idtoken.Claims, error) {
+	claims, err := service.idTokenVerifier.Verify(ctx, provider, idToken, nonce)
+	if errors.Is(err, idtoken.ErrUnknownProvider) {
+		return nil, errUnknownIdentityProvider()
+	}
+	if errors.Is(err, idtoken.ErrInvalidToken) {
+		return nil, errInvalidIDToken()
+	}
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return claims, nil
+}
+
+// findOrCreateExternalUser returns the user linked to the subject of the provider. Users that are not linked yet are
+// found by their email if both the provider and the user have verified it, or created otherwise, and linked to the
+// subject. A user whose email is not verified may not own it, so the provider is never linked to them here.
+func (service identityService) findOrCreateExternalUser(ctx context.Context, provider string, claims idtoken.Claims) (*userEntities.User, error) {
+	externalIdentity, err := service.repo.GetUserExternalIdentity(ctx, provider, claims.Subject)
+	if err != nil && err != repositories.ErrUserExternalIdentityNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == nil {
+		return service.userService.FindByID(ctx, externalIdentity.UserID)
+	}
+
+	var user *userEntities.User
+	if claims.IsEmailVerified() {
+		user, err = service.userService.FindByEmail(ctx, claims.Email)
+		if err != nil && err != userRepositories.ErrUserNotFound {
+			return nil, err
+		}
+		if user != nil && !user.EmailVerified {
+			return nil, errExternalIdentityNotLinked()
+		}
+	}
+	if user == nil {
+		newUser := userEntities.User{}
+		if claims.IsEmailVerified() {
+			newUser.Email = claims.Email
+			newUser.EmailVerified = true
+		}
+		user, err = service.userService.Create(ctx, newUser)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err = service.repo.SaveUserExternalIdentity(ctx, entities.UserExternalIdentity{
+		UserID:    user.ID,
+		Provider:  provider,
+		Subject:   claims.Subject,
+		Email:     claims.Email,
+		CreatedAt: time.Now(),
+	})
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return user, nil
+}
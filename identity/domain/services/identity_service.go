@@ -8,15 +8,22 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/devesh2997/consequent/contextx"
+	"github.com/devesh2997/consequent/emailsender"
 	"github.com/devesh2997/consequent/errorx"
 	"github.com/devesh2997/consequent/identity/constants"
 	"github.com/devesh2997/consequent/identity/domain/entities"
 	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"github.com/devesh2997/consequent/idtoken"
 	"github.com/devesh2997/consequent/logger"
+	"github.com/devesh2997/consequent/magiclinksender"
 	"github.com/devesh2997/consequent/otpsender"
+	"github.com/devesh2997/consequent/passwordhash"
+	"github.com/devesh2997/consequent/ratelimit"
 	userEntities "github.com/devesh2997/consequent/user/domain/entities"
 	userRepositories "github.com/devesh2997/consequent/user/domain/repositories"
 	"github.com/devesh2997/consequent/user/domain/services"
+	"github.com/devesh2997/consequent/webauthn"
 	"github.com/google/uuid"
 )
 
@@ -26,22 +33,118 @@ const (
 
 type IdentityService interface {
 	SendOTP(ctx context.Context, mobileNumber string) (verificationID string, err error)
-	VerifyOTP(ctx context.Context, verificationID string, mobileNumber string, otp int) (*entities.Token, error)
+	// VerifyOTP returns the tokens of the user with the mobile number, or a second factor challenge if the user has
+	// enabled two-factor authentication. A user is created for mobile numbers that are not registered yet.
+	VerifyOTP(ctx context.Context, verificationID string, mobileNumber string, otp int) (*entities.SignInResult, error)
 	ResendOTP(ctx context.Context, verificationID string) (string, error)
 	IsEmailRegistered(ctx context.Context, email string) (bool, error)
 	SignUpWithEmail(ctx context.Context, email string, password string) (*entities.Token, error)
-	SignInWithEmailAndPassword(ctx context.Context, email string, password string) (*entities.Token, error)
+	// SignInWithEmailAndPassword returns the tokens of the user, or a second factor challenge if the user has
+	// enabled two-factor authentication.
+	SignInWithEmailAndPassword(ctx context.Context, email string, password string) (*entities.SignInResult, error)
+	// VerifySecondFactor exchanges a second factor challenge and a totp or recovery code for the tokens of the user.
+	VerifySecondFactor(ctx context.Context, challengeToken string, code string) (*entities.Token, error)
+	// EnrollTOTP generates a new totp secret for the user. Two-factor authentication is enabled once the enrollment
+	// is confirmed with a first code.
+	EnrollTOTP(ctx context.Context, userID int64) (*entities.TOTPEnrollment, error)
+	// ConfirmTOTP enables two-factor authentication with the enrolled secret and returns the recovery codes of the
+	// user, which are only ever returned once.
+	ConfirmTOTP(ctx context.Context, userID int64, code string) (recoveryCodes []string, err error)
+	// RegenerateRecoveryCodes replaces the recovery codes of the user after verifying a totp code.
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) (recoveryCodes []string, err error)
+	// BeginPasskeyRegistration starts the registration of a passkey for the user, and returns the options that the
+	// authenticator creates the passkey with.
+	BeginPasskeyRegistration(ctx context.Context, userID int64) (*entities.PasskeyRegistrationOptions, error)
+	// FinishPasskeyRegistration verifies the response of the authenticator and saves the passkey of the user.
+	FinishPasskeyRegistration(ctx context.Context, userID int64, challengeID string, registration entities.PasskeyRegistration) error
+	// BeginPasskeyLogin starts a sign in with a passkey, and returns the options that the authenticator is asked
+	// for an assertion with.
+	BeginPasskeyLogin(ctx context.Context) (*entities.PasskeyLoginOptions, error)
+	// FinishPasskeyLogin verifies the assertion of the authenticator and returns the tokens of the passkey's user.
+	FinishPasskeyLogin(ctx context.Context, challengeID string, assertion entities.PasskeyAssertion) (*entities.Token, error)
+	// SignInWithIDToken signs in the user of an id token issued by an external identity provider, such as google or
+	// apple. Users are linked to the provider's subject on their first sign in if they have verified the email of
+	// the token as well, and created if no user has the email. A user with the email that has not verified it has
+	// to link the provider with LinkExternalIdentity instead. A second factor challenge is returned instead of the
+	// tokens if the user has enabled two-factor authentication. The nonce is required.
+	SignInWithIDToken(ctx context.Context, provider string, idToken string, nonce string) (*entities.SignInResult, error)
+	// LinkExternalIdentity links the subject of an id token issued by an external identity provider to the signed
+	// in user, so that they can sign in with the provider afterwards. The nonce is required.
+	LinkExternalIdentity(ctx context.Context, userID int64, provider string, idToken string, nonce string) error
+	// SendMagicLink emails a single-use sign in link to the given address.
+	SendMagicLink(ctx context.Context, email string) error
+	// VerifyMagicLink exchanges the token of a sign in link for the tokens of the user with its email, or a second
+	// factor challenge if the user has enabled two-factor authentication. A user is created for emails that are not
+	// registered yet.
+	VerifyMagicLink(ctx context.Context, token string) (*entities.SignInResult, error)
+	// SuspendUser suspends the given user and revokes all of their sessions.
+	SuspendUser(ctx context.Context, userID int64) error
+	// HashPlaintextPasswords hashes every stored password that is still in plaintext, batchSize rows at a time.
+	// It returns the number of passwords that were hashed.
+	HashPlaintextPasswords(ctx context.Context, batchSize int) (int, error)
+	// RequestPasswordReset starts a password reset for the user with the given email or mobile number. Emails
+	// receive a one-time link and mobile numbers receive an otp. The returned reset id identifies the request.
+	RequestPasswordReset(ctx context.Context, emailOrMobile string) (resetID string, err error)
+	// ConfirmPasswordReset replaces the password of the user once the secret (the otp or the token from the link)
+	// has been verified, and revokes all of the user's sessions.
+	ConfirmPasswordReset(ctx context.Context, resetID string, secret string, newPassword string) error
+	// ChangePassword replaces the password of the user after verifying the current one. Every session of the
+	// user is revoked, and a new token is returned for the session that made the change.
+	ChangePassword(ctx context.Context, userID int64, currentPassword string, newPassword string) (*entities.Token, error)
+	// SendEmailLinkCode emails a code to verify the email before it is linked to the user, who must not have an
+	// email yet. The returned verification id identifies the code.
+	SendEmailLinkCode(ctx context.Context, userID int64, email string) (verificationID string, err error)
+	// LinkEmail links the email of a verification to the user once its code has been verified. An email that
+	// belongs to another user is only linked by merging the other user into this one, for which mergeToken must be
+	// a fresh access token of the other user.
+	LinkEmail(ctx context.Context, userID int64, verificationID string, code string, mergeToken string) (*userEntities.User, error)
+	// LinkMobile links the mobile number to the user once the otp sent to it with SendOTP has been verified. A
+	// mobile number that belongs to another user is only linked by merging the other user into this one, for which
+	// mergeToken must be a fresh access token of the other user.
+	LinkMobile(ctx context.Context, userID int64, verificationID string, mobileNumber string, otp int, mergeToken string) (*userEntities.User, error)
+	// MergeUsers merges a user into another, once someone has proved that they own both. Passwords, sessions,
+	// passkeys, api keys, roles and every other row of the merged user are moved to the other user, which also
+	// takes the identifiers it does not have yet. Users with different emails or mobile numbers are not merged.
+	// The sessions of the merged user are revoked first, so that none of its tokens can be used afterwards.
+	MergeUsers(ctx context.Context, intoUserID int64, fromUserID int64) (*userEntities.User, error)
 }
 
-func NewIdentityService(repo repositories.IdentityRepo, userService services.UserService, tokenService TokenService, otpSender otpsender.OTPSender) IdentityService {
-	return identityService{repo: repo, userService: userService, tokenService: tokenService, otpSender: otpSender}
+func NewIdentityService(repo repositories.IdentityRepo, userService services.UserService, tokenService TokenService, otpSender otpsender.OTPSender, emailSender emailsender.EmailSender, passwordHasher passwordhash.PasswordHasher, passwordPolicy PasswordPolicy, otpPolicy OTPPolicy, rateLimiter ratelimit.Limiter, idTokenVerifier idtoken.Verifier, totpIssuer string, relyingParty webauthn.RelyingParty, magicLinkSender magiclinksender.MagicLinkSender, passwordResetLinkURL string, magicLinkURL string) IdentityService {
+	return identityService{
+		repo:                 repo,
+		userService:          userService,
+		tokenService:         tokenService,
+		otpSender:            otpSender,
+		emailSender:          emailSender,
+		passwordHasher:       passwordHasher,
+		passwordPolicy:       passwordPolicy,
+		otpPolicy:            otpPolicy,
+		rateLimiter:          rateLimiter,
+		idTokenVerifier:      idTokenVerifier,
+		totpIssuer:           totpIssuer,
+		relyingParty:         relyingParty,
+		magicLinkSender:      magicLinkSender,
+		passwordResetLinkURL: passwordResetLinkURL,
+		magicLinkURL:         magicLinkURL,
+	}
 }
 
 type identityService struct {
-	repo         repositories.IdentityRepo
-	userService  services.UserService
-	otpSender    otpsender.OTPSender
-	tokenService TokenService
+	repo                 repositories.IdentityRepo
+	userService          services.UserService
+	otpSender            otpsender.OTPSender
+	emailSender          emailsender.EmailSender
+	tokenService         TokenService
+	passwordHasher       passwordhash.PasswordHasher
+	passwordPolicy       PasswordPolicy
+	otpPolicy            OTPPolicy
+	rateLimiter          ratelimit.Limiter
+	idTokenVerifier      idtoken.Verifier
+	totpIssuer           string
+	relyingParty         webauthn.RelyingParty
+	magicLinkSender      magiclinksender.MagicLinkSender
+	passwordResetLinkURL string
+	magicLinkURL         string
 }
 
 func (identityService) generateOTP(numDigits int) (int, error) {
@@ -68,6 +171,12 @@ func (service identityService) SendOTP(ctx context.Context, mobileNumber string)
 	if err := service.validateMobile(mobileNumber); err != nil {
 		return "", err
 	}
+	if err := service.checkOTPLockout(ctx, mobileNumber); err != nil {
+		return "", err
+	}
+	if err := service.checkOTPSendLimits(ctx, mobileNumber); err != nil {
+		return "", err
+	}
 	otp, err := service.generateOTP(4)
 	if err != nil {
 		return "", errorx.NewSystemError(-1, err)
@@ -79,6 +188,7 @@ func (service identityService) SendOTP(ctx context.Context, mobileNumber string)
 		Mobile:         mobileNumber,
 		OTP:            otp,
 		Status:         constants.USER_LOGIN_MOBILE_OTP_STATUS_ACTIVE,
+		MaxAttempts:    service.otpPolicy.MaxAttempts,
 		CreatedAt:      time.Now(),
 		ExpiryAt:       time.Now().Add(otpExpiryDuration),
 	})
@@ -106,7 +216,7 @@ func (service identityService) validateMobile(mobileNumber string) error {
 	return nil
 }
 
-func (service identityService) VerifyOTP(ctx context.Context, verificationID string, mobileNumber string, otp int) (*entities.Token, error) {
+func (service identityService) VerifyOTP(ctx context.Context, verificationID string, mobileNumber string, otp int) (*entities.SignInResult, error) {
 	if err := service.verifyOTP(ctx, verificationID, mobileNumber, otp); err != nil {
 		return nil, err
 	}
@@ -124,8 +234,11 @@ func (service identityService) VerifyOTP(ctx context.Context, verificationID str
 			return nil, err
 		}
 	}
+	if user.IsSuspended() {
+		return nil, errUserSuspended()
+	}
 
-	return service.tokenService.Generate(ctx, *user)
+	return service.signInResult(ctx, *user)
 }
 
 func (service identityService) ResendOTP(ctx context.Context, verificationID string) (string, error) {
@@ -133,9 +246,15 @@ func (service identityService) ResendOTP(ctx context.Context, verificationID str
 	if err != nil {
 		return "", errorx.NewSystemError(-1, err)
 	}
+	if err := service.checkOTPLockout(ctx, userLoginMobileOTP.Mobile); err != nil {
+		return "", err
+	}
 	if !userLoginMobileOTP.IsActive() || userLoginMobileOTP.HasExpired() { // TODO (devesh2997) | mark the old otp as expired if neccessary
 		return service.SendOTP(ctx, userLoginMobileOTP.Mobile)
 	}
+	if err := service.checkOTPSendLimits(ctx, userLoginMobileOTP.Mobile); err != nil {
+		return "", err
+	}
 
 	go service.sendOTP(userLoginMobileOTP.Mobile, userLoginMobileOTP.OTP)
 
@@ -152,22 +271,99 @@ func (service identityService) verifyOTP(ctx context.Context, verificationID str
 		return errInvalidMobile()
 	}
 
-	if userLoginMobileOTP.OTP != otp || !userLoginMobileOTP.IsActive() {
+	if err := service.checkOTPLockout(ctx, mobileNumber); err != nil {
+		return err
+	}
+
+	if userLoginMobileOTP.IsBlocked() {
+		return errOTPAttemptsExceeded()
+	}
+
+	if !userLoginMobileOTP.IsActive() {
 		return errInvalidOTP()
 	}
 
-	nextStatus := constants.USER_LOGIN_MOBILE_OTP_STATUS_VERIFIED
 	if userLoginMobileOTP.HasExpired() {
-		nextStatus = constants.USER_LOGIN_MOBILE_OTP_STATUS_EXPIRED
-		err = errOTPHasExpired()
+		userLoginMobileOTP.Status = constants.USER_LOGIN_MOBILE_OTP_STATUS_EXPIRED
+		if err := service.repo.SaveUserLoginMobileOTP(ctx, *userLoginMobileOTP); err != nil {
+			return errorx.NewSystemError(-1, err)
+		}
+
+		return errOTPHasExpired()
+	}
+
+	if userLoginMobileOTP.OTP != otp {
+		recorded, err := service.repo.RecordUserLoginMobileOTPFailure(ctx, userLoginMobileOTP.ID)
+		if err != nil {
+			return errorx.NewSystemError(-1, err)
+		}
+		if !recorded || userLoginMobileOTP.FailedAttempts+1 >= userLoginMobileOTP.MaxAttempts {
+			return errOTPAttemptsExceeded()
+		}
+
+		return errInvalidOTP()
+	}
+
+	// the otp is verified only if it has not been blocked by guesses made concurrently with this one.
+	verified, err := service.repo.MarkUserLoginMobileOTPVerified(ctx, userLoginMobileOTP.ID)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+	if !verified {
+		return errInvalidOTP()
+	}
+
+	return nil
+}
+
+// checkOTPSendLimits counts an otp being sent to the mobile number, and refuses it if the resend interval or any of
+// the send limits of the mobile number, the client ip or all clients together have been exceeded.
+func (service identityService) checkOTPSendLimits(ctx context.Context, mobileNumber string) error {
+	allowed, retryAt, err := service.allowSend(ctx, "otp", "mobile:"+mobileNumber)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errOTPSendRateLimited(retryAt)
 	}
 
-	userLoginMobileOTP.Status = nextStatus
-	if e := service.repo.SaveUserLoginMobileOTP(ctx, *userLoginMobileOTP); e != nil {
-		err = errorx.NewSystemError(-1, e)
+	return nil
+}
+
+// allowSend counts a message being sent to the recipient, and tells whether the resend interval and the send limits
+// of the otp policy allow it. The limits are counted separately for every kind of message, and a message that is not
+// allowed is not counted against any of them.
+func (service identityService) allowSend(ctx context.Context, kind string, recipient string) (bool, time.Time, error) {
+	policy := service.otpPolicy
+	limits := []ratelimit.KeyedLimit{
+		{Key: kind + ":interval:" + recipient, Limit: ratelimit.Limit{Requests: 1, Window: policy.ResendInterval}},
+		{Key: kind + ":" + recipient, Limit: policy.SendLimitPerMobile},
+	}
+	if clientIP := contextx.GetClientIP(ctx); clientIP != "" {
+		limits = append(limits, ratelimit.KeyedLimit{Key: kind + ":ip:" + clientIP, Limit: policy.SendLimitPerIP})
+	}
+	limits = append(limits, ratelimit.KeyedLimit{Key: kind + ":global", Limit: policy.SendLimitGlobal})
+
+	allowed, retryAt, err := service.rateLimiter.AllowAll(ctx, limits)
+	if err != nil {
+		return false, time.Time{}, errorx.NewSystemError(-1, err)
 	}
 
-	return err
+	return allowed, retryAt, nil
+}
+
+// checkOTPLockout refuses otp logins for a mobile number which has had too many otps blocked recently.
+func (service identityService) checkOTPLockout(ctx context.Context, mobileNumber string) error {
+	since := time.Now().Add(-service.otpPolicy.LockoutWindow)
+	blockedOTPs, err := service.repo.CountUserLoginMobileOTPs(ctx, mobileNumber, constants.USER_LOGIN_MOBILE_OTP_STATUS_BLOCKED, since)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+	if blockedOTPs >= int64(service.otpPolicy.LockoutThreshold) {
+		return errMobileLockedOut()
+	}
+
+	return nil
 }
 
 func (service identityService) SignUpWithEmail(ctx context.Context, email string, password string) (*entities.Token, error) {
@@ -179,7 +375,12 @@ func (service identityService) SignUpWithEmail(ctx context.Context, email string
 		return nil, err
 	}
 	if existingUser != nil {
-		errUserAlreadyExistsForEmail()
+		return nil, errUserAlreadyExistsForEmail()
+	}
+
+	hashedPassword, err := service.passwordHasher.Hash(password)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
 	}
 
 	user, err := service.userService.Create(ctx, userEntities.User{Email: email})
@@ -189,7 +390,7 @@ func (service identityService) SignUpWithEmail(ctx context.Context, email string
 
 	err = service.repo.SaveUserPassword(ctx, entities.UserPassword{
 		UserID:   user.ID,
-		Password: password, // TODO (devesh2997) | hashed value should be stored here, not plaintext
+		Password: hashedPassword,
 		Status:   constants.USER_PASSWORD_STATUS_ACTIVE,
 	})
 	if err != nil {
@@ -215,9 +416,12 @@ func (service identityService) IsEmailRegistered(ctx context.Context, email stri
 	return false, nil
 }
 
-func (service identityService) SignInWithEmailAndPassword(ctx context.Context, email string, password string) (*entities.Token, error) {
-	if err := service.validateEmailAndPassword(email, password); err != nil {
-		return nil, err
+func (service identityService) SignInWithEmailAndPassword(ctx context.Context, email string, password string) (*entities.SignInResult, error) {
+	if !service.isEmailValid(email) {
+		return nil, errInvalidEmail()
+	}
+	if password == "" {
+		return nil, errInvalidPassword()
 	}
 	existingUser, err := service.userService.FindByEmail(ctx, email)
 	if err != nil && err != userRepositories.ErrUserNotFound {
@@ -226,24 +430,94 @@ func (service identityService) SignInWithEmailAndPassword(ctx context.Context, e
 	if existingUser == nil {
 		return nil, errUserNotFoundForEmail()
 	}
+	if existingUser.IsSuspended() {
+		return nil, errUserSuspended()
+	}
 
 	userPassword, err := service.repo.GetActiveUserPassword(ctx, existingUser.ID)
+	if err != nil && err != repositories.ErrUserPasswordNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrUserPasswordNotFound {
+		return nil, errWrongPassword()
+	}
+
+	matches, err := service.passwordHasher.Verify(password, userPassword.Password)
 	if err != nil {
-		return nil, err
+		return nil, errorx.NewSystemError(-1, err)
 	}
-	if userPassword.Password != password {
+	if !matches {
 		return nil, errWrongPassword()
 	}
 
-	return service.tokenService.Generate(ctx, *existingUser)
+	if service.passwordHasher.NeedsRehash(userPassword.Password) {
+		service.rehashPassword(ctx, *userPassword, password)
+	}
+
+	return service.signInResult(ctx, *existingUser)
+}
+
+// rehashPassword upgrades the stored hash to the current algorithm and parameters. A failure is only logged,
+// because the user has already been authenticated successfully.
+func (service identityService) rehashPassword(ctx context.Context, userPassword entities.UserPassword, password string) {
+	hashedPassword, err := service.passwordHasher.Hash(password)
+	if err != nil {
+		logger.Log.Error(ctx, errorx.NewSystemError(-1, err))
+		return
+	}
+
+	userPassword.Password = hashedPassword
+	if err := service.repo.UpdateUserPassword(ctx, userPassword); err != nil {
+		logger.Log.Error(ctx, errorx.NewSystemError(-1, err))
+	}
+}
+
+func (service identityService) HashPlaintextPasswords(ctx context.Context, batchSize int) (int, error) {
+	hashed := 0
+	afterID := int64(0)
+	for {
+		userPasswords, err := service.repo.GetUserPasswordsAfterID(ctx, afterID, batchSize)
+		if err != nil {
+			return hashed, errorx.NewSystemError(-1, err)
+		}
+		if len(userPasswords) == 0 {
+			return hashed, nil
+		}
+
+		for _, userPassword := range userPasswords {
+			afterID = userPassword.ID
+			if service.passwordHasher.IsHashed(userPassword.Password) {
+				continue
+			}
+
+			hashedPassword, err := service.passwordHasher.Hash(userPassword.Password)
+			if err != nil {
+				return hashed, errorx.NewSystemError(-1, err)
+			}
+
+			userPassword.Password = hashedPassword
+			if err := service.repo.UpdateUserPassword(ctx, userPassword); err != nil {
+				return hashed, errorx.NewSystemError(-1, err)
+			}
+			hashed++
+		}
+	}
+}
+
+func (service identityService) SuspendUser(ctx context.Context, userID int64) error {
+	if err := service.userService.Suspend(ctx, userID); err != nil {
+		return err
+	}
+
+	return service.tokenService.RevokeAll(ctx, userID)
 }
 
 func (service identityService) validateEmailAndPassword(email string, password string) error {
 	if !service.isEmailValid(email) {
 		return errInvalidEmail()
 	}
-	if !service.isPasswordValid(password) {
-		return errInvalidPassword()
+	if err := service.passwordPolicy.validate(password); err != nil {
+		return err
 	}
 
 	return nil
@@ -254,7 +528,3 @@ func (service identityService) isEmailValid(email string) bool {
 
 	return err == nil
 }
-
-func (service identityService) isPasswordValid(password string) bool {
-	return len(password) > 5 // TODO (devesh2997) | password rules can be much stricter
-}
@@ -0,0 +1,121 @@
+package services
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/constants"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/logger"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+	userRepositories "github.com/devesh2997/consequent/user/domain/repositories"
+)
+
+func (service identityService) SendMagicLink(ctx context.Context, email string) error {
+	if service.magicLinkURL == "" {
+		return errMagicLinksNotConfigured()
+	}
+	if !service.isEmailValid(email) {
+		return errInvalidEmail()
+	}
+
+	allowed, retryAt, err := service.allowSend(ctx, "magic_link", "email:"+email)
+	if err != nil {
+		return err
+	}
+	if !allowed {
+		return errMagicLinkSendRateLimited(retryAt)
+	}
+
+	token, claims, err := service.tokenService.SignMagicLinkToken(ctx, email)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	err = service.repo.SaveMagicLinkToken(ctx, entities.MagicLinkToken{
+		TokenID:   claims.Id,
+		Email:     email,
+		Status:    constants.MAGIC_LINK_TOKEN_STATUS_ACTIVE,
+		CreatedAt: time.Now(),
+		ExpiryAt:  time.Unix(claims.ExpiresAt, 0),
+	})
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	go service.sendMagicLink(email, token)
+
+	return nil
+}
+
+func (service identityService) VerifyMagicLink(ctx context.Context, token string) (*entities.SignInResult, error) {
+	claims, err := service.tokenService.ValidateMagicLinkToken(ctx, token)
+	if err != nil {
+		return nil, errInvalidMagicLink()
+	}
+
+	// the token is consumed before signing in, so that a link can never be used twice.
+	used, err := service.repo.MarkMagicLinkTokenUsed(ctx, claims.Id)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if !used {
+		return nil, errInvalidMagicLink()
+	}
+
+	user, err := service.userService.FindByEmail(ctx, claims.Email)
+	if err != nil && err != userRepositories.ErrUserNotFound {
+		return nil, err
+	}
+
+	if err == userRepositories.ErrUserNotFound {
+		user, err = service.userService.Create(ctx, userEntities.User{
+			Email:         claims.Email,
+			EmailVerified: true,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	if user.IsSuspended() {
+		return nil, errUserSuspended()
+	}
+
+	// following the link proves that the user owns the email.
+	if !user.EmailVerified {
+		user.EmailVerified = true
+		if err := service.userService.Update(ctx, *user); err != nil {
+			return nil, err
+		}
+	}
+
+	return service.signInResult(ctx, *user)
+}
+
+func (service identityService) sendMagicLink(email string, token string) {
+	ctx := context.TODO()
+	link, err := service.getMagicLink(token)
+	if err != nil {
+		logger.Log.Error(ctx, errorx.NewSystemError(-1, err))
+		return
+	}
+
+	if err := service.magicLinkSender.Send(ctx, email, link, int(magicLinkExpiryDuration.Minutes())); err != nil {
+		logger.Log.Error(ctx, err)
+	}
+}
+
+func (service identityService) getMagicLink(token string) (string, error) {
+	link, err := url.Parse(service.magicLinkURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := link.Query()
+	query.Set("token", token)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
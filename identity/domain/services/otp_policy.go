@@ -0,0 +1,75 @@
+package services
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/ratelimit"
+)
+
+const (
+	defaultOTPMaxAttempts      = 5
+	defaultOTPLockoutThreshold = 3
+	defaultOTPLockoutWindow    = time.Minute * 30
+	defaultOTPResendInterval   = time.Second * 30
+)
+
+var (
+	defaultOTPSendLimitPerMobile = ratelimit.Limit{Requests: 5, Window: time.Hour}
+	defaultOTPSendLimitPerIP     = ratelimit.Limit{Requests: 30, Window: time.Hour}
+)
+
+// OTPPolicy limits how many times an otp can be guessed and how often otps can be sent.
+type OTPPolicy struct {
+	// MaxAttempts is the number of failed verifications after which an otp is blocked.
+	MaxAttempts int
+	// LockoutThreshold is the number of blocked otps of a mobile number within LockoutWindow after which
+	// the mobile number is locked out of otp logins.
+	LockoutThreshold int
+	LockoutWindow    time.Duration
+	// SendLimitPerMobile, SendLimitPerIP and SendLimitGlobal limit the number of otps sent to a mobile number,
+	// requested from a client ip and sent in total. The global limit is not enforced unless it is set.
+	SendLimitPerMobile ratelimit.Limit
+	SendLimitPerIP     ratelimit.Limit
+	SendLimitGlobal    ratelimit.Limit
+	// ResendInterval is the minimum time between two otps sent to the same mobile number.
+	ResendInterval time.Duration
+}
+
+// NewOTPPolicy returns the policy with defaults applied to the limits that are not set.
+func NewOTPPolicy(maxAttempts int, lockoutThreshold int, lockoutWindow time.Duration) OTPPolicy {
+	if maxAttempts == 0 {
+		maxAttempts = defaultOTPMaxAttempts
+	}
+	if lockoutThreshold == 0 {
+		lockoutThreshold = defaultOTPLockoutThreshold
+	}
+	if lockoutWindow == 0 {
+		lockoutWindow = defaultOTPLockoutWindow
+	}
+
+	return OTPPolicy{
+		MaxAttempts:      maxAttempts,
+		LockoutThreshold: lockoutThreshold,
+		LockoutWindow:    lockoutWindow,
+	}
+}
+
+// WithSendLimits returns a copy of the policy with the given send limits, applying defaults to the ones not set.
+func (policy OTPPolicy) WithSendLimits(perMobile ratelimit.Limit, perIP ratelimit.Limit, global ratelimit.Limit, resendInterval time.Duration) OTPPolicy {
+	if perMobile.Requests == 0 {
+		perMobile = defaultOTPSendLimitPerMobile
+	}
+	if perIP.Requests == 0 {
+		perIP = defaultOTPSendLimitPerIP
+	}
+	if resendInterval == 0 {
+		resendInterval = defaultOTPResendInterval
+	}
+
+	policy.SendLimitPerMobile = perMobile
+	policy.SendLimitPerIP = perIP
+	policy.SendLimitGlobal = global
+	policy.ResendInterval = resendInterval
+
+	return policy
+}
@@ -0,0 +1,205 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"strconv"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/constants"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"github.com/devesh2997/consequent/webauthn"
+	"github.com/google/uuid"
+)
+
+func (service identityService) BeginPasskeyRegistration(ctx context.Context, userID int64) (*entities.PasskeyRegistrationOptions, error) {
+	if service.relyingParty.ID == "" {
+		return nil, errPasskeysNotConfigured()
+	}
+
+	user, err := service.userService.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	credentials, err := service.repo.GetUserWebAuthnCredentials(ctx, userID)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	// the authenticator refuses to create a second passkey for an account that it already holds one for.
+	excludeCredentialIDs := make([][]byte, 0, len(credentials))
+	for _, credential := range credentials {
+		credentialID, err := base64.RawURLEncoding.DecodeString(credential.CredentialID)
+		if err != nil {
+			continue
+		}
+		excludeCredentialIDs = append(excludeCredentialIDs, credentialID)
+	}
+
+	challenge, err := service.saveWebAuthnChallenge(ctx, userID, constants.WEBAUTHN_CEREMONY_REGISTRATION)
+	if err != nil {
+		return nil, err
+	}
+	challengeBytes, _ := base64.RawURLEncoding.DecodeString(challenge.Challenge)
+
+	accountName := totpAccountName(*user)
+	displayName := user.Name
+	if displayName == "" {
+		displayName = accountName
+	}
+
+	return &entities.PasskeyRegistrationOptions{
+		ChallengeID: challenge.ChallengeID,
+		Options:     service.relyingParty.CreationOptions(challengeBytes, passkeyUserHandle(userID), accountName, displayName, excludeCredentialIDs),
+	}, nil
+}
+
+func (service identityService) FinishPasskeyRegistration(ctx context.Context, userID int64, challengeID string, registration entities.PasskeyRegistration) error {
+	challenge, err := service.useWebAuthnChallenge(ctx, challengeID, constants.WEBAUTHN_CEREMONY_REGISTRATION)
+	if err != nil {
+		return err
+	}
+	if challenge.UserID != userID {
+		return errInvalidPasskeyChallenge()
+	}
+	challengeBytes, _ := base64.RawURLEncoding.DecodeString(challenge.Challenge)
+
+	credential, err := service.relyingParty.VerifyRegistration(challengeBytes, registration.ClientDataJSON, registration.AttestationObject)
+	if err != nil {
+		return errInvalidPasskey(err)
+	}
+
+	credentialID := base64.RawURLEncoding.EncodeToString(credential.ID)
+	_, err = service.repo.GetWebAuthnCredential(ctx, credentialID)
+	if err != nil && err != repositories.ErrWebAuthnCredentialNotFound {
+		return errorx.NewSystemError(-1, err)
+	}
+	if err == nil {
+		return errPasskeyAlreadyRegistered()
+	}
+
+	err = service.repo.SaveWebAuthnCredential(ctx, entities.WebAuthnCredential{
+		UserID:            userID,
+		CredentialID:      credentialID,
+		PublicKey:         credential.PublicKey,
+		SignCount:         int64(credential.SignCount),
+		AAGUID:            hex.EncodeToString(credential.AAGUID),
+		AttestationFormat: credential.AttestationFormat,
+		LastUsedAt:        time.Now(),
+		CreatedAt:         time.Now(),
+	})
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	return nil
+}
+
+func (service identityService) BeginPasskeyLogin(ctx context.Context) (*entities.PasskeyLoginOptions, error) {
+	if service.relyingParty.ID == "" {
+		return nil, errPasskeysNotConfigured()
+	}
+
+	challenge, err := service.saveWebAuthnChallenge(ctx, 0, constants.WEBAUTHN_CEREMONY_AUTHENTICATION)
+	if err != nil {
+		return nil, err
+	}
+	challengeBytes, _ := base64.RawURLEncoding.DecodeString(challenge.Challenge)
+
+	return &entities.PasskeyLoginOptions{
+		ChallengeID: challenge.ChallengeID,
+		Options:     service.relyingParty.RequestOptions(challengeBytes),
+	}, nil
+}
+
+func (service identityService) FinishPasskeyLogin(ctx context.Context, challengeID string, assertion entities.PasskeyAssertion) (*entities.Token, error) {
+	challenge, err := service.useWebAuthnChallenge(ctx, challengeID, constants.WEBAUTHN_CEREMONY_AUTHENTICATION)
+	if err != nil {
+		return nil, err
+	}
+	challengeBytes, _ := base64.RawURLEncoding.DecodeString(challenge.Challenge)
+
+	credential, err := service.repo.GetWebAuthnCredential(ctx, base64.RawURLEncoding.EncodeToString(assertion.CredentialID))
+	if err != nil && err != repositories.ErrWebAuthnCredentialNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrWebAuthnCredentialNotFound {
+		return nil, errPasskeyNotFound()
+	}
+	if len(assertion.UserHandle) != 0 && !bytes.Equal(assertion.UserHandle, passkeyUserHandle(credential.UserID)) {
+		return nil, errPasskeyNotFound()
+	}
+
+	signCount, err := service.relyingParty.VerifyAssertion(challengeBytes, credential.PublicKey, uint32(credential.SignCount), assertion.ClientDataJSON, assertion.AuthenticatorData, assertion.Signature)
+	if err != nil {
+		return nil, errInvalidPasskey(err)
+	}
+	updated, err := service.repo.UpdateWebAuthnCredentialSignCount(ctx, credential.ID, credential.SignCount, int64(signCount))
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if !updated {
+		return nil, errInvalidPasskey(webauthn.ErrSignCountNotIncreased)
+	}
+
+	user, err := service.userService.FindByID(ctx, credential.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsSuspended() {
+		return nil, errUserSuspended()
+	}
+
+	return service.tokenService.Generate(ctx, *user)
+}
+
+func (service identityService) saveWebAuthnChallenge(ctx context.Context, userID int64, ceremony string) (*entities.WebAuthnChallenge, error) {
+	challengeBytes, err := webauthn.GenerateChallenge()
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	challenge := entities.WebAuthnChallenge{
+		ChallengeID: uuid.New().String(),
+		Challenge:   base64.RawURLEncoding.EncodeToString(challengeBytes),
+		UserID:      userID,
+		Ceremony:    ceremony,
+		Status:      constants.WEBAUTHN_CHALLENGE_STATUS_ACTIVE,
+		CreatedAt:   time.Now(),
+		ExpiryAt:    time.Now().Add(webauthn.Timeout),
+	}
+	if err := service.repo.SaveWebAuthnChallenge(ctx, challenge); err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return &challenge, nil
+}
+
+// useWebAuthnChallenge consumes the challenge of a ceremony, so that every challenge is only ever answered once.
+func (service identityService) useWebAuthnChallenge(ctx context.Context, challengeID string, ceremony string) (*entities.WebAuthnChallenge, error) {
+	challenge, err := service.repo.GetWebAuthnChallenge(ctx, challengeID)
+	if err != nil && err != repositories.ErrWebAuthnChallengeNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrWebAuthnChallengeNotFound || challenge.Ceremony != ceremony || !challenge.IsActive() || challenge.HasExpired() {
+		return nil, errInvalidPasskeyChallenge()
+	}
+
+	used, err := service.repo.MarkWebAuthnChallengeUsed(ctx, challenge.ID)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if !used {
+		return nil, errInvalidPasskeyChallenge()
+	}
+
+	return challenge, nil
+}
+
+// passkeyUserHandle is the user.id of the passkeys of a user, which the authenticator returns on sign in.
+func passkeyUserHandle(userID int64) []byte {
+	return []byte(strconv.FormatInt(userID, 10))
+}
@@ -0,0 +1,57 @@
+package services
+
+import "unicode"
+
+const (
+	defaultPasswordMinLength   = 6
+	defaultPasswordHistorySize = 5
+)
+
+// PasswordPolicy describes the rules that every new password has to follow.
+type PasswordPolicy struct {
+	MinLength     int
+	RequireLetter bool
+	RequireDigit  bool
+	// HistorySize is the number of most recent passwords of a user that cannot be used again.
+	HistorySize int
+}
+
+// NewPasswordPolicy returns the policy with defaults applied to the limits that are not set.
+func NewPasswordPolicy(minLength int, requireLetter bool, requireDigit bool, historySize int) PasswordPolicy {
+	if minLength == 0 {
+		minLength = defaultPasswordMinLength
+	}
+	if historySize == 0 {
+		historySize = defaultPasswordHistorySize
+	}
+
+	return PasswordPolicy{
+		MinLength:     minLength,
+		RequireLetter: requireLetter,
+		RequireDigit:  requireDigit,
+		HistorySize:   historySize,
+	}
+}
+
+func (policy PasswordPolicy) validate(password string) error {
+	if len([]rune(password)) < policy.MinLength {
+		return errPasswordTooShort(policy.MinLength)
+	}
+
+	hasLetter, hasDigit := false, false
+	for _, r := range password {
+		if unicode.IsLetter(r) {
+			hasLetter = true
+		} else if unicode.IsDigit(r) {
+			hasDigit = true
+		}
+	}
+	if policy.RequireLetter && !hasLetter {
+		return errPasswordLetterRequired()
+	}
+	if policy.RequireDigit && !hasDigit {
+		return errPasswordDigitRequired()
+	}
+
+	return nil
+}
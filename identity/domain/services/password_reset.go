@@ -0,0 +1,231 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/constants"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"github.com/devesh2997/consequent/logger"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+	userRepositories "github.com/devesh2997/consequent/user/domain/repositories"
+	"github.com/google/uuid"
+)
+
+const (
+	passwordResetExpiryDuration = time.Minute * 15
+	passwordResetMaxAttempts    = 5
+	passwordResetOTPDigits      = 6
+	passwordResetLinkTokenBytes = 32
+	passwordResetEmailSubject   = "Reset your password"
+)
+
+func (service identityService) RequestPasswordReset(ctx context.Context, emailOrMobile string) (string, error) {
+	var user *userEntities.User
+	var err error
+	channel := constants.PASSWORD_RESET_CHANNEL_EMAIL
+	if service.isEmailValid(emailOrMobile) {
+		if service.passwordResetLinkURL == "" {
+			return "", errPasswordResetByEmailNotConfigured()
+		}
+		user, err = service.userService.FindByEmail(ctx, emailOrMobile)
+		if err == userRepositories.ErrUserNotFound {
+			return "", errUserNotFoundForEmail()
+		}
+	} else {
+		if err := service.validateMobile(emailOrMobile); err != nil {
+			return "", err
+		}
+		channel = constants.PASSWORD_RESET_CHANNEL_OTP
+		user, err = service.userService.FindByMobile(ctx, emailOrMobile)
+		if err == userRepositories.ErrUserNotFound {
+			return "", errUserNotFoundForMobile()
+		}
+	}
+	if err != nil {
+		return "", err
+	}
+	if user.IsSuspended() {
+		return "", errUserSuspended()
+	}
+
+	recipient := "email:" + user.Email
+	if channel == constants.PASSWORD_RESET_CHANNEL_OTP {
+		recipient = "mobile:" + user.Mobile
+	}
+	allowed, retryAt, err := service.allowSend(ctx, "password_reset", recipient)
+	if err != nil {
+		return "", err
+	}
+	if !allowed {
+		return "", errPasswordResetSendRateLimited(retryAt)
+	}
+
+	var secret string
+	var otp int
+	if channel == constants.PASSWORD_RESET_CHANNEL_OTP {
+		otp, err = service.generateOTP(passwordResetOTPDigits)
+		secret = strconv.Itoa(otp)
+	} else {
+		secret, err = service.generateRandomToken(passwordResetLinkTokenBytes)
+	}
+	if err != nil {
+		return "", errorx.NewSystemError(-1, err)
+	}
+
+	resetID := uuid.New().String()
+	err = service.repo.SavePasswordResetToken(ctx, entities.PasswordResetToken{
+		UserID:    user.ID,
+		ResetID:   resetID,
+		TokenHash: service.hashSecret(secret),
+		Channel:   channel,
+		Status:    constants.PASSWORD_RESET_TOKEN_STATUS_ACTIVE,
+		CreatedAt: time.Now(),
+		ExpiryAt:  time.Now().Add(passwordResetExpiryDuration),
+	})
+	if err != nil {
+		return "", errorx.NewSystemError(-1, err)
+	}
+
+	if channel == constants.PASSWORD_RESET_CHANNEL_OTP {
+		go service.sendOTP(user.Mobile, otp)
+	} else {
+		go service.sendPasswordResetLink(user.Email, resetID, secret)
+	}
+
+	return resetID, nil
+}
+
+func (service identityService) ConfirmPasswordReset(ctx context.Context, resetID string, secret string, newPassword string) error {
+	if err := service.passwordPolicy.validate(newPassword); err != nil {
+		return err
+	}
+
+	resetToken, err := service.repo.GetPasswordResetToken(ctx, resetID)
+	if err != nil && err != repositories.ErrPasswordResetTokenNotFound {
+		return errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrPasswordResetTokenNotFound || !resetToken.IsActive() {
+		return errInvalidPasswordResetToken()
+	}
+
+	if resetToken.HasExpired() {
+		resetToken.Status = constants.PASSWORD_RESET_TOKEN_STATUS_EXPIRED
+		if err := service.repo.SavePasswordResetToken(ctx, *resetToken); err != nil {
+			return errorx.NewSystemError(-1, err)
+		}
+
+		return errPasswordResetTokenHasExpired()
+	}
+
+	if subtle.ConstantTimeCompare([]byte(resetToken.TokenHash), []byte(service.hashSecret(secret))) != 1 {
+		if err := service.repo.RecordPasswordResetFailure(ctx, resetToken.ID, passwordResetMaxAttempts); err != nil {
+			return errorx.NewSystemError(-1, err)
+		}
+
+		return errInvalidPasswordResetToken()
+	}
+
+	if err := service.checkPasswordHistory(ctx, resetToken.UserID, newPassword); err != nil {
+		return err
+	}
+
+	// the token is consumed before the password is replaced, so that it can never be used twice, not even by
+	// concurrent requests.
+	used, err := service.repo.MarkPasswordResetTokenUsed(ctx, resetToken.ID)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+	if !used {
+		return errInvalidPasswordResetToken()
+	}
+
+	return service.replaceUserPassword(ctx, resetToken.UserID, newPassword)
+}
+
+// replaceUserPassword makes newPassword the active password of the user. The previously active password is kept
+// with the inactive status, and every session of the user is revoked.
+func (service identityService) replaceUserPassword(ctx context.Context, userID int64, newPassword string) error {
+	hashedPassword, err := service.passwordHasher.Hash(newPassword)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	activePassword, err := service.repo.GetActiveUserPassword(ctx, userID)
+	if err != nil && err != repositories.ErrUserPasswordNotFound {
+		return errorx.NewSystemError(-1, err)
+	}
+	if activePassword != nil {
+		activePassword.Status = constants.USER_PASSWORD_STATUS_INACTIVE
+		if err := service.repo.UpdateUserPassword(ctx, *activePassword); err != nil {
+			return errorx.NewSystemError(-1, err)
+		}
+	}
+
+	err = service.repo.SaveUserPassword(ctx, entities.UserPassword{
+		UserID:   userID,
+		Password: hashedPassword,
+		Status:   constants.USER_PASSWORD_STATUS_ACTIVE,
+	})
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	return service.tokenService.RevokeAll(ctx, userID)
+}
+
+func (service identityService) sendPasswordResetLink(email string, resetID string, secret string) {
+	ctx := context.TODO()
+	link, err := service.getPasswordResetLink(resetID, secret)
+	if err != nil {
+		logger.Log.Error(ctx, errorx.NewSystemError(-1, err))
+		return
+	}
+
+	body := fmt.Sprintf("Use the link below to reset your password. It expires in %d minutes.\n\n%s\n", int(passwordResetExpiryDuration.Minutes()), link)
+	if err := service.emailSender.Send(ctx, email, passwordResetEmailSubject, body); err != nil {
+		logger.Log.Error(ctx, err)
+	}
+}
+
+func (service identityService) getPasswordResetLink(resetID string, secret string) (string, error) {
+	link, err := url.Parse(service.passwordResetLinkURL)
+	if err != nil {
+		return "", err
+	}
+
+	query := link.Query()
+	query.Set("reset_id", resetID)
+	query.Set("token", secret)
+	link.RawQuery = query.Encode()
+
+	return link.String(), nil
+}
+
+// generateRandomToken returns size random bytes, url-safe base64 encoded.
+func (identityService) generateRandomToken(size int) (string, error) {
+	b := make([]byte, size)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// hashSecret hashes high-entropy secrets such as link tokens and recovery codes, which unlike passwords do not need
+// a slow hash.
+func (identityService) hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,88 @@
+package services
+
+import "github.com/golang-jwt/jwt"
+
+const (
+	tokenUseAccess    = "access"
+	tokenUseRefresh   = "refresh"
+	tokenUseMagicLink = "magic_link"
+	tokenUseService   = "service"
+)
+
+// tokenClaims are the claims of any token issued by the token service.
+type tokenClaims interface {
+	jwt.Claims
+	standardClaims() jwt.StandardClaims
+}
+
+// AccessTokenClaims are the claims of the jwts that are issued to users.
+type AccessTokenClaims struct {
+	jwt.StandardClaims
+	// TokenUse tells access tokens apart from refresh tokens, which are signed with the same keys.
+	TokenUse string          `json:"token_use"`
+	User     AccessTokenUser `json:"usr"`
+	// ClientID and Scope are set for tokens issued to oauth clients.
+	ClientID string `json:"client_id,omitempty"`
+	Scope    string `json:"scope,omitempty"`
+	// Roles and Permissions are those of the user when the token was issued, they are brought up to date when the
+	// token is refreshed.
+	Roles       []string `json:"roles,omitempty"`
+	Permissions []string `json:"permissions,omitempty"`
+}
+
+func (claims AccessTokenClaims) standardClaims() jwt.StandardClaims {
+	return claims.StandardClaims
+}
+
+// AccessTokenUser is the user that an access token has been issued to.
+type AccessTokenUser struct {
+	ID     int64  `json:"id"`
+	Email  string `json:"email"`
+	Mobile string `json:"mobile"`
+}
+
+// IDTokenClaims are the claims of OpenID Connect id tokens. The user claims are only set for the scopes that the
+// client has been granted.
+type IDTokenClaims struct {
+	jwt.StandardClaims
+	Nonce               string `json:"nonce,omitempty"`
+	Email               string `json:"email,omitempty"`
+	EmailVerified       bool   `json:"email_verified,omitempty"`
+	PhoneNumber         string `json:"phone_number,omitempty"`
+	PhoneNumberVerified bool   `json:"phone_number_verified,omitempty"`
+	Name                string `json:"name,omitempty"`
+	Gender              string `json:"gender,omitempty"`
+}
+
+// ServiceTokenClaims are the claims of the jwts that are issued to service clients with the client credentials
+// grant. The subject is the client id.
+type ServiceTokenClaims struct {
+	jwt.StandardClaims
+	TokenUse string `json:"token_use"`
+	ClientID string `json:"client_id"`
+	Scope    string `json:"scope,omitempty"`
+}
+
+func (claims ServiceTokenClaims) standardClaims() jwt.StandardClaims {
+	return claims.StandardClaims
+}
+
+// MagicLinkClaims are the claims of the tokens in the sign in links that are emailed to users.
+type MagicLinkClaims struct {
+	jwt.StandardClaims
+	TokenUse string `json:"token_use"`
+	Email    string `json:"email"`
+}
+
+func (claims MagicLinkClaims) standardClaims() jwt.StandardClaims {
+	return claims.StandardClaims
+}
+
+type refreshTokenClaims struct {
+	jwt.StandardClaims
+	TokenUse string `json:"token_use"`
+}
+
+func (claims refreshTokenClaims) standardClaims() jwt.StandardClaims {
+	return claims.StandardClaims
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	tokenTypeAccessToken  = "access_token"
+	tokenTypeRefreshToken = "refresh_token"
+)
+
+func (service tokenService) Introspect(ctx context.Context, token string, tokenTypeHint string) (*entities.TokenIntrospection, error) {
+	introspectors := []func(ctx context.Context, token string) (*entities.TokenIntrospection, error){
+		service.introspectAccessToken,
+		service.introspectRefreshToken,
+	}
+	// the hint only decides which kind of token is looked at first (RFC 7662 section 2.1).
+	if tokenTypeHint == tokenTypeRefreshToken {
+		introspectors[0], introspectors[1] = introspectors[1], introspectors[0]
+	}
+
+	for _, introspect := range introspectors {
+		introspection, err := introspect(ctx, token)
+		if err != nil {
+			return nil, err
+		}
+		if introspection.Active {
+			return introspection, nil
+		}
+	}
+
+	return &entities.TokenIntrospection{Active: false}, nil
+}
+
+func (service tokenService) introspectAccessToken(ctx context.Context, token string) (*entities.TokenIntrospection, error) {
+	claims, err := service.Validate(ctx, token)
+	var systemError errorx.SystemError
+	if errors.As(err, &systemError) {
+		return nil, err
+	}
+	if err != nil {
+		return &entities.TokenIntrospection{Active: false}, nil
+	}
+
+	username := claims.User.Email
+	if username == "" {
+		username = claims.User.Mobile
+	}
+	introspection := service.getIntrospection(claims.StandardClaims, tokenTypeAccessToken)
+	introspection.Username = username
+
+	return &introspection, nil
+}
+
+func (service tokenService) introspectRefreshToken(ctx context.Context, token string) (*entities.TokenIntrospection, error) {
+	claims := refreshTokenClaims{}
+	if err := service.parse(token, &claims); err != nil || claims.TokenUse != tokenUseRefresh {
+		return &entities.TokenIntrospection{Active: false}, nil
+	}
+
+	existingToken, err := service.repo.GetRefreshToken(ctx, token)
+	if err != nil && err != repositories.ErrRefreshTokenNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrRefreshTokenNotFound || !existingToken.IsActive() || existingToken.HasExpired() {
+		return &entities.TokenIntrospection{Active: false}, nil
+	}
+
+	introspection := service.getIntrospection(claims.StandardClaims, tokenTypeRefreshToken)
+
+	return &introspection, nil
+}
+
+func (service tokenService) getIntrospection(claims jwt.StandardClaims, tokenType string) entities.TokenIntrospection {
+	return entities.TokenIntrospection{
+		Active:    true,
+		TokenType: tokenType,
+		Subject:   claims.Subject,
+		Issuer:    claims.Issuer,
+		Audience:  claims.Audience,
+		TokenID:   claims.Id,
+		IssuedAt:  time.Unix(claims.IssuedAt, 0),
+		NotBefore: time.Unix(claims.NotBefore, 0),
+		ExpiryAt:  time.Unix(claims.ExpiresAt, 0),
+	}
+}
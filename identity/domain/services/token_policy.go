@@ -0,0 +1,59 @@
+package services
+
+import (
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	defaultTokenIssuer    = "consequent"
+	defaultTokenAudience  = "consequent"
+	defaultTokenClockSkew = time.Second * 30
+)
+
+// TokenPolicy decides which tokens are accepted besides their signature.
+type TokenPolicy struct {
+	// Issuer is set as the iss claim of issued tokens and is required in validated ones.
+	Issuer string
+	// Audience is set as the aud claim of issued tokens and is required in validated ones.
+	Audience string
+	// ClockSkew is the tolerance for differences between the clocks of the issuer and the validator
+	// when checking exp, nbf and iat.
+	ClockSkew time.Duration
+}
+
+// NewTokenPolicy returns the policy with defaults applied to the values that are not set.
+func NewTokenPolicy(issuer string, audience string, clockSkew time.Duration) TokenPolicy {
+	if issuer == "" {
+		issuer = defaultTokenIssuer
+	}
+	if audience == "" {
+		audience = defaultTokenAudience
+	}
+	if clockSkew == 0 {
+		clockSkew = defaultTokenClockSkew
+	}
+
+	return TokenPolicy{Issuer: issuer, Audience: audience, ClockSkew: clockSkew}
+}
+
+func (policy TokenPolicy) validate(claims jwt.StandardClaims, now time.Time) error {
+	if !claims.VerifyExpiresAt(now.Add(-policy.ClockSkew).Unix(), true) {
+		return errTokenExpired()
+	}
+	if !claims.VerifyNotBefore(now.Add(policy.ClockSkew).Unix(), false) {
+		return errTokenNotValidYet()
+	}
+	if !claims.VerifyIssuedAt(now.Add(policy.ClockSkew).Unix(), false) {
+		return errTokenNotValidYet()
+	}
+	if !claims.VerifyIssuer(policy.Issuer, true) {
+		return errInvalidTokenIssuer()
+	}
+	if !claims.VerifyAudience(policy.Audience, true) {
+		return errInvalidTokenAudience()
+	}
+
+	return nil
+}
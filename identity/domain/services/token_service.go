@@ -3,57 +3,319 @@ package services
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"time"
+	"unicode/utf8"
 
+	"github.com/devesh2997/consequent/contextx"
 	"github.com/devesh2997/consequent/errorx"
 	"github.com/devesh2997/consequent/identity/constants"
 	"github.com/devesh2997/consequent/identity/domain/entities"
 	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"github.com/devesh2997/consequent/keymanager"
 	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+	userRepositories "github.com/devesh2997/consequent/user/domain/repositories"
+	userServices "github.com/devesh2997/consequent/user/domain/services"
 	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
 )
 
 const (
 	jwtExpiryDuration          = time.Minute * 10
 	refreshTokenExpiryDuration = time.Hour * 24
+	idTokenExpiryDuration      = time.Minute * 10
+	magicLinkExpiryDuration    = time.Minute * 15
+	serviceTokenExpiryDuration = time.Minute * 10
+	// sizes of the session columns of refresh tokens
+	maxUserAgentLength  = 512
+	maxIPAddressLength  = 45
+	maxDeviceNameLength = 255
 )
 
 type TokenService interface {
 	Generate(ctx context.Context, user userEntities.User) (*entities.Token, error)
-	Validate(token string) (interface{}, error)
+	// GenerateForClient issues tokens to an oauth client acting on behalf of the user, limited to the given scope.
+	GenerateForClient(ctx context.Context, user userEntities.User, clientID string, scope string) (*entities.Token, error)
+	// Refresh exchanges an active refresh token for a new jwt and refresh token. The exchanged refresh token
+	// is marked as used, and presenting a used refresh token again revokes every token of its family.
+	Refresh(ctx context.Context, refreshToken string) (*entities.Token, error)
+	// RefreshForClient is Refresh for refresh tokens that were issued to the given oauth client.
+	RefreshForClient(ctx context.Context, refreshToken string, clientID string) (*entities.Token, error)
+	// Revoke revokes the session that the given refresh token belongs to, i.e. its whole token family.
+	Revoke(ctx context.Context, userID int64, refreshToken string) error
+	// RevokeAll revokes every session of the given user.
+	RevokeAll(ctx context.Context, userID int64) error
+	// GetSessions returns the sessions of the user, most recently used first. The session of the given access token
+	// jti is marked as the current one.
+	GetSessions(ctx context.Context, userID int64, currentTokenID string) ([]entities.Session, error)
+	// RevokeSession revokes the session of the user with the given id.
+	RevokeSession(ctx context.Context, userID int64, sessionID string) error
+	// SignIDToken completes the iss, iat, exp and jti claims of the id token and signs it. The subject, audience and
+	// user claims are set by the caller.
+	SignIDToken(ctx context.Context, claims IDTokenClaims) (string, error)
+	// SignMagicLinkToken returns a token for a sign in link emailed to the given address, along with its claims. The
+	// token does not keep track of its use, the caller has to make sure that it is only used once by its jti.
+	SignMagicLinkToken(ctx context.Context, email string) (string, *MagicLinkClaims, error)
+	// ValidateMagicLinkToken verifies the signature and the claims of a magic link token, and returns its claims.
+	ValidateMagicLinkToken(ctx context.Context, token string) (*MagicLinkClaims, error)
+	// Validate verifies the signature and the claims of an access token and that it has not been revoked, and
+	// returns its claims.
+	Validate(ctx context.Context, token string) (*AccessTokenClaims, error)
+	// SignServiceToken issues a jwt to a service client that acts on its own behalf, limited to the given scope.
+	SignServiceToken(ctx context.Context, clientID string, scope string) (*entities.JWT, error)
+	// ValidateServiceToken verifies the signature and the claims of a service token, and returns its claims.
+	ValidateServiceToken(ctx context.Context, token string) (*ServiceTokenClaims, error)
+	// IsServiceToken tells from its unverified claims whether the token claims to be a service token, so that it
+	// can be validated as one.
+	IsServiceToken(token string) bool
+	// Introspect tells whether an access or refresh token is active and what it was issued for (RFC 7662). Tokens
+	// that are invalid, expired or revoked are reported as not active rather than as an error.
+	Introspect(ctx context.Context, token string, tokenTypeHint string) (*entities.TokenIntrospection, error)
 }
 
-func NewTokenService(repo repositories.TokenRepo) TokenService {
-	return tokenService{repo: repo}
+func NewTokenService(repo repositories.TokenRepo, denylistRepo repositories.AccessTokenDenylistRepo, userService userServices.UserService, roleService userServices.RoleService, keyManager keymanager.KeyManager, policy TokenPolicy) TokenService {
+	return tokenService{repo: repo, denylistRepo: denylistRepo, userService: userService, roleService: roleService, keyManager: keyManager, policy: policy}
 }
 
 type tokenService struct {
-	repo repositories.TokenRepo
+	repo         repositories.TokenRepo
+	denylistRepo repositories.AccessTokenDenylistRepo
+	userService  userServices.UserService
+	roleService  userServices.RoleService
+	keyManager   keymanager.KeyManager
+	policy       TokenPolicy
+}
+
+// tokenGrant is who tokens are issued to besides the user. It is empty for our own clients.
+type tokenGrant struct {
+	clientID string
+	scope    string
+}
+
+// tokenSession is the refresh token family that tokens are issued in.
+type tokenSession struct {
+	familyID  string
+	createdAt time.Time
+	// deviceName is kept when a refresh request does not name the device.
+	deviceName string
+}
+
+func newTokenSession() tokenSession {
+	return tokenSession{familyID: uuid.New().String(), createdAt: time.Now()}
 }
 
 func (service tokenService) Generate(ctx context.Context, user userEntities.User) (*entities.Token, error) {
+	return service.generate(ctx, user, newTokenSession(), tokenGrant{})
+}
+
+func (service tokenService) GenerateForClient(ctx context.Context, user userEntities.User, clientID string, scope string) (*entities.Token, error) {
+	return service.generate(ctx, user, newTokenSession(), tokenGrant{clientID: clientID, scope: scope})
+}
+
+func (service tokenService) Refresh(ctx context.Context, refreshToken string) (*entities.Token, error) {
+	return service.refresh(ctx, refreshToken, "")
+}
+
+func (service tokenService) RefreshForClient(ctx context.Context, refreshToken string, clientID string) (*entities.Token, error) {
+	return service.refresh(ctx, refreshToken, clientID)
+}
+
+func (service tokenService) refresh(ctx context.Context, refreshToken string, clientID string) (*entities.Token, error) {
+	claims := refreshTokenClaims{}
+	if err := service.parse(refreshToken, &claims); err != nil {
+		return nil, errInvalidRefreshToken()
+	}
+	if claims.TokenUse != tokenUseRefresh {
+		return nil, errInvalidRefreshToken()
+	}
+
+	existingToken, err := service.repo.GetRefreshToken(ctx, refreshToken)
+	if err != nil && err != repositories.ErrRefreshTokenNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrRefreshTokenNotFound {
+		return nil, errInvalidRefreshToken()
+	}
+	// refresh tokens can only be exchanged by the client they were issued to.
+	if existingToken.ClientID != clientID {
+		return nil, errInvalidRefreshToken()
+	}
+
+	if existingToken.IsUsed() {
+		// a refresh token can only be exchanged once, so seeing it again means that it has leaked.
+		if err := service.revokeFamily(ctx, existingToken.FamilyID); err != nil {
+			return nil, err
+		}
+
+		return nil, errRefreshTokenReused()
+	}
+
+	if !existingToken.IsActive() || existingToken.HasExpired() {
+		return nil, errInvalidRefreshToken()
+	}
+
+	exchanged, err := service.repo.MarkRefreshTokenUsed(ctx, existingToken.ID)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if !exchanged {
+		return nil, errInvalidRefreshToken()
+	}
+
+	user, err := service.userService.FindByID(ctx, existingToken.UserID)
+	if err != nil && err != userRepositories.ErrUserNotFound {
+		return nil, err
+	}
+	if err == userRepositories.ErrUserNotFound {
+		return nil, errInvalidRefreshToken()
+	}
+	if user.IsSuspended() {
+		if err := service.revokeFamily(ctx, existingToken.FamilyID); err != nil {
+			return nil, err
+		}
+
+		return nil, errUserSuspended()
+	}
+
+	session := tokenSession{familyID: existingToken.FamilyID, createdAt: existingToken.SessionCreatedAt, deviceName: existingToken.DeviceName}
+	// tokens issued before refresh token families start a family of their own when they are exchanged.
+	if session.familyID == "" {
+		session.familyID = uuid.New().String()
+	}
+
+	return service.generate(ctx, *user, session, tokenGrant{clientID: existingToken.ClientID, scope: existingToken.Scope})
+}
+
+func (service tokenService) Revoke(ctx context.Context, userID int64, refreshToken string) error {
+	existingToken, err := service.repo.GetRefreshToken(ctx, refreshToken)
+	if err != nil && err != repositories.ErrRefreshTokenNotFound {
+		return errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrRefreshTokenNotFound || existingToken.UserID != userID {
+		return errInvalidRefreshToken()
+	}
+
+	return service.revokeFamily(ctx, existingToken.FamilyID)
+}
+
+func (service tokenService) RevokeAll(ctx context.Context, userID int64) error {
+	refreshTokens, err := service.repo.GetUserRefreshTokensWithUnexpiredAccessToken(ctx, userID)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+	if err := service.repo.RevokeUserRefreshTokens(ctx, userID); err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	return service.denyAccessTokens(ctx, refreshTokens)
+}
+
+func (service tokenService) GetSessions(ctx context.Context, userID int64, currentTokenID string) ([]entities.Session, error) {
+	refreshTokens, err := service.repo.GetUserActiveRefreshTokens(ctx, userID)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	sessions := make([]entities.Session, 0, len(refreshTokens))
+	for _, refreshToken := range refreshTokens {
+		sessions = append(sessions, entities.Session{
+			ID:         refreshToken.FamilyID,
+			ClientID:   refreshToken.ClientID,
+			DeviceName: refreshToken.DeviceName,
+			UserAgent:  refreshToken.UserAgent,
+			IPAddress:  refreshToken.IPAddress,
+			CreatedAt:  refreshToken.SessionCreatedAt,
+			LastUsedAt: refreshToken.CreatedAt,
+			ExpiryAt:   refreshToken.ExpiryAt,
+			IsCurrent:  currentTokenID != "" && refreshToken.AccessTokenID == currentTokenID,
+		})
+	}
+
+	return sessions, nil
+}
+
+func (service tokenService) RevokeSession(ctx context.Context, userID int64, sessionID string) error {
+	refreshTokens, err := service.repo.GetUserActiveRefreshTokens(ctx, userID)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	// sessions are only looked up among the user's own, so that no other user's session can be revoked.
+	for _, refreshToken := range refreshTokens {
+		if refreshToken.FamilyID == sessionID {
+			return service.revokeFamily(ctx, sessionID)
+		}
+	}
+
+	return errSessionNotFound()
+}
+
+// revokeFamily revokes the refresh tokens of the family and denylists the access tokens issued along with them.
+func (service tokenService) revokeFamily(ctx context.Context, familyID string) error {
+	refreshTokens, err := service.repo.GetFamilyRefreshTokensWithUnexpiredAccessToken(ctx, familyID)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+	if err := service.repo.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+
+	return service.denyAccessTokens(ctx, refreshTokens)
+}
+
+func (service tokenService) denyAccessTokens(ctx context.Context, refreshTokens []entities.RefreshToken) error {
+	for _, refreshToken := range refreshTokens {
+		if err := service.denylistRepo.Deny(ctx, refreshToken.AccessTokenID, refreshToken.AccessTokenExpiryAt); err != nil {
+			return errorx.NewSystemError(-1, err)
+		}
+	}
+
+	return nil
+}
+
+func (service tokenService) generate(ctx context.Context, user userEntities.User, session tokenSession, grant tokenGrant) (*entities.Token, error) {
 	now := time.Now().UTC()
 	jwtExpiryAt := now.Add(jwtExpiryDuration)
 	refreshTokenExpiryAt := now.Add(refreshTokenExpiryDuration)
+	if deviceName := contextx.GetDeviceName(ctx); deviceName != "" {
+		session.deviceName = deviceName
+	}
+
+	roles, err := service.roleService.GetUserRoles(ctx, user.ID)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
 
-	jwtClaims := service.getJWTClaims(user, jwtExpiryAt.Unix())
+	jwtClaims := service.getJWTClaims(user, roles, now, jwtExpiryAt)
+	jwtClaims.ClientID = grant.clientID
+	jwtClaims.Scope = grant.scope
 	jwtTokenStr, err := service.signClaims(jwtClaims)
 	if err != nil {
 		return nil, err
 	}
 
-	refreshTokenClaims := service.getRefreshTokenClaims(user.ID, refreshTokenExpiryAt.Unix())
+	refreshTokenClaims := service.getRefreshTokenClaims(user.ID, now, refreshTokenExpiryAt)
 	refreshTokenStr, err := service.signClaims(refreshTokenClaims)
 	if err != nil {
 		return nil, err
 	}
 
 	refreshToken := entities.RefreshToken{
-		Token:     refreshTokenStr,
-		Status:    constants.REFRESH_TOKEN_STATUS_ACTIVE,
-		CreatedAt: time.Now(),
-		ExpiryAt:  refreshTokenExpiryAt,
-		UpdatedAt: time.Now(),
+		UserID:              user.ID,
+		Token:               refreshTokenStr,
+		FamilyID:            session.familyID,
+		AccessTokenID:       jwtClaims.Id,
+		AccessTokenExpiryAt: jwtExpiryAt,
+		ClientID:            grant.clientID,
+		Scope:               grant.scope,
+		UserAgent:           truncate(contextx.GetUserAgent(ctx), maxUserAgentLength),
+		IPAddress:           truncate(contextx.GetClientIP(ctx), maxIPAddressLength),
+		DeviceName:          truncate(session.deviceName, maxDeviceNameLength),
+		SessionCreatedAt:    session.createdAt,
+		Status:              constants.REFRESH_TOKEN_STATUS_ACTIVE,
+		CreatedAt:           time.Now(),
+		ExpiryAt:            refreshTokenExpiryAt,
+		UpdatedAt:           time.Now(),
 	}
 
 	if err := service.repo.SaveRefreshToken(ctx, refreshToken); err != nil {
@@ -71,40 +333,59 @@ func (service tokenService) Generate(ctx context.Context, user userEntities.User
 	return &token, nil
 }
 
-func (service tokenService) getJWTClaims(user userEntities.User, exp int64) jwt.MapClaims {
-	claims := make(jwt.MapClaims)
-	claims["sub"] = user.ID                     // Subject of the token (i.e. the user)
-	claims["usr"] = service.getJWTPayload(user) // User data.
-	claims["exp"] = exp                         // The expiration time after which the token must be disregarded.
-	// claims["iat"] = time.Now()                  // The time at which the token was issued.
-	// claims["nbf"] = time.Now()                  // The time before which the token must be disregarded.
+func (service tokenService) SignIDToken(ctx context.Context, claims IDTokenClaims) (string, error) {
+	now := time.Now().UTC()
+	claims.Issuer = service.policy.Issuer
+	claims.IssuedAt = now.Unix()
+	claims.ExpiresAt = now.Add(idTokenExpiryDuration).Unix()
+	claims.Id = uuid.New().String()
 
-	return claims
+	return service.signClaims(claims)
 }
 
-func (service tokenService) getRefreshTokenClaims(userID int64, exp int64) jwt.MapClaims {
-	claims := make(jwt.MapClaims)
-	claims["sub"] = userID // Subject of the token (i.e. the user)
-	claims["exp"] = exp    // The expiration time after which the token must be disregarded.
-	// claims["iat"] = time.Now() // The time at which the token was issued.
-	// claims["nbf"] = time.Now() // The time before which the token must be disregarded.
+func (service tokenService) getJWTClaims(user userEntities.User, roles []userEntities.Role, issuedAt time.Time, expiryAt time.Time) AccessTokenClaims {
+	return AccessTokenClaims{
+		StandardClaims: service.getStandardClaims(user.ID, issuedAt, expiryAt),
+		TokenUse:       tokenUseAccess,
+		User:           service.getJWTPayload(user),
+		Roles:          userEntities.RoleNames(roles),
+		Permissions:    userEntities.RolePermissions(roles),
+	}
+}
 
-	return claims
+func (service tokenService) getRefreshTokenClaims(userID int64, issuedAt time.Time, expiryAt time.Time) refreshTokenClaims {
+	return refreshTokenClaims{
+		StandardClaims: service.getStandardClaims(userID, issuedAt, expiryAt),
+		TokenUse:       tokenUseRefresh,
+	}
 }
 
-func (service tokenService) signClaims(claims jwt.MapClaims) (string, error) {
-	privateKey, err := service.repo.GetPrivateKey()
-	if err != nil {
-		return "", errorx.NewSystemError(-1, err)
+func (service tokenService) getStandardClaims(userID int64, issuedAt time.Time, expiryAt time.Time) jwt.StandardClaims {
+	return jwt.StandardClaims{
+		Subject:   strconv.FormatInt(userID, 10), // Subject of the token (i.e. the user)
+		Issuer:    service.policy.Issuer,
+		Audience:  service.policy.Audience,
+		IssuedAt:  issuedAt.Unix(),     // The time at which the token was issued.
+		NotBefore: issuedAt.Unix(),     // The time before which the token must be disregarded.
+		ExpiresAt: expiryAt.Unix(),     // The expiration time after which the token must be disregarded.
+		Id:        uuid.New().String(), // Unique identifier of the token, so that no two tokens are the same.
 	}
-	key, err := jwt.ParseRSAPrivateKeyFromPEM(privateKey)
+}
+
+// signClaims signs the claims of a user token with the active asymmetric key.
+func (service tokenService) signClaims(claims jwt.Claims) (string, error) {
+	key, err := service.keyManager.SigningKey()
 	if err != nil {
-		return "", errorx.NewSystemError(-1, fmt.Errorf("create: parse key: %w", err))
+		return "", errorx.NewSystemError(-1, err)
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
-	token.Header["kid"] = "1" // TODO (devesh2997) | this should not be hardcoded. a separate key manager service is advised.
-	tokenStr, err := token.SignedString(key)
+	return service.signClaimsWithKey(key, claims)
+}
+
+func (service tokenService) signClaimsWithKey(key *keymanager.Key, claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), claims)
+	token.Header["kid"] = key.ID
+	tokenStr, err := token.SignedString(key.PrivateKey)
 	if err != nil {
 		return "nil", errorx.NewSystemError(-1, fmt.Errorf("create: sign token: %w", err))
 	}
@@ -112,39 +393,167 @@ func (service tokenService) signClaims(claims jwt.MapClaims) (string, error) {
 	return tokenStr, nil
 }
 
-func (service tokenService) getJWTPayload(user userEntities.User) map[string]interface{} {
-	return map[string]interface{}{
-		"id":     user.ID,
-		"email":  user.Email,
-		"mobile": user.Mobile,
+func (service tokenService) getJWTPayload(user userEntities.User) AccessTokenUser {
+	return AccessTokenUser{
+		ID:     user.ID,
+		Email:  user.Email,
+		Mobile: user.Mobile,
 	}
 }
 
-func (service tokenService) Validate(token string) (interface{}, error) {
-	publicKey, err := service.repo.GetPublicKey()
+func (service tokenService) SignMagicLinkToken(ctx context.Context, email string) (string, *MagicLinkClaims, error) {
+	now := time.Now().UTC()
+	claims := MagicLinkClaims{
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    service.policy.Issuer,
+			Audience:  service.policy.Audience,
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: now.Add(magicLinkExpiryDuration).Unix(),
+			Id:        uuid.New().String(),
+		},
+		TokenUse: tokenUseMagicLink,
+		Email:    email,
+	}
+
+	token, err := service.signClaims(claims)
 	if err != nil {
+		return "", nil, err
+	}
+
+	return token, &claims, nil
+}
+
+func (service tokenService) ValidateMagicLinkToken(ctx context.Context, token string) (*MagicLinkClaims, error) {
+	claims := MagicLinkClaims{}
+	if err := service.parse(token, &claims); err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != tokenUseMagicLink || claims.Email == "" || claims.Id == "" {
+		return nil, errInvalidToken()
+	}
+
+	return &claims, nil
+}
+
+func (service tokenService) Validate(ctx context.Context, token string) (*AccessTokenClaims, error) {
+	claims := AccessTokenClaims{}
+	if err := service.parse(token, &claims); err != nil {
 		return nil, err
 	}
-	key, err := jwt.ParseRSAPublicKeyFromPEM(publicKey)
+	if claims.TokenUse != tokenUseAccess || claims.User.ID == 0 {
+		return nil, errInvalidToken()
+	}
+
+	denied, err := service.denylistRepo.IsDenied(ctx, claims.Id)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if denied {
+		return nil, errTokenRevoked()
+	}
+
+	return &claims, nil
+}
+
+func (service tokenService) SignServiceToken(ctx context.Context, clientID string, scope string) (*entities.JWT, error) {
+	now := time.Now().UTC()
+	expiryAt := now.Add(serviceTokenExpiryDuration)
+	claims := ServiceTokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:   clientID,
+			Issuer:    service.policy.Issuer,
+			Audience:  service.policy.Audience,
+			IssuedAt:  now.Unix(),
+			NotBefore: now.Unix(),
+			ExpiresAt: expiryAt.Unix(),
+			Id:        uuid.New().String(),
+		},
+		TokenUse: tokenUseService,
+		ClientID: clientID,
+		Scope:    scope,
+	}
+
+	// service tokens are only verified by our own services, so they can be signed with a shared secret.
+	key, err := service.keyManager.ServiceSigningKey()
 	if err != nil {
-		return "", fmt.Errorf("validate: parse key: %w", err)
+		return nil, errorx.NewSystemError(-1, err)
 	}
+	token, err := service.signClaimsWithKey(key, claims)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.JWT{Token: token, ExpiryAt: expiryAt}, nil
+}
 
-	tok, err := jwt.Parse(token, func(jwtToken *jwt.Token) (interface{}, error) {
-		if _, ok := jwtToken.Method.(*jwt.SigningMethodRSA); !ok {
+func (service tokenService) ValidateServiceToken(ctx context.Context, token string) (*ServiceTokenClaims, error) {
+	claims := ServiceTokenClaims{}
+	if err := service.parseWithKeys(token, &claims, true); err != nil {
+		return nil, err
+	}
+	if claims.TokenUse != tokenUseService || claims.ClientID == "" || claims.Subject != claims.ClientID {
+		return nil, errInvalidToken()
+	}
+
+	return &claims, nil
+}
+
+func (tokenService) IsServiceToken(token string) bool {
+	claims := struct {
+		jwt.StandardClaims
+		TokenUse string `json:"token_use"`
+	}{}
+	if _, _, err := new(jwt.Parser).ParseUnverified(token, &claims); err != nil {
+		return false
+	}
+
+	return claims.TokenUse == tokenUseService
+}
+
+// parse verifies the signature of a user token and the claims shared by all tokens, and unmarshals its claims into
+// claims. User tokens signed with a symmetric key are refused, those keys only sign service tokens.
+func (service tokenService) parse(token string, claims tokenClaims) error {
+	return service.parseWithKeys(token, claims, false)
+}
+
+func (service tokenService) parseWithKeys(token string, claims tokenClaims, acceptSymmetricKeys bool) error {
+	// the claims are validated by the policy instead of the parser, which does not allow for clock skew.
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(token, claims, func(jwtToken *jwt.Token) (interface{}, error) {
+		kid, ok := jwtToken.Header["kid"].(string)
+		if !ok {
+			return nil, fmt.Errorf("kid not found")
+		}
+		key, err := service.keyManager.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		// the algorithm is pinned by the key, the alg header of the token is never trusted on its own.
+		if jwtToken.Method.Alg() != key.Algorithm {
 			return nil, fmt.Errorf("unexpected method: %s", jwtToken.Header["alg"])
 		}
+		if key.IsSymmetric() && !acceptSymmetricKeys {
+			return nil, fmt.Errorf("key %s only verifies service tokens", kid)
+		}
 
-		return key, nil
+		return key.PublicKey, nil
 	})
 	if err != nil {
-		return nil, fmt.Errorf("validate: %w", err)
+		return errInvalidToken()
 	}
 
-	claims, ok := tok.Claims.(jwt.MapClaims)
-	if !ok || !tok.Valid {
-		return nil, fmt.Errorf("validate: invalid")
+	return service.policy.validate(claims.standardClaims(), time.Now())
+}
+
+// truncate cuts s to at most max bytes, without splitting a utf-8 character.
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	for max > 0 && !utf8.RuneStart(s[max]) {
+		max--
 	}
 
-	return claims["dat"], nil
+	return s[:max]
 }
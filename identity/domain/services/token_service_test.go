@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/devesh2997/consequent/identity/data/repositories"
+	"github.com/devesh2997/consequent/keymanager"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+	"github.com/golang-jwt/jwt"
+)
+
+// newTestTokenService returns a token service with an active RS256 key "rsa" and an active HS256 key "hmac", along
+// with the pem of the public key and the hmac secret.
+func newTestTokenService(t *testing.T) (tokenService, []byte, []byte) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	publicKeyPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicKeyDER})
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	files := map[string][]byte{
+		"private.pem": pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)}),
+		"public.pem":  publicKeyPEM,
+		"secret":      secret,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	keyManager, err := keymanager.New([]keymanager.KeyConfig{
+		{ID: "rsa", State: keymanager.StateActive, PrivateKeyPath: filepath.Join(dir, "private.pem"), PublicKeyPath: filepath.Join(dir, "public.pem")},
+		{ID: "hmac", State: keymanager.StateActive, Algorithm: keymanager.AlgorithmHS256, SecretPath: filepath.Join(dir, "secret")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	service := NewTokenService(nil, repositories.NewMemoryAccessTokenDenylistRepo(), nil, nil, keyManager, NewTokenPolicy("", "", 0)).(tokenService)
+
+	return service, publicKeyPEM, secret
+}
+
+// signTestToken signs the claims with the method and key, under the kid.
+func signTestToken(t *testing.T, method jwt.SigningMethod, kid string, key interface{}, claims jwt.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signedToken, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signedToken
+}
+
+func TestValidateRejectsAlgorithmConfusion(t *testing.T) {
+	service, publicKeyPEM, secret := newTestTokenService(t)
+	now := time.Now()
+	claims := service.getJWTClaims(userEntities.User{ID: 1, Email: "user@example.com"}, nil, now, now.Add(time.Minute))
+
+	validToken, err := service.signClaims(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.Validate(context.Background(), validToken); err != nil {
+		t.Fatalf("Validate() of a token signed with the active key error = %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{
+			// the public key is known to everyone, a verifier that trusts the alg header would accept this token.
+			name:  "HS256 signed with the RS256 public key",
+			token: signTestToken(t, jwt.SigningMethodHS256, "rsa", publicKeyPEM, claims),
+		},
+		{
+			name:  "HS256 signed with the service token secret",
+			token: signTestToken(t, jwt.SigningMethodHS256, "hmac", secret, claims),
+		},
+		{
+			name:  "RS256 under the kid of the HS256 key",
+			token: signTestToken(t, jwt.SigningMethodRS256, "hmac", signingPrivateKey(t, service), claims),
+		},
+		{
+			name:  "unsigned",
+			token: signTestToken(t, jwt.SigningMethodNone, "rsa", jwt.UnsafeAllowNoneSignatureType, claims),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := service.Validate(context.Background(), tt.token); err == nil {
+				t.Error("Validate() succeeded, want an error")
+			}
+		})
+	}
+}
+
+func TestServiceTokensAreSignedWithTheHMACKey(t *testing.T) {
+	service, publicKeyPEM, _ := newTestTokenService(t)
+
+	serviceToken, err := service.SignServiceToken(context.Background(), "client", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := new(jwt.Parser).ParseUnverified(serviceToken.Token, &ServiceTokenClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.Header["kid"] != "hmac" || token.Method != jwt.SigningMethodHS256 {
+		t.Errorf("service token kid = %v, alg = %v, want hmac and HS256", token.Header["kid"], token.Method.Alg())
+	}
+	if _, err := service.ValidateServiceToken(context.Background(), serviceToken.Token); err != nil {
+		t.Errorf("ValidateServiceToken() error = %v", err)
+	}
+	if _, err := service.Validate(context.Background(), serviceToken.Token); err == nil {
+		t.Error("Validate() of a service token succeeded, want an error")
+	}
+
+	confusedToken := signTestToken(t, jwt.SigningMethodHS256, "rsa", publicKeyPEM, token.Claims)
+	if _, err := service.ValidateServiceToken(context.Background(), confusedToken); err == nil {
+		t.Error("ValidateServiceToken() of a token signed with the RS256 public key succeeded, want an error")
+	}
+}
+
+func signingPrivateKey(t *testing.T, service tokenService) interface{} {
+	t.Helper()
+
+	key, err := service.keyManager.SigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key.PrivateKey
+}
@@ -0,0 +1,287 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/identity/constants"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/devesh2997/consequent/identity/domain/repositories"
+	"github.com/devesh2997/consequent/totp"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+)
+
+const (
+	secondFactorChallengeExpiryDuration = time.Minute * 5
+	secondFactorChallengeMaxAttempts    = 5
+	secondFactorChallengeTokenBytes     = 32
+	recoveryCodeCount                   = 10
+	// recovery codes are 8 base32 characters, written as two groups of 4.
+	recoveryCodeBytes = 5
+	// the failed attempts of all the challenges of a user within secondFactorLockoutWindow after which the user
+	// cannot verify a second factor anymore, so that new challenges do not give an attacker more guesses.
+	secondFactorLockoutThreshold = 10
+	secondFactorLockoutWindow    = time.Minute * 30
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+func (service identityService) EnrollTOTP(ctx context.Context, userID int64) (*entities.TOTPEnrollment, error) {
+	user, err := service.userService.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	userTOTP, err := service.repo.GetUserTOTP(ctx, userID)
+	if err != nil && err != repositories.ErrUserTOTPNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrUserTOTPNotFound {
+		userTOTP = &entities.UserTOTP{UserID: userID, CreatedAt: time.Now()}
+	}
+	if userTOTP.IsActive() {
+		return nil, errTOTPAlreadyEnabled()
+	}
+
+	// enrolling again before confirming replaces the pending secret.
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	userTOTP.Secret = secret
+	userTOTP.Status = constants.USER_TOTP_STATUS_PENDING
+	userTOTP.LastUsedCounter = 0
+	if err := service.repo.SaveUserTOTP(ctx, *userTOTP); err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return &entities.TOTPEnrollment{
+		Secret: secret,
+		URI:    totp.URI(service.totpIssuer, totpAccountName(*user), secret),
+	}, nil
+}
+
+func (service identityService) ConfirmTOTP(ctx context.Context, userID int64, code string) ([]string, error) {
+	userTOTP, err := service.repo.GetUserTOTP(ctx, userID)
+	if err != nil && err != repositories.ErrUserTOTPNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrUserTOTPNotFound {
+		return nil, errTOTPNotEnrolled()
+	}
+	if userTOTP.IsActive() {
+		return nil, errTOTPAlreadyEnabled()
+	}
+
+	counter, ok := totp.Validate(userTOTP.Secret, code, time.Now())
+	if !ok {
+		return nil, errInvalidTOTPCode()
+	}
+
+	userTOTP.Status = constants.USER_TOTP_STATUS_ACTIVE
+	userTOTP.LastUsedCounter = counter
+	if err := service.repo.SaveUserTOTP(ctx, *userTOTP); err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return service.replaceRecoveryCodes(ctx, userID)
+}
+
+func (service identityService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	userTOTP, err := service.repo.GetUserTOTP(ctx, userID)
+	if err != nil && err != repositories.ErrUserTOTPNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrUserTOTPNotFound || !userTOTP.IsActive() {
+		return nil, errTOTPNotEnrolled()
+	}
+	if err := service.verifyTOTPCode(ctx, *userTOTP, code); err != nil {
+		return nil, err
+	}
+
+	return service.replaceRecoveryCodes(ctx, userID)
+}
+
+func (service identityService) VerifySecondFactor(ctx context.Context, challengeToken string, code string) (*entities.Token, error) {
+	challenge, err := service.repo.GetSecondFactorChallenge(ctx, service.hashSecret(challengeToken))
+	if err != nil && err != repositories.ErrSecondFactorChallengeNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrSecondFactorChallengeNotFound || !challenge.IsActive() || challenge.HasExpired() {
+		return nil, errInvalidSecondFactorChallenge()
+	}
+	if err := service.checkSecondFactorLockout(ctx, challenge.UserID); err != nil {
+		return nil, err
+	}
+
+	if err := service.verifySecondFactorCode(ctx, challenge.UserID, code); err != nil {
+		recorded, recordErr := service.repo.RecordSecondFactorChallengeFailure(ctx, challenge.ID, secondFactorChallengeMaxAttempts)
+		if recordErr != nil {
+			return nil, errorx.NewSystemError(-1, recordErr)
+		}
+		if !recorded {
+			return nil, errInvalidSecondFactorChallenge()
+		}
+
+		return nil, err
+	}
+
+	// the challenge is consumed before the tokens are issued, so that it can never be exchanged twice.
+	used, err := service.repo.MarkSecondFactorChallengeUsed(ctx, challenge.ID)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if !used {
+		return nil, errInvalidSecondFactorChallenge()
+	}
+
+	user, err := service.userService.FindByID(ctx, challenge.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user.IsSuspended() {
+		return nil, errUserSuspended()
+	}
+
+	return service.tokenService.Generate(ctx, *user)
+}
+
+// checkSecondFactorLockout refuses second factor verifications of a user who has failed too many recently.
+func (service identityService) checkSecondFactorLockout(ctx context.Context, userID int64) error {
+	since := time.Now().Add(-secondFactorLockoutWindow)
+	failures, err := service.repo.CountSecondFactorFailures(ctx, userID, since)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+	if failures >= secondFactorLockoutThreshold {
+		return errSecondFactorLockedOut()
+	}
+
+	return nil
+}
+
+// signInResult returns the tokens of the user, or a second factor challenge if the user has enabled two-factor
+// authentication.
+func (service identityService) signInResult(ctx context.Context, user userEntities.User) (*entities.SignInResult, error) {
+	userTOTP, err := service.repo.GetUserTOTP(ctx, user.ID)
+	if err != nil && err != repositories.ErrUserTOTPNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrUserTOTPNotFound || !userTOTP.IsActive() {
+		token, err := service.tokenService.Generate(ctx, user)
+		if err != nil {
+			return nil, err
+		}
+
+		return &entities.SignInResult{Token: token}, nil
+	}
+
+	challengeToken, err := service.generateRandomToken(secondFactorChallengeTokenBytes)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	challenge := entities.SecondFactorChallenge{
+		UserID:    user.ID,
+		TokenHash: service.hashSecret(challengeToken),
+		Status:    constants.SECOND_FACTOR_CHALLENGE_STATUS_ACTIVE,
+		CreatedAt: time.Now(),
+		ExpiryAt:  time.Now().Add(secondFactorChallengeExpiryDuration),
+	}
+	if err := service.repo.SaveSecondFactorChallenge(ctx, challenge); err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return &entities.SignInResult{ChallengeToken: challengeToken, ChallengeExpiryAt: challenge.ExpiryAt}, nil
+}
+
+// verifySecondFactorCode accepts either a totp code or an unused recovery code of the user.
+func (service identityService) verifySecondFactorCode(ctx context.Context, userID int64, code string) error {
+	code = strings.TrimSpace(code)
+	if _, err := strconv.Atoi(code); err == nil && len(code) == totp.Digits {
+		userTOTP, err := service.repo.GetUserTOTP(ctx, userID)
+		if err != nil && err != repositories.ErrUserTOTPNotFound {
+			return errorx.NewSystemError(-1, err)
+		}
+		if err == repositories.ErrUserTOTPNotFound || !userTOTP.IsActive() {
+			return errInvalidTOTPCode()
+		}
+
+		return service.verifyTOTPCode(ctx, *userTOTP, code)
+	}
+
+	used, err := service.repo.UseUserRecoveryCode(ctx, userID, service.hashSecret(normalizeRecoveryCode(code)))
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+	if !used {
+		return errInvalidRecoveryCode()
+	}
+
+	return nil
+}
+
+// verifyTOTPCode accepts a code only once, and never a code older than the last accepted one.
+func (service identityService) verifyTOTPCode(ctx context.Context, userTOTP entities.UserTOTP, code string) error {
+	counter, ok := totp.Validate(userTOTP.Secret, code, time.Now())
+	if !ok || counter <= userTOTP.LastUsedCounter {
+		return errInvalidTOTPCode()
+	}
+
+	marked, err := service.repo.MarkUserTOTPCounterUsed(ctx, userTOTP.ID, counter)
+	if err != nil {
+		return errorx.NewSystemError(-1, err)
+	}
+	if !marked {
+		return errInvalidTOTPCode()
+	}
+
+	return nil
+}
+
+// replaceRecoveryCodes generates new recovery codes for the user, which invalidates the previous ones. The codes are
+// stored hashed and only returned here.
+func (service identityService) replaceRecoveryCodes(ctx context.Context, userID int64) ([]string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	recoveryCodes := make([]entities.UserRecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, recoveryCodeBytes)
+		if _, err := rand.Read(b); err != nil {
+			return nil, errorx.NewSystemError(-1, err)
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes = append(codes, code[:4]+"-"+code[4:])
+		recoveryCodes = append(recoveryCodes, entities.UserRecoveryCode{
+			UserID:    userID,
+			CodeHash:  service.hashSecret(code),
+			Status:    constants.USER_RECOVERY_CODE_STATUS_ACTIVE,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		})
+	}
+
+	if err := service.repo.ReplaceUserRecoveryCodes(ctx, userID, recoveryCodes); err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func totpAccountName(user userEntities.User) string {
+	if user.Email != "" {
+		return user.Email
+	}
+	if user.Mobile != "" {
+		return user.Mobile
+	}
+
+	return strconv.FormatInt(user.ID, 10)
+}
@@ -0,0 +1,77 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/devesh2997/consequent/app/controller"
+	"github.com/devesh2997/consequent/contextx"
+	"github.com/devesh2997/consequent/identity/data/mappers"
+	"github.com/devesh2997/consequent/identity/domain/services"
+	"github.com/gin-gonic/gin"
+)
+
+type AccountController interface {
+	RequestDeletion(gCtx *gin.Context)
+	CancelDeletion(gCtx *gin.Context)
+	GetDeletionRequest(gCtx *gin.Context)
+	Export(gCtx *gin.Context)
+}
+
+func NewAccountController(service services.AccountService) AccountController {
+	return accountController{service: service}
+}
+
+type accountController struct {
+	controller.Controller
+	service services.AccountService
+}
+
+func (c accountController) RequestDeletion(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	request, err := c.service.RequestDeletion(gCtx.Request.Context(), requestUser.ID)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, mappers.NewAccountDeletionRequestMapper().ToModel(*request))
+}
+
+func (c accountController) CancelDeletion(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	if err := c.service.CancelDeletion(gCtx.Request.Context(), requestUser.ID); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func (c accountController) GetDeletionRequest(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	request, err := c.service.GetDeletionRequest(gCtx.Request.Context(), requestUser.ID)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, mappers.NewAccountDeletionRequestMapper().ToModel(*request))
+}
+
+// Export responds with the bare export instead of the usual response envelope, as a file to be downloaded.
+func (c accountController) Export(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	export, err := c.service.Export(gCtx.Request.Context(), requestUser.ID)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	gCtx.Header("Cache-Control", "no-store")
+	gCtx.Header("Content-Disposition", `attachment; filename="account-export.json"`)
+	gCtx.JSON(http.StatusOK, mappers.NewAccountExportMapper().ToModel(*export))
+}
@@ -0,0 +1,88 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/devesh2997/consequent/app/controller"
+	"github.com/devesh2997/consequent/contextx"
+	"github.com/devesh2997/consequent/identity/data/mappers"
+	"github.com/devesh2997/consequent/identity/data/models"
+	"github.com/devesh2997/consequent/identity/domain/services"
+	"github.com/gin-gonic/gin"
+)
+
+type APIKeyController interface {
+	CreateAPIKey(gCtx *gin.Context)
+	GetAPIKeys(gCtx *gin.Context)
+	RevokeAPIKey(gCtx *gin.Context)
+}
+
+func NewAPIKeyController(service services.APIKeyService) APIKeyController {
+	return apiKeyController{service: service}
+}
+
+type apiKeyController struct {
+	controller.Controller
+	service services.APIKeyService
+}
+
+func (c apiKeyController) CreateAPIKey(gCtx *gin.Context) {
+	input := struct {
+		Name  string `json:"name" form:"name"`
+		Scope string `json:"scope" form:"scope"`
+		// the key does not expire when it is not set
+		ExpiresInDays int `json:"expires_in_days" form:"expires_in_days"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	expiresIn := time.Duration(input.ExpiresInDays) * time.Hour * 24
+	apiKey, err := c.service.Create(gCtx.Request.Context(), requestUser.ID, input.Name, input.Scope, expiresIn)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, mappers.NewCreatedAPIKeyMapper().ToModel(*apiKey))
+}
+
+func (c apiKeyController) GetAPIKeys(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	apiKeys, err := c.service.List(gCtx.Request.Context(), requestUser.ID)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	apiKeyModels := make([]models.APIKey, 0, len(apiKeys))
+	for _, apiKey := range apiKeys {
+		apiKeyModels = append(apiKeyModels, mappers.NewAPIKeyMapper().ToModel(apiKey))
+	}
+
+	c.Send(gCtx, apiKeyModels)
+}
+
+func (c apiKeyController) RevokeAPIKey(gCtx *gin.Context) {
+	id, err := strconv.ParseInt(gCtx.Param("api_key_id"), 10, 64)
+	if err != nil {
+		c.SendBadRequestError(gCtx, errors.New("invalid api key id"))
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	if err := c.service.Revoke(gCtx.Request.Context(), requestUser.ID, id); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
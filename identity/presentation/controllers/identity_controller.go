@@ -2,13 +2,20 @@ package controllers
 
 import (
 	"errors"
+	"net/http"
+	"strconv"
 
 	"github.com/devesh2997/consequent/app/controller"
+	"github.com/devesh2997/consequent/contextx"
 	"github.com/devesh2997/consequent/identity/data/mappers"
+	"github.com/devesh2997/consequent/identity/data/models"
 	"github.com/devesh2997/consequent/identity/domain/services"
+	userMappers "github.com/devesh2997/consequent/user/data/mappers"
 	"github.com/gin-gonic/gin"
 )
 
+var errNonceRequired = errors.New("nonce is required")
+
 type IdentityController interface {
 	SendOTP(gCtx *gin.Context)
 	VerifyOTP(gCtx *gin.Context)
@@ -16,15 +23,44 @@ type IdentityController interface {
 	IsEmailRegistered(gCtx *gin.Context)
 	SignUpWithEmail(gCtx *gin.Context)
 	SignInWithEmailAndPassword(gCtx *gin.Context)
+	SignInWithIDToken(gCtx *gin.Context)
+	SendMagicLink(gCtx *gin.Context)
+	VerifyMagicLink(gCtx *gin.Context)
+	Refresh(gCtx *gin.Context)
+	Logout(gCtx *gin.Context)
+	LogoutAll(gCtx *gin.Context)
+	GetSessions(gCtx *gin.Context)
+	RevokeSession(gCtx *gin.Context)
+	Introspect(gCtx *gin.Context)
+	RequestPasswordReset(gCtx *gin.Context)
+	ConfirmPasswordReset(gCtx *gin.Context)
+	ChangePassword(gCtx *gin.Context)
+	SendEmailLinkCode(gCtx *gin.Context)
+	LinkEmail(gCtx *gin.Context)
+	LinkMobile(gCtx *gin.Context)
+	LinkExternalIdentity(gCtx *gin.Context)
+	VerifySecondFactor(gCtx *gin.Context)
+	EnrollTOTP(gCtx *gin.Context)
+	ConfirmTOTP(gCtx *gin.Context)
+	RegenerateRecoveryCodes(gCtx *gin.Context)
+	BeginPasskeyRegistration(gCtx *gin.Context)
+	FinishPasskeyRegistration(gCtx *gin.Context)
+	BeginPasskeyLogin(gCtx *gin.Context)
+	FinishPasskeyLogin(gCtx *gin.Context)
+	// GetUserSessions, RevokeUserSessions and SuspendUser are admin apis, which act on the user of the path.
+	GetUserSessions(gCtx *gin.Context)
+	RevokeUserSessions(gCtx *gin.Context)
+	SuspendUser(gCtx *gin.Context)
 }
 
-func NewIdentityController(service services.IdentityService) IdentityController {
-	return identityController{service: service}
+func NewIdentityController(service services.IdentityService, tokenService services.TokenService) IdentityController {
+	return identityController{service: service, tokenService: tokenService}
 }
 
 type identityController struct {
 	controller.Controller
-	service services.IdentityService
+	service      services.IdentityService
+	tokenService services.TokenService
 }
 
 func (c identityController) SendOTP(gCtx *gin.Context) {
@@ -75,13 +111,18 @@ func (c identityController) VerifyOTP(gCtx *gin.Context) {
 		return
 	}
 
-	token, err := c.service.VerifyOTP(gCtx.Request.Context(), input.VerificationID, input.MobileNumber, input.OTP)
+	result, err := c.service.VerifyOTP(gCtx.Request.Context(), input.VerificationID, input.MobileNumber, input.OTP)
 	if err != nil {
 		c.SendWithError(gCtx, err)
 		return
 	}
 
-	tokenModel := mappers.NewTokenMapper().ToModel(*token)
+	if result.IsSecondFactorRequired() {
+		c.Send(gCtx, mappers.NewSecondFactorChallengeMapper().ToResponseModel(*result))
+		return
+	}
+
+	tokenModel := mappers.NewTokenMapper().ToModel(*result.Token)
 
 	c.Send(gCtx, tokenModel)
 }
@@ -136,7 +177,144 @@ func (c identityController) SignInWithEmailAndPassword(gCtx *gin.Context) {
 		return
 	}
 
-	token, err := c.service.SignInWithEmailAndPassword(gCtx.Request.Context(), input.Email, input.Password)
+	result, err := c.service.SignInWithEmailAndPassword(gCtx.Request.Context(), input.Email, input.Password)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	if result.IsSecondFactorRequired() {
+		c.Send(gCtx, mappers.NewSecondFactorChallengeMapper().ToResponseModel(*result))
+		return
+	}
+
+	tokenModel := mappers.NewTokenMapper().ToModel(*result.Token)
+
+	c.Send(gCtx, tokenModel)
+}
+
+func (c identityController) VerifySecondFactor(gCtx *gin.Context) {
+	input := struct {
+		ChallengeToken string `json:"challenge_token" form:"challenge_token"`
+		Code           string `json:"code" form:"code"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	token, err := c.service.VerifySecondFactor(gCtx.Request.Context(), input.ChallengeToken, input.Code)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	tokenModel := mappers.NewTokenMapper().ToModel(*token)
+
+	c.Send(gCtx, tokenModel)
+}
+
+func (c identityController) SignInWithIDToken(gCtx *gin.Context) {
+	input := struct {
+		Provider string `json:"provider" form:"provider"`
+		IDToken  string `json:"id_token" form:"id_token"`
+		Nonce    string `json:"nonce" form:"nonce"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	if input.Nonce == "" {
+		c.SendBadRequestError(gCtx, errNonceRequired)
+		return
+	}
+
+	result, err := c.service.SignInWithIDToken(gCtx.Request.Context(), input.Provider, input.IDToken, input.Nonce)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	if result.IsSecondFactorRequired() {
+		c.Send(gCtx, mappers.NewSecondFactorChallengeMapper().ToResponseModel(*result))
+		return
+	}
+
+	tokenModel := mappers.NewTokenMapper().ToModel(*result.Token)
+
+	c.Send(gCtx, tokenModel)
+}
+
+func (c identityController) SendMagicLink(gCtx *gin.Context) {
+	input := struct {
+		Email string `json:"email" form:"email"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+	if input.Email == "" {
+		c.SendBadRequestError(gCtx, errors.New("email is required"))
+		return
+	}
+
+	if err := c.service.SendMagicLink(gCtx.Request.Context(), input.Email); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func (c identityController) VerifyMagicLink(gCtx *gin.Context) {
+	input := struct {
+		Token string `json:"token" form:"token"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+	if input.Token == "" {
+		c.SendBadRequestError(gCtx, errors.New("token is required"))
+		return
+	}
+
+	result, err := c.service.VerifyMagicLink(gCtx.Request.Context(), input.Token)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	if result.IsSecondFactorRequired() {
+		c.Send(gCtx, mappers.NewSecondFactorChallengeMapper().ToResponseModel(*result))
+		return
+	}
+
+	tokenModel := mappers.NewTokenMapper().ToModel(*result.Token)
+
+	c.Send(gCtx, tokenModel)
+}
+
+func (c identityController) Refresh(gCtx *gin.Context) {
+	input := struct {
+		RefreshToken string `json:"refresh_token" form:"refresh_token"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+	if input.RefreshToken == "" {
+		c.SendBadRequestError(gCtx, errors.New("refresh_token is required"))
+		return
+	}
+
+	token, err := c.tokenService.Refresh(gCtx.Request.Context(), input.RefreshToken)
 	if err != nil {
 		c.SendWithError(gCtx, err)
 		return
@@ -146,3 +324,377 @@ func (c identityController) SignInWithEmailAndPassword(gCtx *gin.Context) {
 
 	c.Send(gCtx, tokenModel)
 }
+
+func (c identityController) Logout(gCtx *gin.Context) {
+	input := struct {
+		RefreshToken string `json:"refresh_token" form:"refresh_token"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+	if input.RefreshToken == "" {
+		c.SendBadRequestError(gCtx, errors.New("refresh_token is required"))
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	if err := c.tokenService.Revoke(gCtx.Request.Context(), requestUser.ID, input.RefreshToken); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func (c identityController) LogoutAll(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	if err := c.tokenService.RevokeAll(gCtx.Request.Context(), requestUser.ID); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func (c identityController) GetSessions(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	sessions, err := c.tokenService.GetSessions(gCtx.Request.Context(), requestUser.ID, requestUser.TokenID)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	sessionModels := make([]models.Session, 0, len(sessions))
+	for _, session := range sessions {
+		sessionModels = append(sessionModels, mappers.NewSessionMapper().ToModel(session))
+	}
+
+	c.Send(gCtx, sessionModels)
+}
+
+func (c identityController) RevokeSession(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	if err := c.tokenService.RevokeSession(gCtx.Request.Context(), requestUser.ID, gCtx.Param("session_id")); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func (c identityController) GetUserSessions(gCtx *gin.Context) {
+	userID, err := strconv.ParseInt(gCtx.Param("user_id"), 10, 64)
+	if err != nil {
+		c.SendBadRequestError(gCtx, errors.New("invalid user id"))
+		return
+	}
+
+	sessions, err := c.tokenService.GetSessions(gCtx.Request.Context(), userID, "")
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	sessionModels := make([]models.Session, 0, len(sessions))
+	for _, session := range sessions {
+		sessionModels = append(sessionModels, mappers.NewSessionMapper().ToModel(session))
+	}
+
+	c.Send(gCtx, sessionModels)
+}
+
+func (c identityController) RevokeUserSessions(gCtx *gin.Context) {
+	userID, err := strconv.ParseInt(gCtx.Param("user_id"), 10, 64)
+	if err != nil {
+		c.SendBadRequestError(gCtx, errors.New("invalid user id"))
+		return
+	}
+
+	if err := c.tokenService.RevokeAll(gCtx.Request.Context(), userID); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func (c identityController) SuspendUser(gCtx *gin.Context) {
+	userID, err := strconv.ParseInt(gCtx.Param("user_id"), 10, 64)
+	if err != nil {
+		c.SendBadRequestError(gCtx, errors.New("invalid user id"))
+		return
+	}
+
+	if err := c.service.SuspendUser(gCtx.Request.Context(), userID); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+// Introspect responds with the bare RFC 7662 introspection response instead of the usual response envelope, as
+// introspection clients expect.
+func (c identityController) Introspect(gCtx *gin.Context) {
+	input := struct {
+		Token         string `json:"token" form:"token"`
+		TokenTypeHint string `json:"token_type_hint" form:"token_type_hint"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+	if input.Token == "" {
+		c.SendBadRequestError(gCtx, errors.New("token is required"))
+		return
+	}
+
+	introspection, err := c.tokenService.Introspect(gCtx.Request.Context(), input.Token, input.TokenTypeHint)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	gCtx.Header("Cache-Control", "no-store")
+	gCtx.JSON(http.StatusOK, mappers.NewTokenIntrospectionMapper().ToModel(*introspection))
+}
+
+func (c identityController) RequestPasswordReset(gCtx *gin.Context) {
+	input := struct {
+		Email        string `json:"email" form:"email"`
+		MobileNumber string `json:"mobile_number" form:"mobile_number"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	emailOrMobile := input.Email
+	if emailOrMobile == "" {
+		emailOrMobile = input.MobileNumber
+	}
+	if emailOrMobile == "" {
+		c.SendBadRequestError(gCtx, errors.New("email or mobile_number is required"))
+		return
+	}
+
+	resetID, err := c.service.RequestPasswordReset(gCtx.Request.Context(), emailOrMobile)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, gin.H{
+		"reset_id": resetID,
+	})
+}
+
+func (c identityController) ConfirmPasswordReset(gCtx *gin.Context) {
+	input := struct {
+		ResetID     string `json:"reset_id" form:"reset_id"`
+		Token       string `json:"token" form:"token"`
+		NewPassword string `json:"new_password" form:"new_password"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+	if input.ResetID == "" || input.Token == "" {
+		c.SendBadRequestError(gCtx, errors.New("reset_id and token are required"))
+		return
+	}
+
+	if err := c.service.ConfirmPasswordReset(gCtx.Request.Context(), input.ResetID, input.Token, input.NewPassword); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func (c identityController) ChangePassword(gCtx *gin.Context) {
+	input := struct {
+		CurrentPassword string `json:"current_password" form:"current_password"`
+		NewPassword     string `json:"new_password" form:"new_password"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	token, err := c.service.ChangePassword(gCtx.Request.Context(), requestUser.ID, input.CurrentPassword, input.NewPassword)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	tokenModel := mappers.NewTokenMapper().ToModel(*token)
+
+	c.Send(gCtx, tokenModel)
+}
+
+func (c identityController) SendEmailLinkCode(gCtx *gin.Context) {
+	input := struct {
+		Email string `json:"email" form:"email"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	verificationID, err := c.service.SendEmailLinkCode(gCtx.Request.Context(), requestUser.ID, input.Email)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, gin.H{
+		"verification_id": verificationID,
+	})
+}
+
+func (c identityController) LinkEmail(gCtx *gin.Context) {
+	input := struct {
+		VerificationID string `json:"verification_id" form:"verification_id"`
+		Code           string `json:"code" form:"code"`
+		// an access token of the user that the email belongs to, if any, to merge that user into the signed in user
+		MergeToken string `json:"merge_token" form:"merge_token"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	user, err := c.service.LinkEmail(gCtx.Request.Context(), requestUser.ID, input.VerificationID, input.Code, input.MergeToken)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, userMappers.NewUserMapper().ToModel(*user))
+}
+
+func (c identityController) LinkMobile(gCtx *gin.Context) {
+	input := struct {
+		VerificationID string `json:"verification_id" form:"verification_id"`
+		MobileNumber   string `json:"mobile_number" form:"mobile_number"`
+		OTP            int    `json:"otp" form:"otp"`
+		// an access token of the user that the mobile number belongs to, if any, to merge that user into the signed
+		// in user
+		MergeToken string `json:"merge_token" form:"merge_token"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	user, err := c.service.LinkMobile(gCtx.Request.Context(), requestUser.ID, input.VerificationID, input.MobileNumber, input.OTP, input.MergeToken)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, userMappers.NewUserMapper().ToModel(*user))
+}
+
+func (c identityController) LinkExternalIdentity(gCtx *gin.Context) {
+	input := struct {
+		Provider string `json:"provider" form:"provider"`
+		IDToken  string `json:"id_token" form:"id_token"`
+		Nonce    string `json:"nonce" form:"nonce"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+	if input.Nonce == "" {
+		c.SendBadRequestError(gCtx, errNonceRequired)
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	err := c.service.LinkExternalIdentity(gCtx.Request.Context(), requestUser.ID, input.Provider, input.IDToken, input.Nonce)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func (c identityController) EnrollTOTP(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	enrollment, err := c.service.EnrollTOTP(gCtx.Request.Context(), requestUser.ID)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, mappers.NewTOTPEnrollmentMapper().ToModel(*enrollment))
+}
+
+func (c identityController) ConfirmTOTP(gCtx *gin.Context) {
+	input := struct {
+		Code string `json:"code" form:"code"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	recoveryCodes, err := c.service.ConfirmTOTP(gCtx.Request.Context(), requestUser.ID, input.Code)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, models.RecoveryCodes{RecoveryCodes: recoveryCodes})
+}
+
+func (c identityController) RegenerateRecoveryCodes(gCtx *gin.Context) {
+	input := struct {
+		Code string `json:"code" form:"code"`
+	}{}
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	recoveryCodes, err := c.service.RegenerateRecoveryCodes(gCtx.Request.Context(), requestUser.ID, input.Code)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, models.RecoveryCodes{RecoveryCodes: recoveryCodes})
+}
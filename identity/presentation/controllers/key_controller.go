@@ -0,0 +1,32 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/devesh2997/consequent/app/controller"
+	"github.com/devesh2997/consequent/keymanager"
+	"github.com/gin-gonic/gin"
+)
+
+// jwksMaxAge is how long clients may cache the jwks. It must be well below the time between adding a new key and
+// making it active, so that every verifier knows the key before the first token is signed with it.
+const jwksMaxAge = "max-age=300"
+
+type KeyController interface {
+	JWKS(gCtx *gin.Context)
+}
+
+func NewKeyController(keyManager keymanager.KeyManager) KeyController {
+	return keyController{keyManager: keyManager}
+}
+
+type keyController struct {
+	controller.Controller
+	keyManager keymanager.KeyManager
+}
+
+// JWKS responds with the bare key set instead of the usual response envelope, as jwks clients expect.
+func (c keyController) JWKS(gCtx *gin.Context) {
+	gCtx.Header("Cache-Control", "public, "+jwksMaxAge)
+	gCtx.JSON(http.StatusOK, c.keyManager.JWKS())
+}
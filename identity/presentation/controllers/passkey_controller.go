@@ -0,0 +1,125 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/devesh2997/consequent/contextx"
+	"github.com/devesh2997/consequent/identity/data/mappers"
+	"github.com/devesh2997/consequent/identity/domain/entities"
+	"github.com/gin-gonic/gin"
+)
+
+func (c identityController) BeginPasskeyRegistration(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	options, err := c.service.BeginPasskeyRegistration(gCtx.Request.Context(), requestUser.ID)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, mappers.NewPasskeyOptionsMapper().ToRegistrationModel(*options))
+}
+
+// FinishPasskeyRegistration takes the binary fields of the authenticator response base64url encoded.
+func (c identityController) FinishPasskeyRegistration(gCtx *gin.Context) {
+	input := struct {
+		ChallengeID       string `json:"challenge_id"`
+		ClientDataJSON    string `json:"client_data_json"`
+		AttestationObject string `json:"attestation_object"`
+	}{}
+
+	if err := gCtx.ShouldBindJSON(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	registration := entities.PasskeyRegistration{}
+	err := decodeBase64URLFields(map[string]decodeTarget{
+		"client_data_json":   {input.ClientDataJSON, &registration.ClientDataJSON},
+		"attestation_object": {input.AttestationObject, &registration.AttestationObject},
+	})
+	if err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	if err := c.service.FinishPasskeyRegistration(gCtx.Request.Context(), requestUser.ID, input.ChallengeID, registration); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func (c identityController) BeginPasskeyLogin(gCtx *gin.Context) {
+	options, err := c.service.BeginPasskeyLogin(gCtx.Request.Context())
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, mappers.NewPasskeyOptionsMapper().ToLoginModel(*options))
+}
+
+// FinishPasskeyLogin takes the binary fields of the authenticator response base64url encoded.
+func (c identityController) FinishPasskeyLogin(gCtx *gin.Context) {
+	input := struct {
+		ChallengeID       string `json:"challenge_id"`
+		CredentialID      string `json:"credential_id"`
+		ClientDataJSON    string `json:"client_data_json"`
+		AuthenticatorData string `json:"authenticator_data"`
+		Signature         string `json:"signature"`
+		UserHandle        string `json:"user_handle"`
+	}{}
+
+	if err := gCtx.ShouldBindJSON(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	assertion := entities.PasskeyAssertion{}
+	err := decodeBase64URLFields(map[string]decodeTarget{
+		"credential_id":      {input.CredentialID, &assertion.CredentialID},
+		"client_data_json":   {input.ClientDataJSON, &assertion.ClientDataJSON},
+		"authenticator_data": {input.AuthenticatorData, &assertion.AuthenticatorData},
+		"signature":          {input.Signature, &assertion.Signature},
+		"user_handle":        {input.UserHandle, &assertion.UserHandle},
+	})
+	if err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	token, err := c.service.FinishPasskeyLogin(gCtx.Request.Context(), input.ChallengeID, assertion)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	tokenModel := mappers.NewTokenMapper().ToModel(*token)
+
+	c.Send(gCtx, tokenModel)
+}
+
+type decodeTarget struct {
+	value  string
+	target *[]byte
+}
+
+// decodeBase64URLFields decodes base64url values, with or without padding, into their targets.
+func decodeBase64URLFields(fields map[string]decodeTarget) error {
+	for name, field := range fields {
+		b, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(field.value, "="))
+		if err != nil {
+			return fmt.Errorf("%s must be base64url encoded", name)
+		}
+		*field.target = b
+	}
+
+	return nil
+}
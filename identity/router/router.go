@@ -1,7 +1,10 @@
 package router
 
 import (
+	"github.com/devesh2997/consequent/app/middleware"
+	"github.com/devesh2997/consequent/identity/constants"
 	"github.com/devesh2997/consequent/identity/containers"
+	userConstants "github.com/devesh2997/consequent/user/constants"
 	"github.com/gin-gonic/gin"
 )
 
@@ -9,7 +12,18 @@ func InjectIdentityRoutes(router *gin.RouterGroup) {
 	setupV1Routes(router)
 }
 
+// InjectWellKnownRoutes adds the identity documents that are served from /.well-known.
+func InjectWellKnownRoutes(router *gin.RouterGroup) {
+	keyController := containers.InjectKeyController()
+
+	router.GET("/jwks.json", func(c *gin.Context) {
+		keyController.JWKS(c)
+	})
+}
+
 func setupV1Routes(r *gin.RouterGroup) {
+	tokenService := containers.InjectTokenService()
+	apiKeyService := containers.InjectAPIKeyService()
 	identiyController := containers.InjectIdentityController()
 
 	v1 := r.Group("/v1")
@@ -31,4 +45,69 @@ func setupV1Routes(r *gin.RouterGroup) {
 	v1.POST("/sign-in-with-email", func(c *gin.Context) {
 		identiyController.SignInWithEmailAndPassword(c)
 	})
+	v1.POST("/sign-in-with-id-token", func(c *gin.Context) {
+		identiyController.SignInWithIDToken(c)
+	})
+	v1.POST("/send-magic-link", func(c *gin.Context) {
+		identiyController.SendMagicLink(c)
+	})
+	v1.POST("/verify-magic-link", func(c *gin.Context) {
+		identiyController.VerifyMagicLink(c)
+	})
+	v1.POST("/verify-second-factor", func(c *gin.Context) {
+		identiyController.VerifySecondFactor(c)
+	})
+	v1.POST("/passkeys/login/begin", func(c *gin.Context) {
+		identiyController.BeginPasskeyLogin(c)
+	})
+	v1.POST("/passkeys/login/finish", func(c *gin.Context) {
+		identiyController.FinishPasskeyLogin(c)
+	})
+	v1.POST("/refresh", func(c *gin.Context) {
+		identiyController.Refresh(c)
+	})
+	v1.POST("/request-password-reset", func(c *gin.Context) {
+		identiyController.RequestPasswordReset(c)
+	})
+	v1.POST("/confirm-password-reset", func(c *gin.Context) {
+		identiyController.ConfirmPasswordReset(c)
+	})
+
+	authorised := v1.Group("")
+	authorised.Use(middleware.Authorisation(tokenService, apiKeyService), middleware.RequireUser(), middleware.RequireAccessToken())
+	authorised.POST("/logout", func(c *gin.Context) {
+		identiyController.Logout(c)
+	})
+	authorised.POST("/logout-all", func(c *gin.Context) {
+		identiyController.LogoutAll(c)
+	})
+	authorised.POST("/passkeys/register/begin", func(c *gin.Context) {
+		identiyController.BeginPasskeyRegistration(c)
+	})
+	authorised.POST("/passkeys/register/finish", func(c *gin.Context) {
+		identiyController.FinishPasskeyRegistration(c)
+	})
+
+	services := v1.Group("")
+	services.Use(middleware.Authorisation(tokenService, apiKeyService), middleware.RequireService())
+	introspectors := services.Group("", middleware.RequirePermission(constants.SCOPE_TOKENS_INTROSPECT))
+	introspectors.POST("/introspect", func(c *gin.Context) {
+		identiyController.Introspect(c)
+	})
+
+	admin := v1.Group("/admin")
+	admin.Use(middleware.Authorisation(tokenService, apiKeyService), middleware.RequireAccessToken())
+
+	userReaders := admin.Group("", middleware.RequirePermission(userConstants.PERMISSION_USERS_READ))
+	userReaders.GET("/users/:user_id/sessions", func(c *gin.Context) {
+		identiyController.GetUserSessions(c)
+	})
+
+	userWriters := admin.Group("", middleware.RequirePermission(userConstants.PERMISSION_USERS_WRITE))
+	userWriters.POST("/users/:user_id/logout-all", func(c *gin.Context) {
+		identiyController.RevokeUserSessions(c)
+	})
+	userWriters.POST("/users/:user_id/suspend", func(c *gin.Context) {
+		identiyController.SuspendUser(c)
+	})
 }
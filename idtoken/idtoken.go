@@ -0,0 +1,209 @@
+// Package idtoken verifies id tokens issued by external OpenID Connect providers, such as Google and Apple, against the
+// public keys that the providers publish at their jwks url.
+package idtoken
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	ProviderGoogle = "google"
+	ProviderApple  = "apple"
+
+	// clockSkew is the tolerance for clock differences with the provider when checking exp, nbf and iat.
+	clockSkew = time.Minute
+)
+
+var (
+	ErrUnknownProvider = errors.New("unknown identity provider")
+	ErrInvalidToken    = errors.New("invalid id token")
+)
+
+// wellKnownProviders are the issuers and jwks urls of the providers that only need their client ids configured.
+var wellKnownProviders = map[string]Provider{
+	ProviderGoogle: {
+		Issuers: []string{"https://accounts.google.com", "accounts.google.com"},
+		JWKSURL: "https://www.googleapis.com/oauth2/v3/certs",
+	},
+	ProviderApple: {
+		Issuers: []string{"https://appleid.apple.com"},
+		JWKSURL: "https://appleid.apple.com/auth/keys",
+	},
+}
+
+// Provider is an identity provider whose id tokens are accepted. Issuers and JWKSURL default to the ones of Google and
+// Apple for the providers named google and apple.
+type Provider struct {
+	Name string
+	// accepted iss claims
+	Issuers []string
+	// accepted aud claims, the client ids of our apps registered with the provider
+	ClientIDs []string
+	JWKSURL   string
+}
+
+// Claims are the claims of a verified id token that users are signed in with.
+type Claims struct {
+	jwt.StandardClaims
+	Nonce string `json:"nonce,omitempty"`
+	Email string `json:"email,omitempty"`
+	// Apple sends email_verified as a string
+	EmailVerified flexibleBool `json:"email_verified,omitempty"`
+}
+
+// IsEmailVerified reports whether the provider has verified that the user owns the email.
+func (claims Claims) IsEmailVerified() bool {
+	return claims.Email != "" && bool(claims.EmailVerified)
+}
+
+type Verifier interface {
+	// Verify checks the signature of an id token issued by the named provider and its iss, aud, exp, iat and nbf
+	// claims, and returns its claims. The nonce is required and must match the nonce claim. Tokens that fail
+	// verification are reported with an error wrapping ErrInvalidToken.
+	Verify(ctx context.Context, provider string, token string, nonce string) (*Claims, error)
+}
+
+// NewVerifier returns a verifier of the id tokens of the given providers. The jwks of every provider is fetched with
+// the http client when a token is first verified and cached afterwards.
+func NewVerifier(providers []Provider, httpClient *http.Client) (Verifier, error) {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: time.Second * 10}
+	}
+
+	verifier := verifier{providers: map[string]provider{}}
+	for _, p := range providers {
+		if wellKnown, ok := wellKnownProviders[p.Name]; ok {
+			if len(p.Issuers) == 0 {
+				p.Issuers = wellKnown.Issuers
+			}
+			if p.JWKSURL == "" {
+				p.JWKSURL = wellKnown.JWKSURL
+			}
+		}
+		if p.Name == "" || len(p.Issuers) == 0 || len(p.ClientIDs) == 0 || p.JWKSURL == "" {
+			return nil, fmt.Errorf("idtoken: provider %s needs issuers, client ids and a jwks url", p.Name)
+		}
+
+		verifier.providers[p.Name] = provider{Provider: p, keys: newKeySet(p.JWKSURL, httpClient)}
+	}
+
+	return verifier, nil
+}
+
+type provider struct {
+	Provider
+	keys *keySet
+}
+
+type verifier struct {
+	providers map[string]provider
+}
+
+func (v verifier) Verify(ctx context.Context, providerName string, token string, nonce string) (*Claims, error) {
+	p, ok := v.providers[providerName]
+	if !ok {
+		return nil, ErrUnknownProvider
+	}
+
+	claims := &Claims{}
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	_, err := parser.ParseWithClaims(token, claims, func(t *jwt.Token) (interface{}, error) {
+		kid, _ := t.Header["kid"].(string)
+		key, err := p.keys.get(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		// the algorithm of the key decides how the token is verified, the alg header only has to agree with it.
+		if t.Method.Alg() != key.algorithm {
+			return nil, fmt.Errorf("unexpected signing method %s", t.Method.Alg())
+		}
+
+		return key.publicKey, nil
+	})
+	if err != nil {
+		var validationError *jwt.ValidationError
+		if errors.As(err, &validationError) && validationError.Inner != nil {
+			err = validationError.Inner
+		}
+		var fetchError jwksFetchError
+		if errors.As(err, &fetchError) {
+			return nil, err
+		}
+
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if err := p.validate(*claims, nonce, time.Now()); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	return claims, nil
+}
+
+func (p provider) validate(claims Claims, nonce string, now time.Time) error {
+	if !contains(p.Issuers, claims.Issuer) {
+		return errors.New("unexpected issuer")
+	}
+	if !contains(p.ClientIDs, claims.Audience) {
+		return errors.New("unexpected audience")
+	}
+	if claims.Subject == "" {
+		return errors.New("subject not found")
+	}
+	if claims.ExpiresAt == 0 || now.Add(-clockSkew).Unix() > claims.ExpiresAt {
+		return errors.New("token has expired")
+	}
+	if claims.NotBefore != 0 && now.Add(clockSkew).Unix() < claims.NotBefore {
+		return errors.New("token is not valid yet")
+	}
+	if now.Add(clockSkew).Unix() < claims.IssuedAt {
+		return errors.New("token is issued in the future")
+	}
+	// the nonce binds the token to the sign in that the client started, without it a leaked token could be replayed.
+	if nonce == "" {
+		return errors.New("nonce is required")
+	}
+	if claims.Nonce != nonce {
+		return errors.New("unexpected nonce")
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+type flexibleBool bool
+
+func (b *flexibleBool) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+
+	switch v := value.(type) {
+	case bool:
+		*b = flexibleBool(v)
+	case string:
+		*b = flexibleBool(strings.EqualFold(v, "true"))
+	default:
+		*b = false
+	}
+
+	return nil
+}
@@ -0,0 +1,320 @@
+package idtoken
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	testProvider = "test"
+	testIssuer   = "https://issuer.example.com"
+	testClientID = "client-id"
+	testNonce    = "nonce"
+)
+
+// testKeys are the keys of a provider served from a test jwks url.
+type testKeys struct {
+	rsaKey *rsa.PrivateKey
+	ecKey  *ecdsa.PrivateKey
+	// number of times the jwks has been fetched
+	fetches int32
+}
+
+func newTestKeys(tb testing.TB) *testKeys {
+	tb.Helper()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return &testKeys{rsaKey: rsaKey, ecKey: ecKey}
+}
+
+func (keys *testKeys) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	atomic.AddInt32(&keys.fetches, 1)
+
+	encode := func(i *big.Int) string {
+		return base64.RawURLEncoding.EncodeToString(i.Bytes())
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string][]jwk{
+		"keys": {
+			{
+				KeyType:   "RSA",
+				Use:       "sig",
+				Algorithm: "RS256",
+				KeyID:     "rsa",
+				N:         encode(keys.rsaKey.N),
+				E:         encode(big.NewInt(int64(keys.rsaKey.E))),
+			},
+			{
+				KeyType:   "EC",
+				Use:       "sig",
+				Algorithm: "ES256",
+				KeyID:     "ec",
+				Curve:     "P-256",
+				X:         encode(keys.ecKey.X),
+				Y:         encode(keys.ecKey.Y),
+			},
+		},
+	})
+}
+
+// newTestVerifier returns a verifier of the test provider whose jwks is served by a test server with the handler.
+func newTestVerifier(tb testing.TB, jwks http.Handler) Verifier {
+	tb.Helper()
+
+	server := httptest.NewServer(jwks)
+	tb.Cleanup(server.Close)
+
+	verifier, err := NewVerifier([]Provider{{
+		Name:      testProvider,
+		Issuers:   []string{testIssuer},
+		ClientIDs: []string{testClientID},
+		JWKSURL:   server.URL,
+	}}, server.Client())
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return verifier
+}
+
+func validClaims() Claims {
+	now := time.Now()
+
+	return Claims{
+		StandardClaims: jwt.StandardClaims{
+			Issuer:    testIssuer,
+			Audience:  testClientID,
+			Subject:   "subject",
+			IssuedAt:  now.Unix(),
+			ExpiresAt: now.Add(time.Hour).Unix(),
+		},
+		Nonce:         testNonce,
+		Email:         "user@example.com",
+		EmailVerified: true,
+	}
+}
+
+func sign(tb testing.TB, method jwt.SigningMethod, kid string, claims Claims, key interface{}) string {
+	tb.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	signed, err := token.SignedString(key)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	return signed
+}
+
+func TestVerify(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := newTestVerifier(t, keys)
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "rs256", token: sign(t, jwt.SigningMethodRS256, "rsa", validClaims(), keys.rsaKey)},
+		{name: "es256", token: sign(t, jwt.SigningMethodES256, "ec", validClaims(), keys.ecKey)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.Verify(context.Background(), testProvider, tt.token, testNonce)
+			if err != nil {
+				t.Fatalf("Verify() error = %v", err)
+			}
+			if claims.Subject != "subject" || !claims.IsEmailVerified() {
+				t.Errorf("Verify() claims = %+v", claims)
+			}
+		})
+	}
+
+	// the jwks is cached after the first token.
+	if fetches := atomic.LoadInt32(&keys.fetches); fetches != 1 {
+		t.Errorf("jwks fetched %d times, want 1", fetches)
+	}
+}
+
+func TestVerifyRejectsInvalidTokens(t *testing.T) {
+	keys := newTestKeys(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	withClaims := func(modify func(*Claims)) Claims {
+		claims := validClaims()
+		modify(&claims)
+		return claims
+	}
+
+	tests := []struct {
+		name  string
+		token func() string
+		nonce string
+	}{
+		{
+			name: "wrong audience",
+			token: func() string {
+				return sign(t, jwt.SigningMethodRS256, "rsa", withClaims(func(c *Claims) { c.Audience = "other-client-id" }), keys.rsaKey)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "wrong issuer",
+			token: func() string {
+				return sign(t, jwt.SigningMethodRS256, "rsa", withClaims(func(c *Claims) { c.Issuer = "https://other.example.com" }), keys.rsaKey)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "expired",
+			token: func() string {
+				return sign(t, jwt.SigningMethodRS256, "rsa", withClaims(func(c *Claims) {
+					c.IssuedAt = time.Now().Add(-time.Hour * 2).Unix()
+					c.ExpiresAt = time.Now().Add(-time.Hour).Unix()
+				}), keys.rsaKey)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "missing nonce",
+			token: func() string {
+				return sign(t, jwt.SigningMethodRS256, "rsa", validClaims(), keys.rsaKey)
+			},
+			nonce: "",
+		},
+		{
+			name: "missing nonce claim",
+			token: func() string {
+				return sign(t, jwt.SigningMethodRS256, "rsa", withClaims(func(c *Claims) { c.Nonce = "" }), keys.rsaKey)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "mismatched nonce",
+			token: func() string {
+				return sign(t, jwt.SigningMethodRS256, "rsa", validClaims(), keys.rsaKey)
+			},
+			nonce: "other-nonce",
+		},
+		{
+			name: "unknown kid",
+			token: func() string {
+				return sign(t, jwt.SigningMethodRS256, "unknown", validClaims(), keys.rsaKey)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "signed by another key",
+			token: func() string {
+				return sign(t, jwt.SigningMethodRS256, "rsa", validClaims(), otherKey)
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "algorithm of another key",
+			token: func() string {
+				return sign(t, jwt.SigningMethodRS256, "ec", validClaims(), keys.rsaKey)
+			},
+			nonce: testNonce,
+		},
+		{
+			// the public key of the jwks must not be usable as an hmac secret.
+			name: "hs256",
+			token: func() string {
+				return sign(t, jwt.SigningMethodHS256, "rsa", validClaims(), keys.rsaKey.N.Bytes())
+			},
+			nonce: testNonce,
+		},
+		{
+			name: "none",
+			token: func() string {
+				return sign(t, jwt.SigningMethodNone, "rsa", validClaims(), jwt.UnsafeAllowNoneSignatureType)
+			},
+			nonce: testNonce,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verifier := newTestVerifier(t, keys)
+
+			_, err := verifier.Verify(context.Background(), testProvider, tt.token(), tt.nonce)
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("Verify() error = %v, want %v", err, ErrInvalidToken)
+			}
+		})
+	}
+}
+
+func TestVerifyUnknownProvider(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := newTestVerifier(t, keys)
+
+	token := sign(t, jwt.SigningMethodRS256, "rsa", validClaims(), keys.rsaKey)
+	if _, err := verifier.Verify(context.Background(), "other", token, testNonce); !errors.Is(err, ErrUnknownProvider) {
+		t.Errorf("Verify() error = %v, want %v", err, ErrUnknownProvider)
+	}
+}
+
+func TestVerifyJWKSUnavailable(t *testing.T) {
+	keys := newTestKeys(t)
+	verifier := newTestVerifier(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+
+	// a provider that cannot be reached is not the fault of the token.
+	token := sign(t, jwt.SigningMethodRS256, "rsa", validClaims(), keys.rsaKey)
+	_, err := verifier.Verify(context.Background(), testProvider, token, testNonce)
+	if err == nil || errors.Is(err, ErrInvalidToken) {
+		t.Errorf("Verify() error = %v, want a jwks fetch error", err)
+	}
+}
+
+func TestClaimsEmailVerified(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want bool
+	}{
+		{name: "bool", json: `{"email":"user@example.com","email_verified":true}`, want: true},
+		{name: "string", json: `{"email":"user@example.com","email_verified":"true"}`, want: true},
+		{name: "false string", json: `{"email":"user@example.com","email_verified":"false"}`, want: false},
+		{name: "missing", json: `{"email":"user@example.com"}`, want: false},
+		{name: "without email", json: `{"email_verified":true}`, want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var claims Claims
+			if err := json.Unmarshal([]byte(tt.json), &claims); err != nil {
+				t.Fatal(err)
+			}
+			if got := claims.IsEmailVerified(); got != tt.want {
+				t.Errorf("IsEmailVerified() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
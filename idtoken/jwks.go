@@ -0,0 +1,192 @@
+package idtoken
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+const (
+	// jwksCacheDuration is how long a fetched jwks is used before it is fetched again.
+	jwksCacheDuration = time.Hour
+	// jwksMinRefetchInterval limits refetching the jwks for tokens with an unknown kid, so that such tokens cannot be
+	// used to flood the provider with requests.
+	jwksMinRefetchInterval = time.Minute
+)
+
+// jwksFetchError is a failure to fetch the jwks, which is not the fault of the token being verified.
+type jwksFetchError struct {
+	err error
+}
+
+func (err jwksFetchError) Error() string {
+	return "idtoken: fetch jwks: " + err.err.Error()
+}
+
+func (err jwksFetchError) Unwrap() error {
+	return err.err
+}
+
+type publicKey struct {
+	algorithm string
+	publicKey crypto.PublicKey
+}
+
+// keySet caches the keys of a jwks url. The jwks is fetched again once the cache expires or when a token is signed
+// with a kid that is not in the cache, which is how providers rotate their keys.
+type keySet struct {
+	url        string
+	httpClient *http.Client
+
+	mu        sync.Mutex
+	keys      map[string]publicKey
+	fetchedAt time.Time
+}
+
+func newKeySet(url string, httpClient *http.Client) *keySet {
+	return &keySet{url: url, httpClient: httpClient}
+}
+
+func (set *keySet) get(ctx context.Context, kid string) (publicKey, error) {
+	set.mu.Lock()
+	defer set.mu.Unlock()
+
+	if key, ok := set.keys[kid]; ok && time.Since(set.fetchedAt) < jwksCacheDuration {
+		return key, nil
+	}
+	if set.keys != nil && time.Since(set.fetchedAt) < jwksMinRefetchInterval {
+		return publicKey{}, fmt.Errorf("unknown kid %s", kid)
+	}
+
+	keys, err := set.fetch(ctx)
+	if err != nil {
+		// keys that have been fetched before are still used while the provider is unreachable.
+		if key, ok := set.keys[kid]; ok {
+			return key, nil
+		}
+
+		return publicKey{}, jwksFetchError{err: err}
+	}
+	set.keys = keys
+	set.fetchedAt = time.Now()
+
+	key, ok := set.keys[kid]
+	if !ok {
+		return publicKey{}, fmt.Errorf("unknown kid %s", kid)
+	}
+
+	return key, nil
+}
+
+type jwk struct {
+	KeyType   string `json:"kty"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	KeyID     string `json:"kid"`
+	N         string `json:"n"`
+	E         string `json:"e"`
+	Curve     string `json:"crv"`
+	X         string `json:"x"`
+	Y         string `json:"y"`
+}
+
+func (set *keySet) fetch(ctx context.Context) (map[string]publicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, set.url, nil)
+	if err != nil {
+		return nil, err
+	}
+	res, err := set.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %d", res.StatusCode)
+	}
+
+	jwks := struct {
+		Keys []jwk `json:"keys"`
+	}{}
+	if err := json.NewDecoder(res.Body).Decode(&jwks); err != nil {
+		return nil, err
+	}
+
+	keys := map[string]publicKey{}
+	for _, k := range jwks.Keys {
+		if k.Use != "" && k.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(k)
+		if err != nil {
+			// keys of unsupported types are skipped, tokens signed by them fail with an unknown kid.
+			continue
+		}
+		keys[k.KeyID] = key
+	}
+
+	return keys, nil
+}
+
+func parseJWK(k jwk) (publicKey, error) {
+	switch k.KeyType {
+	case "RSA":
+		if k.Algorithm != "" && k.Algorithm != "RS256" {
+			return publicKey{}, fmt.Errorf("unsupported algorithm %s", k.Algorithm)
+		}
+		n, err := decodeBase64URLInt(k.N)
+		if err != nil {
+			return publicKey{}, err
+		}
+		e, err := decodeBase64URLInt(k.E)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if !e.IsInt64() || e.Int64() > 1<<31-1 {
+			return publicKey{}, errors.New("invalid rsa exponent")
+		}
+
+		return publicKey{algorithm: "RS256", publicKey: &rsa.PublicKey{N: n, E: int(e.Int64())}}, nil
+	case "EC":
+		if k.Curve != "P-256" || (k.Algorithm != "" && k.Algorithm != "ES256") {
+			return publicKey{}, fmt.Errorf("unsupported curve %s", k.Curve)
+		}
+		x, err := decodeBase64URLInt(k.X)
+		if err != nil {
+			return publicKey{}, err
+		}
+		y, err := decodeBase64URLInt(k.Y)
+		if err != nil {
+			return publicKey{}, err
+		}
+		if !elliptic.P256().IsOnCurve(x, y) {
+			return publicKey{}, errors.New("point is not on the curve")
+		}
+
+		return publicKey{algorithm: "ES256", publicKey: &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}, nil
+	}
+
+	return publicKey{}, fmt.Errorf("unsupported key type %s", k.KeyType)
+}
+
+func decodeBase64URLInt(s string) (*big.Int, error) {
+	b, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(b) == 0 {
+		return nil, errors.New("empty key parameter")
+	}
+
+	return new(big.Int).SetBytes(b), nil
+}
@@ -0,0 +1,149 @@
+package keymanager
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"errors"
+	"fmt"
+	"io/ioutil"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmES256 = "ES256"
+	AlgorithmEdDSA = "EdDSA"
+	// AlgorithmHS256 keys are shared secrets. They only sign service tokens, which are only verified by our own
+	// services, and are never published in the jwks.
+	AlgorithmHS256 = "HS256"
+)
+
+// minHMACSecretLength is the minimum length of HS256 secrets in bytes, the size of the sha256 output.
+const minHMACSecretLength = 32
+
+// keyParser parses the pem encoded key pairs of an asymmetric algorithm.
+type keyParser struct {
+	parsePrivateKey func(pem []byte) (crypto.PrivateKey, crypto.PublicKey, error)
+	parsePublicKey  func(pem []byte) (crypto.PublicKey, error)
+}
+
+var keyParsers = map[string]keyParser{
+	AlgorithmRS256: {
+		parsePrivateKey: func(pem []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+			privateKey, err := jwt.ParseRSAPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			return privateKey, &privateKey.PublicKey, nil
+		},
+		parsePublicKey: func(pem []byte) (crypto.PublicKey, error) {
+			return jwt.ParseRSAPublicKeyFromPEM(pem)
+		},
+	},
+	AlgorithmES256: {
+		parsePrivateKey: func(pem []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+			privateKey, err := jwt.ParseECPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, nil, err
+			}
+			if privateKey.Curve != elliptic.P256() {
+				return nil, nil, errors.New("ES256 needs a P-256 key")
+			}
+
+			return privateKey, &privateKey.PublicKey, nil
+		},
+		parsePublicKey: func(pem []byte) (crypto.PublicKey, error) {
+			publicKey, err := jwt.ParseECPublicKeyFromPEM(pem)
+			if err != nil {
+				return nil, err
+			}
+			if publicKey.Curve != elliptic.P256() {
+				return nil, errors.New("ES256 needs a P-256 key")
+			}
+
+			return publicKey, nil
+		},
+	},
+	AlgorithmEdDSA: {
+		parsePrivateKey: func(pem []byte) (crypto.PrivateKey, crypto.PublicKey, error) {
+			privateKey, err := jwt.ParseEdPrivateKeyFromPEM(pem)
+			if err != nil {
+				return nil, nil, err
+			}
+			edPrivateKey, ok := privateKey.(ed25519.PrivateKey)
+			if !ok {
+				return nil, nil, errors.New("EdDSA needs an Ed25519 key")
+			}
+
+			return edPrivateKey, edPrivateKey.Public(), nil
+		},
+		parsePublicKey: func(pem []byte) (crypto.PublicKey, error) {
+			return jwt.ParseEdPublicKeyFromPEM(pem)
+		},
+	},
+}
+
+func loadAsymmetricKey(key Key, keyConfig KeyConfig) (Key, error) {
+	parser, ok := keyParsers[key.Algorithm]
+	if !ok {
+		return Key{}, fmt.Errorf("unsupported algorithm %s", key.Algorithm)
+	}
+
+	if keyConfig.PrivateKeyPath != "" {
+		pem, err := ioutil.ReadFile(keyConfig.PrivateKeyPath)
+		if err != nil {
+			return Key{}, err
+		}
+		key.PrivateKey, key.PublicKey, err = parser.parsePrivateKey(pem)
+		if err != nil {
+			return Key{}, fmt.Errorf("parse private key: %w", err)
+		}
+	}
+
+	if keyConfig.PublicKeyPath != "" {
+		pem, err := ioutil.ReadFile(keyConfig.PublicKeyPath)
+		if err != nil {
+			return Key{}, err
+		}
+		key.PublicKey, err = parser.parsePublicKey(pem)
+		if err != nil {
+			return Key{}, fmt.Errorf("parse public key: %w", err)
+		}
+	}
+
+	if key.State == StateActive && key.PrivateKey == nil {
+		return Key{}, errors.New("active key needs a private key")
+	}
+	if key.PublicKey == nil {
+		return Key{}, errors.New("public key not found")
+	}
+
+	return key, nil
+}
+
+func loadHMACKey(key Key, keyConfig KeyConfig) (Key, error) {
+	if keyConfig.SecretPath == "" {
+		return Key{}, errors.New("secret not found")
+	}
+	secret, err := ioutil.ReadFile(keyConfig.SecretPath)
+	if err != nil {
+		return Key{}, err
+	}
+	if len(secret) < minHMACSecretLength {
+		return Key{}, fmt.Errorf("secret must be at least %d bytes long", minHMACSecretLength)
+	}
+
+	key.PrivateKey = secret
+	key.PublicKey = secret
+
+	return key, nil
+}
+
+// IsSymmetric reports whether the key is a shared secret, which only our own services can verify tokens with. Its
+// verification key is never published.
+func (key Key) IsSymmetric() bool {
+	return key.Algorithm == AlgorithmHS256
+}
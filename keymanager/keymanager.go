@@ -0,0 +1,350 @@
+// Package keymanager holds the keys that tokens are signed and verified with. Every key has a kid and a state, which
+// allows rotating keys without invalidating the tokens that were signed by the previous ones.
+package keymanager
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// State decides what a key can be used for.
+type State string
+
+const (
+	// StateActive keys sign new tokens and verify existing ones. There is a single active asymmetric key, which signs
+	// the tokens of users, and at most one active HS256 key, which signs service tokens.
+	StateActive State = "active"
+	// StateVerifyOnly keys only verify tokens that were signed before the key was rotated out.
+	StateVerifyOnly State = "verify_only"
+	// StateRetired keys are neither used for signing nor for verification.
+	StateRetired State = "retired"
+)
+
+var (
+	ErrNoActiveKey = errors.New("no active asymmetric signing key")
+	ErrKeyNotFound = errors.New("key not found")
+)
+
+// Key is a parsed signing key. PrivateKey is nil for keys that cannot sign. For HS256 keys both PrivateKey and
+// PublicKey hold the shared secret.
+type Key struct {
+	ID         string
+	State      State
+	Algorithm  string
+	PrivateKey crypto.PrivateKey
+	PublicKey  crypto.PublicKey
+}
+
+func (key Key) canSign() bool {
+	return key.State == StateActive && key.PrivateKey != nil
+}
+
+func (key Key) canVerify() bool {
+	return (key.State == StateActive || key.State == StateVerifyOnly) && key.PublicKey != nil
+}
+
+// KeyConfig describes where a key is read from. The public key is derived from the private key when PublicKeyPath is
+// not set, and verify-only keys only need the public key. HS256 keys are read from SecretPath instead.
+type KeyConfig struct {
+	ID    string
+	State State
+	// Algorithm is the only algorithm that tokens signed with the key are accepted with, RS256 by default.
+	Algorithm      string
+	PrivateKeyPath string
+	PublicKeyPath  string
+	SecretPath     string
+}
+
+type KeyManager interface {
+	// SigningKey returns the active asymmetric key that new user tokens, such as access and id tokens, are signed
+	// with. It is never an HS256 key, so that the tokens can be verified with the jwks.
+	SigningKey() (*Key, error)
+	// ServiceSigningKey returns the active HS256 key that service tokens are signed with, or the SigningKey when
+	// there is no active HS256 key.
+	ServiceSigningKey() (*Key, error)
+	// VerificationKey returns the key with the given kid if tokens signed by it are still accepted.
+	VerificationKey(kid string) (*Key, error)
+	// JWKS returns the public keys that tokens are currently verified with. HS256 keys are left out.
+	JWKS() JWKS
+	// Refresh reads the keys again if any of the key files has changed since they were last read. The keys in use
+	// are kept when the new ones cannot be loaded.
+	Refresh() error
+}
+
+// New reads and parses the configured keys. Exactly one asymmetric key must be active, along with at most one HS256
+// key. The parsed keys are held in memory, key files are only read again by Refresh.
+func New(configs []KeyConfig) (KeyManager, error) {
+	fileStates, err := statKeyFiles(configs)
+	if err != nil {
+		return nil, err
+	}
+	set, err := loadKeySet(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	return &keyManager{configs: configs, set: set, fileStates: fileStates}, nil
+}
+
+// RefreshEvery calls Refresh on the manager every interval until the returned stop function is called. Errors are
+// passed to onError and do not stop the refreshing.
+func RefreshEvery(manager KeyManager, interval time.Duration, onError func(err error)) (stop func()) {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	go func() {
+		for {
+			select {
+			case <-ticker.C:
+				if err := manager.Refresh(); err != nil && onError != nil {
+					onError(err)
+				}
+			case <-done:
+				ticker.Stop()
+				return
+			}
+		}
+	}()
+
+	var stopOnce sync.Once
+	return func() {
+		stopOnce.Do(func() { close(done) })
+	}
+}
+
+type keyManager struct {
+	configs []KeyConfig
+
+	mu         sync.RWMutex
+	set        *keySet
+	fileStates map[string]fileState
+}
+
+func (manager *keyManager) keySet() *keySet {
+	manager.mu.RLock()
+	defer manager.mu.RUnlock()
+
+	return manager.set
+}
+
+func (manager *keyManager) SigningKey() (*Key, error) {
+	return manager.keySet().signingKey()
+}
+
+func (manager *keyManager) ServiceSigningKey() (*Key, error) {
+	return manager.keySet().serviceSigningKey()
+}
+
+func (manager *keyManager) VerificationKey(kid string) (*Key, error) {
+	return manager.keySet().verificationKey(kid)
+}
+
+func (manager *keyManager) JWKS() JWKS {
+	return manager.keySet().jwks()
+}
+
+func (manager *keyManager) Refresh() error {
+	fileStates, err := statKeyFiles(manager.configs)
+	if err != nil {
+		return err
+	}
+
+	manager.mu.RLock()
+	changed := !sameFileStates(manager.fileStates, fileStates)
+	manager.mu.RUnlock()
+	if !changed {
+		return nil
+	}
+
+	set, err := loadKeySet(manager.configs)
+	if err != nil {
+		return err
+	}
+
+	manager.mu.Lock()
+	manager.set = set
+	manager.fileStates = fileStates
+	manager.mu.Unlock()
+
+	return nil
+}
+
+// keySet is an immutable set of parsed keys. A refresh replaces the whole set, so that readers never see keys of two
+// different loads.
+type keySet struct {
+	keys map[string]Key
+	// order keeps the keys in the order they are configured in, so that the jwks is stable.
+	order             []string
+	signingKID        string
+	serviceSigningKID string
+}
+
+func loadKeySet(configs []KeyConfig) (*keySet, error) {
+	keys, err := loadKeys(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	return newKeySet(keys)
+}
+
+func newKeySet(keys []Key) (*keySet, error) {
+	set := &keySet{keys: map[string]Key{}}
+	for _, key := range keys {
+		if _, ok := set.keys[key.ID]; ok {
+			return nil, fmt.Errorf("keymanager: duplicate kid %s", key.ID)
+		}
+		set.keys[key.ID] = key
+		set.order = append(set.order, key.ID)
+
+		if key.State != StateActive {
+			continue
+		}
+		if key.IsSymmetric() {
+			if set.serviceSigningKID != "" {
+				return nil, fmt.Errorf("keymanager: more than one active HS256 key, %s and %s", set.serviceSigningKID, key.ID)
+			}
+			set.serviceSigningKID = key.ID
+			continue
+		}
+		if set.signingKID != "" {
+			return nil, fmt.Errorf("keymanager: more than one active key, %s and %s", set.signingKID, key.ID)
+		}
+		set.signingKID = key.ID
+	}
+	if set.signingKID == "" {
+		return nil, ErrNoActiveKey
+	}
+
+	return set, nil
+}
+
+func (set *keySet) signingKey() (*Key, error) {
+	key, ok := set.keys[set.signingKID]
+	if !ok || !key.canSign() {
+		return nil, ErrNoActiveKey
+	}
+
+	return &key, nil
+}
+
+func (set *keySet) serviceSigningKey() (*Key, error) {
+	if set.serviceSigningKID == "" {
+		return set.signingKey()
+	}
+
+	key, ok := set.keys[set.serviceSigningKID]
+	if !ok || !key.canSign() {
+		return nil, ErrNoActiveKey
+	}
+
+	return &key, nil
+}
+
+func (set *keySet) verificationKey(kid string) (*Key, error) {
+	key, ok := set.keys[kid]
+	if !ok || !key.canVerify() {
+		return nil, ErrKeyNotFound
+	}
+
+	return &key, nil
+}
+
+func (set *keySet) jwks() JWKS {
+	jwks := JWKS{Keys: []JWK{}}
+	for _, kid := range set.order {
+		key := set.keys[kid]
+		if !key.canVerify() || key.IsSymmetric() {
+			continue
+		}
+
+		jwk, ok := toJWK(key)
+		if !ok {
+			continue
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks
+}
+
+// fileState is what a change of a key file is detected by.
+type fileState struct {
+	modTime time.Time
+	size    int64
+}
+
+func statKeyFiles(configs []KeyConfig) (map[string]fileState, error) {
+	fileStates := map[string]fileState{}
+	for _, keyConfig := range configs {
+		if keyConfig.State == StateRetired {
+			continue
+		}
+		for _, path := range []string{keyConfig.PrivateKeyPath, keyConfig.PublicKeyPath, keyConfig.SecretPath} {
+			if path == "" {
+				continue
+			}
+			info, err := os.Stat(path)
+			if err != nil {
+				return nil, fmt.Errorf("keymanager: load key %s: %w", keyConfig.ID, err)
+			}
+			fileStates[path] = fileState{modTime: info.ModTime(), size: info.Size()}
+		}
+	}
+
+	return fileStates, nil
+}
+
+func sameFileStates(a, b map[string]fileState) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for path, state := range a {
+		if other, ok := b[path]; !ok || !other.modTime.Equal(state.modTime) || other.size != state.size {
+			return false
+		}
+	}
+
+	return true
+}
+
+func loadKeys(configs []KeyConfig) ([]Key, error) {
+	keys := make([]Key, 0, len(configs))
+	for _, keyConfig := range configs {
+		key, err := loadKey(keyConfig)
+		if err != nil {
+			return nil, fmt.Errorf("keymanager: load key %s: %w", keyConfig.ID, err)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func loadKey(keyConfig KeyConfig) (Key, error) {
+	key := Key{ID: keyConfig.ID, State: keyConfig.State, Algorithm: keyConfig.Algorithm}
+	if key.ID == "" {
+		return Key{}, errors.New("kid not found")
+	}
+	if key.Algorithm == "" {
+		key.Algorithm = AlgorithmRS256
+	}
+
+	switch key.State {
+	case StateActive, StateVerifyOnly:
+	case StateRetired:
+		// retired keys stay in the config only to document them, their files are not needed anymore.
+		return key, nil
+	default:
+		return Key{}, fmt.Errorf("unknown state %s", key.State)
+	}
+
+	if key.Algorithm == AlgorithmHS256 {
+		return loadHMACKey(key, keyConfig)
+	}
+
+	return loadAsymmetricKey(key, keyConfig)
+}
@@ -0,0 +1,200 @@
+package keymanager
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// writeRSAKeyFiles writes a new RS256 key pair to pem files in a temporary directory.
+func writeRSAKeyFiles(tb testing.TB) (privateKeyPath string, publicKeyPath string) {
+	tb.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		tb.Fatal(err)
+	}
+	publicKeyDER, err := x509.MarshalPKIXPublicKey(&privateKey.PublicKey)
+	if err != nil {
+		tb.Fatal(err)
+	}
+
+	dir := tb.TempDir()
+	privateKeyPath = filepath.Join(dir, "private.pem")
+	publicKeyPath = filepath.Join(dir, "public.pem")
+	writePEM(tb, privateKeyPath, "RSA PRIVATE KEY", x509.MarshalPKCS1PrivateKey(privateKey))
+	writePEM(tb, publicKeyPath, "PUBLIC KEY", publicKeyDER)
+
+	return privateKeyPath, publicKeyPath
+}
+
+func writePEM(tb testing.TB, path string, blockType string, der []byte) {
+	tb.Helper()
+
+	if err := os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: blockType, Bytes: der}), 0600); err != nil {
+		tb.Fatal(err)
+	}
+}
+
+// writeHMACSecretFile writes a new HS256 secret to a file in a temporary directory.
+func writeHMACSecretFile(tb testing.TB) string {
+	tb.Helper()
+
+	secret := make([]byte, minHMACSecretLength)
+	if _, err := rand.Read(secret); err != nil {
+		tb.Fatal(err)
+	}
+	path := filepath.Join(tb.TempDir(), "secret")
+	if err := os.WriteFile(path, secret, 0600); err != nil {
+		tb.Fatal(err)
+	}
+
+	return path
+}
+
+func TestNewSigningKeys(t *testing.T) {
+	privateKeyPath, publicKeyPath := writeRSAKeyFiles(t)
+	secretPath := writeHMACSecretFile(t)
+	rsaKey := KeyConfig{ID: "rsa", State: StateActive, PrivateKeyPath: privateKeyPath, PublicKeyPath: publicKeyPath}
+	hmacKey := KeyConfig{ID: "hmac", State: StateActive, Algorithm: AlgorithmHS256, SecretPath: secretPath}
+	otherHMACKey := KeyConfig{ID: "other-hmac", State: StateActive, Algorithm: AlgorithmHS256, SecretPath: secretPath}
+
+	tests := []struct {
+		name              string
+		configs           []KeyConfig
+		wantErr           bool
+		signingKID        string
+		serviceSigningKID string
+	}{
+		{name: "asymmetric key signs every token", configs: []KeyConfig{rsaKey}, signingKID: "rsa", serviceSigningKID: "rsa"},
+		{name: "hmac key only signs service tokens", configs: []KeyConfig{rsaKey, hmacKey}, signingKID: "rsa", serviceSigningKID: "hmac"},
+		{name: "at most one active hmac key", configs: []KeyConfig{rsaKey, hmacKey, otherHMACKey}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager, err := New(tt.configs)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatal("New() succeeded, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("New() error = %v", err)
+			}
+
+			signingKey, err := manager.SigningKey()
+			if err != nil || signingKey.ID != tt.signingKID {
+				t.Errorf("SigningKey() = %v, %v, want kid %s", signingKey, err, tt.signingKID)
+			}
+			serviceSigningKey, err := manager.ServiceSigningKey()
+			if err != nil || serviceSigningKey.ID != tt.serviceSigningKID {
+				t.Errorf("ServiceSigningKey() = %v, %v, want kid %s", serviceSigningKey, err, tt.serviceSigningKID)
+			}
+			for _, jwk := range manager.JWKS().Keys {
+				if jwk.KeyID == "hmac" {
+					t.Error("JWKS() publishes the hmac key")
+				}
+			}
+		})
+	}
+}
+
+func TestNewWithoutActiveAsymmetricKey(t *testing.T) {
+	secretPath := writeHMACSecretFile(t)
+
+	_, err := New([]KeyConfig{{ID: "hmac", State: StateActive, Algorithm: AlgorithmHS256, SecretPath: secretPath}})
+	if !errors.Is(err, ErrNoActiveKey) {
+		t.Errorf("New() error = %v, want %v", err, ErrNoActiveKey)
+	}
+}
+
+// BenchmarkVerificationKey compares reading and parsing the public key on every request, as tokens were validated
+// before the keys were cached, with looking the parsed key up in the manager.
+func BenchmarkVerificationKey(b *testing.B) {
+	privateKeyPath, publicKeyPath := writeRSAKeyFiles(b)
+	keyConfig := KeyConfig{ID: "1", State: StateActive, PrivateKeyPath: privateKeyPath, PublicKeyPath: publicKeyPath}
+	manager, err := New([]KeyConfig{keyConfig})
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("read_per_request", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := readPublicKey(publicKeyPath); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := manager.VerificationKey("1"); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkValidate compares the cost of validating an RS256 token with and without the cached key.
+func BenchmarkValidate(b *testing.B) {
+	privateKeyPath, publicKeyPath := writeRSAKeyFiles(b)
+	keyConfig := KeyConfig{ID: "1", State: StateActive, PrivateKeyPath: privateKeyPath, PublicKeyPath: publicKeyPath}
+	manager, err := New([]KeyConfig{keyConfig})
+	if err != nil {
+		b.Fatal(err)
+	}
+	signingKey, err := manager.SigningKey()
+	if err != nil {
+		b.Fatal(err)
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.StandardClaims{Subject: "1"})
+	token.Header["kid"] = signingKey.ID
+	signedToken, err := token.SignedString(signingKey.PrivateKey)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	validate := func(b *testing.B, keyFunc jwt.Keyfunc) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			if _, err := jwt.ParseWithClaims(signedToken, &jwt.StandardClaims{}, keyFunc); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+
+	b.Run("read_per_request", func(b *testing.B) {
+		validate(b, func(token *jwt.Token) (interface{}, error) {
+			return readPublicKey(publicKeyPath)
+		})
+	})
+	b.Run("cached", func(b *testing.B) {
+		validate(b, func(token *jwt.Token) (interface{}, error) {
+			key, err := manager.VerificationKey(token.Header["kid"].(string))
+			if err != nil {
+				return nil, err
+			}
+
+			return key.PublicKey, nil
+		})
+	})
+}
+
+// readPublicKey is how the public key was read on every request before the keys were cached.
+func readPublicKey(path string) (interface{}, error) {
+	publicKeyPEM, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return jwt.ParseRSAPublicKeyFromPEM(publicKeyPEM)
+}
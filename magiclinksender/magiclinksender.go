@@ -0,0 +1,29 @@
+package magiclinksender
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/devesh2997/consequent/emailsender"
+)
+
+const subject = "Your sign in link"
+
+type MagicLinkSender interface {
+	Send(ctx context.Context, email string, link string, expiresIn int) error
+}
+
+func NewEmailMagicLinkSender(emailSender emailsender.EmailSender) MagicLinkSender {
+	return emailMagicLinkSender{emailSender: emailSender}
+}
+
+type emailMagicLinkSender struct {
+	emailSender emailsender.EmailSender
+}
+
+// Send emails the sign in link, which expires in expiresIn minutes.
+func (sender emailMagicLinkSender) Send(ctx context.Context, email string, link string, expiresIn int) error {
+	body := fmt.Sprintf("Use the link below to sign in. It expires in %d minutes and can only be used once.\n\n%s\n\nIf you did not request this link, you can ignore this email.\n", expiresIn, link)
+
+	return sender.emailSender.Send(ctx, email, subject, body)
+}
@@ -0,0 +1,3 @@
+ALTER TABLE `refresh_tokens`
+    DROP INDEX `idx_refresh_tokens_family_id`,
+    DROP COLUMN `family_id`;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE `refresh_tokens`
+    ADD COLUMN `family_id` varchar(36) NOT NULL DEFAULT '' AFTER `token`,
+    ADD INDEX `idx_refresh_tokens_family_id` (`family_id`);
\ No newline at end of file
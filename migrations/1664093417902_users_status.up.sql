@@ -0,0 +1,2 @@
+ALTER TABLE `users`
+    ADD COLUMN `status` varchar(50) NOT NULL DEFAULT 'active' AFTER `gender`;
\ No newline at end of file
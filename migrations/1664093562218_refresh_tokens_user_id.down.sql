@@ -0,0 +1,3 @@
+ALTER TABLE `refresh_tokens`
+    DROP INDEX `idx_refresh_tokens_user_id`,
+    DROP COLUMN `user_id`;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE `refresh_tokens`
+    ADD COLUMN `user_id` int NOT NULL DEFAULT 0 AFTER `id`,
+    ADD INDEX `idx_refresh_tokens_user_id` (`user_id`);
\ No newline at end of file
@@ -0,0 +1,13 @@
+CREATE TABLE IF NOT EXISTS `password_reset_tokens` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` int NOT NULL,
+    `reset_id` varchar(36) NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `channel` varchar(20) NOT NULL,
+    `attempts` int NOT NULL DEFAULT 0,
+    `status` varchar(50) NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expiry_at` timestamp NOT NULL,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_password_reset_tokens_reset_id` (`reset_id`)
+);
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE `user_login_mobile_otps`
+    DROP INDEX `idx_user_login_mobile_otps_mobile_status`,
+    DROP COLUMN `max_attempts`,
+    DROP COLUMN `failed_attempts`;
\ No newline at end of file
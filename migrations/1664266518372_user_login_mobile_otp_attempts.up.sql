@@ -0,0 +1,4 @@
+ALTER TABLE `user_login_mobile_otps`
+    ADD COLUMN `failed_attempts` int NOT NULL DEFAULT 0 AFTER `status`,
+    ADD COLUMN `max_attempts` int NOT NULL DEFAULT 5 AFTER `failed_attempts`,
+    ADD INDEX `idx_user_login_mobile_otps_mobile_status` (`mobile`, `status`);
\ No newline at end of file
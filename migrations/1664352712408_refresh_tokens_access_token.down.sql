@@ -0,0 +1,3 @@
+ALTER TABLE `refresh_tokens`
+    DROP COLUMN `access_token_expiry_at`,
+    DROP COLUMN `access_token_id`;
\ No newline at end of file
@@ -0,0 +1,3 @@
+ALTER TABLE `refresh_tokens`
+    ADD COLUMN `access_token_id` varchar(36) NOT NULL DEFAULT '' AFTER `family_id`,
+    ADD COLUMN `access_token_expiry_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP AFTER `access_token_id`;
\ No newline at end of file
@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `access_token_denylist` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `token_id` varchar(36) NOT NULL,
+    `expiry_at` timestamp NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_access_token_denylist_token_id` (`token_id`),
+    INDEX `idx_access_token_denylist_expiry_at` (`expiry_at`)
+);
\ No newline at end of file
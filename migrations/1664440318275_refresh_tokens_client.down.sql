@@ -0,0 +1,3 @@
+ALTER TABLE `refresh_tokens`
+    DROP COLUMN `scope`,
+    DROP COLUMN `client_id`;
\ No newline at end of file
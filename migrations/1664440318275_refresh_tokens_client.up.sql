@@ -0,0 +1,3 @@
+ALTER TABLE `refresh_tokens`
+    ADD COLUMN `client_id` varchar(64) NOT NULL DEFAULT '' AFTER `access_token_expiry_at`,
+    ADD COLUMN `scope` varchar(1000) NOT NULL DEFAULT '' AFTER `client_id`;
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `oauth_clients`;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `oauth_clients` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `client_id` varchar(64) NOT NULL,
+    `secret_hash` varchar(64) NOT NULL DEFAULT '',
+    `name` varchar(255) NOT NULL,
+    `type` varchar(20) NOT NULL,
+    `redirect_uris` text NOT NULL,
+    `scopes` varchar(1000) NOT NULL DEFAULT '',
+    `first_party` tinyint(1) NOT NULL DEFAULT 0,
+    `status` varchar(50) NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_oauth_clients_client_id` (`client_id`)
+);
\ No newline at end of file
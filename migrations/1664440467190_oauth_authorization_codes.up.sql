@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `oauth_authorization_codes` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `code_hash` varchar(64) NOT NULL,
+    `client_id` varchar(64) NOT NULL,
+    `user_id` int NOT NULL,
+    `redirect_uri` text NOT NULL,
+    `scope` varchar(1000) NOT NULL DEFAULT '',
+    `code_challenge` varchar(128) NOT NULL,
+    `code_challenge_method` varchar(10) NOT NULL,
+    `status` varchar(50) NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expiry_at` timestamp NOT NULL,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_oauth_authorization_codes_code_hash` (`code_hash`)
+);
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `oauth_consents` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` int NOT NULL,
+    `client_id` varchar(64) NOT NULL,
+    `scopes` varchar(1000) NOT NULL DEFAULT '',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_oauth_consents_user_id_client_id` (`user_id`, `client_id`)
+);
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE `oauth_authorization_codes`
+    DROP COLUMN `nonce`;
\ No newline at end of file
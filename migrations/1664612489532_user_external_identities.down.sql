@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `user_external_identities`;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `user_external_identities` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` int NOT NULL,
+    `provider` varchar(32) NOT NULL,
+    `subject` varchar(255) NOT NULL,
+    `email` varchar(255) NOT NULL DEFAULT '',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_user_external_identities_provider_subject` (`provider`, `subject`),
+    KEY `idx_user_external_identities_user_id` (`user_id`)
+);
\ No newline at end of file
@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `user_totps` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` int NOT NULL,
+    `secret` varchar(64) NOT NULL,
+    `status` varchar(50) NOT NULL,
+    `last_used_counter` bigint NOT NULL DEFAULT 0,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_user_totps_user_id` (`user_id`)
+);
\ No newline at end of file
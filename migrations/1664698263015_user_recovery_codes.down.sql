@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `user_recovery_codes`;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `user_recovery_codes` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` int NOT NULL,
+    `code_hash` varchar(64) NOT NULL,
+    `status` varchar(50) NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY `idx_user_recovery_codes_user_id` (`user_id`)
+);
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `second_factor_challenges`;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `second_factor_challenges` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` int NOT NULL,
+    `token_hash` varchar(64) NOT NULL,
+    `attempts` int NOT NULL DEFAULT 0,
+    `status` varchar(50) NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expiry_at` timestamp NOT NULL,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_second_factor_challenges_token_hash` (`token_hash`)
+);
\ No newline at end of file
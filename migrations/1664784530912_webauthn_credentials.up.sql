@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `webauthn_credentials` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` int NOT NULL,
+    `credential_id` varchar(1400) CHARACTER SET ascii NOT NULL,
+    `public_key` blob NOT NULL,
+    `sign_count` bigint NOT NULL DEFAULT 0,
+    `aaguid` varchar(32) NOT NULL DEFAULT '',
+    `attestation_format` varchar(50) NOT NULL,
+    `last_used_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_webauthn_credentials_credential_id` (`credential_id`),
+    KEY `idx_webauthn_credentials_user_id` (`user_id`)
+);
\ No newline at end of file
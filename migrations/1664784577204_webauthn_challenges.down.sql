@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `webauthn_challenges`;
\ No newline at end of file
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS `webauthn_challenges` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `challenge_id` varchar(36) NOT NULL,
+    `challenge` varchar(64) NOT NULL,
+    `user_id` int NOT NULL DEFAULT 0,
+    `ceremony` varchar(50) NOT NULL,
+    `status` varchar(50) NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expiry_at` timestamp NOT NULL,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_webauthn_challenges_challenge_id` (`challenge_id`)
+);
\ No newline at end of file
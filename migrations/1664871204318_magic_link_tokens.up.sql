@@ -0,0 +1,10 @@
+CREATE TABLE IF NOT EXISTS `magic_link_tokens` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `token_id` varchar(36) NOT NULL,
+    `email` varchar(255) NOT NULL,
+    `status` varchar(50) NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expiry_at` timestamp NOT NULL,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_magic_link_tokens_token_id` (`token_id`)
+);
\ No newline at end of file
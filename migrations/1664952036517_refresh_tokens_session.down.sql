@@ -0,0 +1,5 @@
+ALTER TABLE `refresh_tokens`
+    DROP COLUMN `session_created_at`,
+    DROP COLUMN `device_name`,
+    DROP COLUMN `ip_address`,
+    DROP COLUMN `user_agent`;
\ No newline at end of file
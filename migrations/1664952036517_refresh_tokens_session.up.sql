@@ -0,0 +1,5 @@
+ALTER TABLE `refresh_tokens`
+    ADD COLUMN `user_agent` varchar(512) NOT NULL DEFAULT '' AFTER `scope`,
+    ADD COLUMN `ip_address` varchar(45) NOT NULL DEFAULT '' AFTER `user_agent`,
+    ADD COLUMN `device_name` varchar(255) NOT NULL DEFAULT '' AFTER `ip_address`,
+    ADD COLUMN `session_created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP AFTER `device_name`;
\ No newline at end of file
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS `api_keys` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` int NOT NULL,
+    `name` varchar(100) NOT NULL,
+    `prefix` varchar(16) NOT NULL,
+    `key_hash` varchar(64) NOT NULL,
+    `scope` varchar(1000) NOT NULL DEFAULT '',
+    `status` varchar(50) NOT NULL,
+    `expiry_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_api_keys_key_hash` (`key_hash`),
+    KEY `idx_api_keys_user_id` (`user_id`)
+);
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE `oauth_clients`
+    DROP COLUMN `public_key`;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE `oauth_clients`
+    ADD COLUMN `public_key` varchar(2048) NOT NULL DEFAULT '' AFTER `secret_hash`;
\ No newline at end of file
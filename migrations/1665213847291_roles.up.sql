@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `roles` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `name` varchar(50) NOT NULL,
+    `description` varchar(255) NOT NULL DEFAULT '',
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_roles_name` (`name`)
+);
\ No newline at end of file
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS `role_permissions` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `role_id` int NOT NULL,
+    `permission_id` int NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_role_permissions_role_id_permission_id` (`role_id`, `permission_id`)
+);
\ No newline at end of file
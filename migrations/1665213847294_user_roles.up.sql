@@ -0,0 +1,8 @@
+CREATE TABLE IF NOT EXISTS `user_roles` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` int NOT NULL,
+    `role_id` int NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_user_roles_user_id_role_id` (`user_id`, `role_id`),
+    KEY `idx_user_roles_role_id` (`role_id`)
+);
\ No newline at end of file
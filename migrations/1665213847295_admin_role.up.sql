@@ -0,0 +1,2 @@
+INSERT INTO `roles` (`name`, `description`) VALUES
+    ('admin', 'Manages users and their roles');
\ No newline at end of file
@@ -0,0 +1 @@
+DELETE FROM `permissions` WHERE `name` IN ('users:read', 'users:write', 'roles:read', 'roles:write');
\ No newline at end of file
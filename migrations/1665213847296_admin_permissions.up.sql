@@ -0,0 +1,5 @@
+INSERT INTO `permissions` (`name`, `description`) VALUES
+    ('users:read', 'View users and their sessions'),
+    ('users:write', 'Suspend users and revoke their sessions'),
+    ('roles:read', 'View roles and the roles of users'),
+    ('roles:write', 'Grant roles to and revoke roles from users');
\ No newline at end of file
@@ -0,0 +1,3 @@
+DELETE `role_permissions` FROM `role_permissions`
+    JOIN `roles` ON `roles`.`id` = `role_permissions`.`role_id`
+    WHERE `roles`.`name` = 'admin';
\ No newline at end of file
@@ -0,0 +1,3 @@
+INSERT INTO `role_permissions` (`role_id`, `permission_id`)
+    SELECT `roles`.`id`, `permissions`.`id` FROM `roles` CROSS JOIN `permissions`
+    WHERE `roles`.`name` = 'admin' AND `permissions`.`name` IN ('users:read', 'users:write', 'roles:read', 'roles:write');
\ No newline at end of file
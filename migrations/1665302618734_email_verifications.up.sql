@@ -0,0 +1,15 @@
+CREATE TABLE IF NOT EXISTS `email_verifications` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `verification_id` varchar(255) NOT NULL,
+    `user_id` int NOT NULL,
+    `email` varchar(255) NOT NULL,
+    `code_hash` varchar(64) NOT NULL,
+    `status` varchar(50) NOT NULL,
+    `failed_attempts` int NOT NULL DEFAULT 0,
+    `max_attempts` int NOT NULL DEFAULT 5,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `expiry_at` timestamp NOT NULL,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_email_verifications_verification_id` (`verification_id`),
+    KEY `idx_email_verifications_user_id` (`user_id`)
+);
\ No newline at end of file
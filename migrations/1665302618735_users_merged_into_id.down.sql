@@ -0,0 +1,2 @@
+ALTER TABLE `users`
+    DROP COLUMN `merged_into_id`;
\ No newline at end of file
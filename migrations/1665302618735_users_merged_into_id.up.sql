@@ -0,0 +1,2 @@
+ALTER TABLE `users`
+    ADD COLUMN `merged_into_id` int NULL DEFAULT NULL AFTER `status`;
\ No newline at end of file
@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS `account_deletion_requests` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `user_id` int NOT NULL,
+    `status` varchar(50) NOT NULL,
+    `scheduled_at` timestamp NOT NULL,
+    `completed_at` timestamp NULL DEFAULT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    `updated_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    KEY `idx_account_deletion_requests_user_id` (`user_id`),
+    KEY `idx_account_deletion_requests_status_scheduled_at` (`status`, `scheduled_at`)
+);
\ No newline at end of file
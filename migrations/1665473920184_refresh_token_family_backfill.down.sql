@@ -0,0 +1,2 @@
+-- the family ids are kept, refresh tokens that are in a family of their own behave as before the backfill.
+SELECT 1;
\ No newline at end of file
@@ -0,0 +1 @@
+UPDATE `refresh_tokens` SET `family_id` = UUID() WHERE `family_id` = '';
\ No newline at end of file
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS `oauth_used_client_assertions`;
\ No newline at end of file
@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS `oauth_used_client_assertions` (
+    `id` int NOT NULL AUTO_INCREMENT PRIMARY KEY,
+    `client_id` varchar(64) NOT NULL,
+    `assertion_id` varchar(255) NOT NULL,
+    `expiry_at` timestamp NOT NULL,
+    `created_at` timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY `uk_oauth_used_client_assertions_client_id_assertion_id` (`client_id`, `assertion_id`),
+    INDEX `idx_oauth_used_client_assertions_expiry_at` (`expiry_at`)
+);
\ No newline at end of file
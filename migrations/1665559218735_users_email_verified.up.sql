@@ -0,0 +1,2 @@
+ALTER TABLE `users`
+    ADD COLUMN `email_verified` tinyint(1) NOT NULL DEFAULT 0 AFTER `email`;
\ No newline at end of file
@@ -0,0 +1,2 @@
+ALTER TABLE `second_factor_challenges`
+    ADD INDEX `idx_second_factor_challenges_user_id_updated_at` (`user_id`, `updated_at`);
\ No newline at end of file
@@ -0,0 +1,44 @@
+package constants
+
+const (
+	OAUTH_CLIENT_STATUS_ACTIVE   = "active"
+	OAUTH_CLIENT_STATUS_INACTIVE = "inactive"
+)
+
+const (
+	// OAUTH_CLIENT_TYPE_CONFIDENTIAL clients can keep a secret, e.g. web app backends.
+	OAUTH_CLIENT_TYPE_CONFIDENTIAL = "confidential"
+	// OAUTH_CLIENT_TYPE_PUBLIC clients cannot keep a secret, e.g. single page and mobile apps.
+	OAUTH_CLIENT_TYPE_PUBLIC = "public"
+	// OAUTH_CLIENT_TYPE_SERVICE clients are our internal services, which act on their own behalf with the client
+	// credentials grant and never on behalf of a user.
+	OAUTH_CLIENT_TYPE_SERVICE = "service"
+)
+
+const (
+	AUTHORIZATION_CODE_STATUS_ACTIVE = "active"
+	AUTHORIZATION_CODE_STATUS_USED   = "used"
+)
+
+const (
+	GRANT_TYPE_AUTHORIZATION_CODE = "authorization_code"
+	GRANT_TYPE_REFRESH_TOKEN      = "refresh_token"
+	GRANT_TYPE_CLIENT_CREDENTIALS = "client_credentials"
+)
+
+// CLIENT_ASSERTION_TYPE_JWT_BEARER is the client assertion type of clients that authenticate with a jwt signed by
+// their private key (RFC 7523 section 2.2).
+const CLIENT_ASSERTION_TYPE_JWT_BEARER = "urn:ietf:params:oauth:client-assertion-type:jwt-bearer"
+
+// OpenID Connect scopes (OpenID Connect Core 1.0 section 5.4).
+const (
+	SCOPE_OPENID  = "openid"
+	SCOPE_PROFILE = "profile"
+	SCOPE_EMAIL   = "email"
+	SCOPE_PHONE   = "phone"
+)
+
+const (
+	RESPONSE_TYPE_CODE         = "code"
+	CODE_CHALLENGE_METHOD_S256 = "S256"
+)
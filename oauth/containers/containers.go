@@ -0,0 +1,31 @@
+package containers
+
+import (
+	"github.com/devesh2997/consequent/config"
+	"github.com/devesh2997/consequent/datasources"
+	identityContainers "github.com/devesh2997/consequent/identity/containers"
+	"github.com/devesh2997/consequent/oauth/data/repositories"
+	"github.com/devesh2997/consequent/oauth/domain/services"
+	"github.com/devesh2997/consequent/oauth/presentation/controllers"
+	userContainers "github.com/devesh2997/consequent/user/containers"
+)
+
+func InjectOAuthService() services.OAuthService {
+	ds, err := datasources.Get()
+	if err != nil {
+		panic(err)
+	}
+
+	repo := repositories.NewOAuthRepo(ds.SQLClients.GetGormDB())
+
+	provider := services.ProviderConfig{
+		Issuer:                identityContainers.InjectTokenPolicy().Issuer,
+		AuthorizationEndpoint: config.Config.OIDC.AuthorizationEndpoint,
+	}
+
+	return services.NewOAuthService(repo, userContainers.InjectUserService(), identityContainers.InjectTokenService(), identityContainers.InjectKeyManager(), provider)
+}
+
+func InjectOAuthController() controllers.OAuthController {
+	return controllers.NewOAuthController(InjectOAuthService())
+}
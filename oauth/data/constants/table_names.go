@@ -0,0 +1,8 @@
+package constants
+
+const (
+	TABLE_NAME_OAUTH_CLIENTS                = "oauth_clients"
+	TABLE_NAME_OAUTH_AUTHORIZATION_CODES    = "oauth_authorization_codes"
+	TABLE_NAME_OAUTH_CONSENTS               = "oauth_consents"
+	TABLE_NAME_OAUTH_USED_CLIENT_ASSERTIONS = "oauth_used_client_assertions"
+)
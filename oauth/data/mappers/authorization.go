@@ -0,0 +1,46 @@
+package mappers
+
+import (
+	"math"
+	"time"
+
+	"github.com/devesh2997/consequent/oauth/data/models"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+)
+
+type authorizationResultMapper struct{}
+
+func NewAuthorizationResultMapper() authorizationResultMapper {
+	return authorizationResultMapper{}
+}
+
+func (authorizationResultMapper) ToModel(entity entities.AuthorizationResult) models.AuthorizationResult {
+	result := models.AuthorizationResult{
+		RedirectTo:      entity.RedirectTo,
+		ConsentRequired: entity.ConsentRequired,
+		Scope:           entities.JoinScopes(entity.Scopes),
+	}
+	if entity.Client != nil {
+		client := NewClientMapper().ToModel(*entity.Client)
+		result.Client = &client
+	}
+
+	return result
+}
+
+type tokenResponseMapper struct{}
+
+func NewTokenResponseMapper() tokenResponseMapper {
+	return tokenResponseMapper{}
+}
+
+func (tokenResponseMapper) ToModel(entity entities.TokenResponse) models.TokenResponse {
+	return models.TokenResponse{
+		AccessToken:  entity.AccessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(math.Max(math.Round(time.Until(entity.ExpiryAt).Seconds()), 0)),
+		RefreshToken: entity.RefreshToken,
+		Scope:        entity.Scope,
+		IDToken:      entity.IDToken,
+	}
+}
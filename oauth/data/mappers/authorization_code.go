@@ -0,0 +1,48 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/oauth/data/models"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+)
+
+type authorizationCodeMapper struct{}
+
+func NewAuthorizationCodeMapper() authorizationCodeMapper {
+	return authorizationCodeMapper{}
+}
+
+func (authorizationCodeMapper) ToEntity(model models.AuthorizationCode) entities.AuthorizationCode {
+	return entities.AuthorizationCode{
+		ID:                  model.ID,
+		CodeHash:            model.CodeHash,
+		ClientID:            model.ClientID,
+		UserID:              model.UserID,
+		RedirectURI:         model.RedirectURI,
+		Scope:               model.Scope,
+		CodeChallenge:       model.CodeChallenge,
+		CodeChallengeMethod: model.CodeChallengeMethod,
+		Nonce:               model.Nonce,
+		Status:              model.Status,
+		CreatedAt:           model.CreatedAt,
+		ExpiryAt:            model.ExpiryAt,
+		UpdatedAt:           model.UpdatedAt,
+	}
+}
+
+func (authorizationCodeMapper) ToModel(entity entities.AuthorizationCode) models.AuthorizationCode {
+	return models.AuthorizationCode{
+		ID:                  entity.ID,
+		CodeHash:            entity.CodeHash,
+		ClientID:            entity.ClientID,
+		UserID:              entity.UserID,
+		RedirectURI:         entity.RedirectURI,
+		Scope:               entity.Scope,
+		CodeChallenge:       entity.CodeChallenge,
+		CodeChallengeMethod: entity.CodeChallengeMethod,
+		Nonce:               entity.Nonce,
+		Status:              entity.Status,
+		CreatedAt:           entity.CreatedAt,
+		ExpiryAt:            entity.ExpiryAt,
+		UpdatedAt:           entity.UpdatedAt,
+	}
+}
@@ -0,0 +1,48 @@
+package mappers
+
+import (
+	"strings"
+
+	"github.com/devesh2997/consequent/oauth/data/models"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+)
+
+type clientMapper struct{}
+
+func NewClientMapper() clientMapper {
+	return clientMapper{}
+}
+
+func (clientMapper) ToEntity(model models.Client) entities.Client {
+	return entities.Client{
+		ID:           model.ID,
+		ClientID:     model.ClientID,
+		SecretHash:   model.SecretHash,
+		PublicKey:    model.PublicKey,
+		Name:         model.Name,
+		Type:         model.Type,
+		RedirectURIs: strings.Fields(model.RedirectURIs),
+		Scopes:       entities.SplitScope(model.Scopes),
+		FirstParty:   model.FirstParty,
+		Status:       model.Status,
+		CreatedAt:    model.CreatedAt,
+		UpdatedAt:    model.UpdatedAt,
+	}
+}
+
+func (clientMapper) ToModel(entity entities.Client) models.Client {
+	return models.Client{
+		ID:           entity.ID,
+		ClientID:     entity.ClientID,
+		SecretHash:   entity.SecretHash,
+		PublicKey:    entity.PublicKey,
+		Name:         entity.Name,
+		Type:         entity.Type,
+		RedirectURIs: strings.Join(entity.RedirectURIs, " "),
+		Scopes:       entities.JoinScopes(entity.Scopes),
+		FirstParty:   entity.FirstParty,
+		Status:       entity.Status,
+		CreatedAt:    entity.CreatedAt,
+		UpdatedAt:    entity.UpdatedAt,
+	}
+}
@@ -0,0 +1,34 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/oauth/data/models"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+)
+
+type consentMapper struct{}
+
+func NewConsentMapper() consentMapper {
+	return consentMapper{}
+}
+
+func (consentMapper) ToEntity(model models.Consent) entities.Consent {
+	return entities.Consent{
+		ID:        model.ID,
+		UserID:    model.UserID,
+		ClientID:  model.ClientID,
+		Scopes:    entities.SplitScope(model.Scopes),
+		CreatedAt: model.CreatedAt,
+		UpdatedAt: model.UpdatedAt,
+	}
+}
+
+func (consentMapper) ToModel(entity entities.Consent) models.Consent {
+	return models.Consent{
+		ID:        entity.ID,
+		UserID:    entity.UserID,
+		ClientID:  entity.ClientID,
+		Scopes:    entities.JoinScopes(entity.Scopes),
+		CreatedAt: entity.CreatedAt,
+		UpdatedAt: entity.UpdatedAt,
+	}
+}
@@ -0,0 +1,48 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/oauth/data/models"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+)
+
+type userInfoMapper struct{}
+
+func NewUserInfoMapper() userInfoMapper {
+	return userInfoMapper{}
+}
+
+func (userInfoMapper) ToModel(entity entities.UserInfo) models.UserInfo {
+	return models.UserInfo{
+		Subject:             entity.Subject,
+		Email:               entity.Email,
+		EmailVerified:       entity.EmailVerified,
+		PhoneNumber:         entity.PhoneNumber,
+		PhoneNumberVerified: entity.PhoneNumberVerified,
+		Name:                entity.Name,
+		Gender:              entity.Gender,
+	}
+}
+
+type providerMetadataMapper struct{}
+
+func NewProviderMetadataMapper() providerMetadataMapper {
+	return providerMetadataMapper{}
+}
+
+func (providerMetadataMapper) ToModel(entity entities.ProviderMetadata) models.ProviderMetadata {
+	return models.ProviderMetadata{
+		Issuer:                            entity.Issuer,
+		AuthorizationEndpoint:             entity.AuthorizationEndpoint,
+		TokenEndpoint:                     entity.TokenEndpoint,
+		UserInfoEndpoint:                  entity.UserInfoEndpoint,
+		JWKSURI:                           entity.JWKSURI,
+		ScopesSupported:                   entity.ScopesSupported,
+		ResponseTypesSupported:            entity.ResponseTypesSupported,
+		GrantTypesSupported:               entity.GrantTypesSupported,
+		SubjectTypesSupported:             entity.SubjectTypesSupported,
+		IDTokenSigningAlgValuesSupported:  entity.IDTokenSigningAlgValuesSupported,
+		TokenEndpointAuthMethodsSupported: entity.TokenEndpointAuthMethodsSupported,
+		ClaimsSupported:                   entity.ClaimsSupported,
+		CodeChallengeMethodsSupported:     entity.CodeChallengeMethodsSupported,
+	}
+}
@@ -0,0 +1,24 @@
+package models
+
+type AuthorizationResult struct {
+	RedirectTo      string  `json:"redirect_to,omitempty"`
+	ConsentRequired bool    `json:"consent_required"`
+	Client          *Client `json:"client,omitempty"`
+	Scope           string  `json:"scope,omitempty"`
+}
+
+// TokenResponse is the successful response of the token endpoint (RFC 6749 section 5.1).
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope,omitempty"`
+	IDToken      string `json:"id_token,omitempty"`
+}
+
+// ErrorResponse is the error response of the token endpoint (RFC 6749 section 5.2).
+type ErrorResponse struct {
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description,omitempty"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/oauth/data/constants"
+)
+
+type AuthorizationCode struct {
+	ID                  int64     `json:"id" gorm:"column:id"`
+	CodeHash            string    `json:"-" gorm:"column:code_hash"`
+	ClientID            string    `json:"client_id" gorm:"column:client_id"`
+	UserID              int64     `json:"user_id" gorm:"column:user_id"`
+	RedirectURI         string    `json:"redirect_uri" gorm:"column:redirect_uri"`
+	Scope               string    `json:"scope" gorm:"column:scope"`
+	CodeChallenge       string    `json:"-" gorm:"column:code_challenge"`
+	CodeChallengeMethod string    `json:"-" gorm:"column:code_challenge_method"`
+	Nonce               string    `json:"-" gorm:"column:nonce"`
+	Status              string    `json:"status" gorm:"column:status"`
+	CreatedAt           time.Time `json:"created_at" gorm:"column:created_at"`
+	ExpiryAt            time.Time `json:"expiry_at" gorm:"column:expiry_at"`
+	UpdatedAt           time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (AuthorizationCode) TableName() string {
+	return constants.TABLE_NAME_OAUTH_AUTHORIZATION_CODES
+}
@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/oauth/data/constants"
+)
+
+type Client struct {
+	ID         int64  `json:"-" gorm:"column:id"`
+	ClientID   string `json:"client_id" gorm:"column:client_id"`
+	SecretHash string `json:"-" gorm:"column:secret_hash"`
+	PublicKey  string `json:"-" gorm:"column:public_key"`
+	Name       string `json:"name" gorm:"column:name"`
+	Type       string `json:"-" gorm:"column:type"`
+	// space delimited
+	RedirectURIs string    `json:"-" gorm:"column:redirect_uris"`
+	Scopes       string    `json:"-" gorm:"column:scopes"`
+	FirstParty   bool      `json:"-" gorm:"column:first_party"`
+	Status       string    `json:"-" gorm:"column:status"`
+	CreatedAt    time.Time `json:"-" gorm:"column:created_at"`
+	UpdatedAt    time.Time `json:"-" gorm:"column:updated_at"`
+}
+
+func (Client) TableName() string {
+	return constants.TABLE_NAME_OAUTH_CLIENTS
+}
@@ -0,0 +1,21 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/oauth/data/constants"
+)
+
+type Consent struct {
+	ID       int64  `json:"id" gorm:"column:id"`
+	UserID   int64  `json:"user_id" gorm:"column:user_id"`
+	ClientID string `json:"client_id" gorm:"column:client_id"`
+	// space delimited
+	Scopes    string    `json:"scopes" gorm:"column:scopes"`
+	CreatedAt time.Time `json:"created_at" gorm:"column:created_at"`
+	UpdatedAt time.Time `json:"updated_at" gorm:"column:updated_at"`
+}
+
+func (Consent) TableName() string {
+	return constants.TABLE_NAME_OAUTH_CONSENTS
+}
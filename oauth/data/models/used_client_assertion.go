@@ -0,0 +1,19 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/oauth/data/constants"
+)
+
+type UsedClientAssertion struct {
+	ID          int64     `json:"id" gorm:"column:id"`
+	ClientID    string    `json:"client_id" gorm:"column:client_id"`
+	AssertionID string    `json:"assertion_id" gorm:"column:assertion_id"`
+	ExpiryAt    time.Time `json:"expiry_at" gorm:"column:expiry_at"`
+	CreatedAt   time.Time `json:"created_at" gorm:"column:created_at"`
+}
+
+func (UsedClientAssertion) TableName() string {
+	return constants.TABLE_NAME_OAUTH_USED_CLIENT_ASSERTIONS
+}
@@ -0,0 +1,131 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/devesh2997/consequent/oauth/constants"
+	"github.com/devesh2997/consequent/oauth/data/mappers"
+	"github.com/devesh2997/consequent/oauth/data/models"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+	"github.com/devesh2997/consequent/oauth/domain/repositories"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type oauthRepo struct {
+	db *gorm.DB
+}
+
+func NewOAuthRepo(db *gorm.DB) repositories.OAuthRepo {
+	return oauthRepo{db: db}
+}
+
+func (repo oauthRepo) SaveClient(ctx context.Context, client entities.Client) (*entities.Client, error) {
+	clientModel := mappers.NewClientMapper().ToModel(client)
+	clientModel.UpdatedAt = time.Now()
+	err := repo.db.Save(&clientModel).Error
+	if err != nil {
+		return nil, err
+	}
+
+	clientEntity := mappers.NewClientMapper().ToEntity(clientModel)
+
+	return &clientEntity, nil
+}
+
+func (repo oauthRepo) GetClient(ctx context.Context, clientID string) (*entities.Client, error) {
+	client := models.Client{}
+	res := repo.db.Where("client_id = ?", clientID).Find(&client)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrClientNotFound
+	}
+
+	clientEntity := mappers.NewClientMapper().ToEntity(client)
+
+	return &clientEntity, nil
+}
+
+func (repo oauthRepo) SaveAuthorizationCode(ctx context.Context, code entities.AuthorizationCode) error {
+	codeModel := mappers.NewAuthorizationCodeMapper().ToModel(code)
+	codeModel.UpdatedAt = time.Now()
+	err := repo.db.Save(&codeModel).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo oauthRepo) GetAuthorizationCode(ctx context.Context, codeHash string) (*entities.AuthorizationCode, error) {
+	code := models.AuthorizationCode{}
+	res := repo.db.Where("code_hash = ?", codeHash).Find(&code)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrAuthorizationCodeNotFound
+	}
+
+	codeEntity := mappers.NewAuthorizationCodeMapper().ToEntity(code)
+
+	return &codeEntity, nil
+}
+
+func (repo oauthRepo) MarkAuthorizationCodeUsed(ctx context.Context, id int64) (bool, error) {
+	res := repo.db.Model(&models.AuthorizationCode{}).
+		Where("id = ? AND status = ?", id, constants.AUTHORIZATION_CODE_STATUS_ACTIVE).
+		Updates(map[string]interface{}{"status": constants.AUTHORIZATION_CODE_STATUS_USED, "updated_at": time.Now()})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+func (repo oauthRepo) GetConsent(ctx context.Context, userID int64, clientID string) (*entities.Consent, error) {
+	consent := models.Consent{}
+	res := repo.db.Where("user_id = ? AND client_id = ?", userID, clientID).Find(&consent)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrConsentNotFound
+	}
+
+	consentEntity := mappers.NewConsentMapper().ToEntity(consent)
+
+	return &consentEntity, nil
+}
+
+func (repo oauthRepo) SaveConsent(ctx context.Context, consent entities.Consent) error {
+	consentModel := mappers.NewConsentMapper().ToModel(consent)
+	consentModel.UpdatedAt = time.Now()
+	err := repo.db.Save(&consentModel).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo oauthRepo) UseClientAssertion(ctx context.Context, clientID string, assertionID string, expiryAt time.Time) (bool, error) {
+	now := time.Now()
+
+	// entries of expired assertions are not needed anymore, an expired assertion is refused by its exp claim.
+	err := repo.db.Where("expiry_at <= ?", now).Delete(&models.UsedClientAssertion{}).Error
+	if err != nil {
+		return false, err
+	}
+
+	usedAssertion := models.UsedClientAssertion{ClientID: clientID, AssertionID: assertionID, ExpiryAt: expiryAt, CreatedAt: now}
+	res := repo.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&usedAssertion)
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
@@ -0,0 +1,51 @@
+package entities
+
+import "time"
+
+// AuthorizationRequest is the request of a client to act on behalf of the signed in user (RFC 6749 section 4.1.1),
+// with the code challenge of RFC 7636.
+type AuthorizationRequest struct {
+	ResponseType        string
+	ClientID            string
+	RedirectURI         string
+	Scope               string
+	State               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	// Nonce is passed on to the id token by OpenID Connect clients.
+	Nonce string
+}
+
+// AuthorizationResult either redirects the user back to the client or asks the user for consent first.
+type AuthorizationResult struct {
+	RedirectTo      string
+	ConsentRequired bool
+	Client          *Client
+	Scopes          []string
+}
+
+// TokenRequest is the request of a client to the token endpoint (RFC 6749 sections 4.1.3 and 6).
+type TokenRequest struct {
+	GrantType    string
+	ClientID     string
+	ClientSecret string
+	Code         string
+	RedirectURI  string
+	CodeVerifier string
+	RefreshToken string
+	// Scope is requested with the client credentials grant.
+	Scope string
+	// ClientAssertionType and ClientAssertion authenticate clients with a signed jwt instead of a secret
+	// (RFC 7523 section 2.2).
+	ClientAssertionType string
+	ClientAssertion     string
+}
+
+type TokenResponse struct {
+	AccessToken  string
+	ExpiryAt     time.Time
+	RefreshToken string
+	Scope        string
+	// IDToken is only issued for the openid scope.
+	IDToken string
+}
@@ -0,0 +1,32 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/oauth/constants"
+)
+
+type AuthorizationCode struct {
+	ID       int64
+	CodeHash string
+	ClientID string
+	UserID   int64
+	// RedirectURI is the uri that the code was sent to.
+	RedirectURI         string
+	Scope               string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	Nonce               string
+	Status              string
+	CreatedAt           time.Time
+	ExpiryAt            time.Time
+	UpdatedAt           time.Time
+}
+
+func (code AuthorizationCode) IsActive() bool {
+	return code.Status == constants.AUTHORIZATION_CODE_STATUS_ACTIVE
+}
+
+func (code AuthorizationCode) HasExpired() bool {
+	return time.Now().After(code.ExpiryAt)
+}
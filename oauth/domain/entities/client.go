@@ -0,0 +1,71 @@
+package entities
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/oauth/constants"
+)
+
+type Client struct {
+	ID         int64
+	ClientID   string
+	SecretHash string
+	// PublicKey is the pem encoded public key of service clients that authenticate with a signed jwt instead of a
+	// secret.
+	PublicKey string
+	Name      string
+	Type      string
+	// RedirectURIs are the only uris that authorization responses are sent to. They are compared exactly.
+	RedirectURIs []string
+	// Scopes are the scopes that the client may request.
+	Scopes []string
+	// FirstParty clients are our own apps, users are not asked for consent when signing in to them.
+	FirstParty bool
+	Status     string
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+func (client Client) IsActive() bool {
+	return client.Status == constants.OAUTH_CLIENT_STATUS_ACTIVE
+}
+
+// IsConfidential tells whether the client has to authenticate, which service clients always have to.
+func (client Client) IsConfidential() bool {
+	return client.Type == constants.OAUTH_CLIENT_TYPE_CONFIDENTIAL || client.IsService()
+}
+
+func (client Client) IsService() bool {
+	return client.Type == constants.OAUTH_CLIENT_TYPE_SERVICE
+}
+
+func (client Client) HasRedirectURI(redirectURI string) bool {
+	for _, registeredURI := range client.RedirectURIs {
+		if registeredURI == redirectURI {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (client Client) AllowsScopes(scopes []string) bool {
+	return containsAll(client.Scopes, scopes)
+}
+
+func containsAll(set []string, values []string) bool {
+	for _, value := range values {
+		found := false
+		for _, element := range set {
+			if element == value {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,29 @@
+package entities
+
+import (
+	"strings"
+	"time"
+)
+
+// Consent is the scopes that a user has allowed a client to access.
+type Consent struct {
+	ID        int64
+	UserID    int64
+	ClientID  string
+	Scopes    []string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+func (consent Consent) Covers(scopes []string) bool {
+	return containsAll(consent.Scopes, scopes)
+}
+
+// SplitScope splits a space delimited scope parameter into its scopes.
+func SplitScope(scope string) []string {
+	return strings.Fields(scope)
+}
+
+func JoinScopes(scopes []string) string {
+	return strings.Join(scopes, " ")
+}
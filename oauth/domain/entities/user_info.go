@@ -0,0 +1,29 @@
+package entities
+
+// UserInfo is the claims about a user that a client has been granted (OpenID Connect Core 1.0 section 5.1).
+type UserInfo struct {
+	Subject             string
+	Email               string
+	EmailVerified       bool
+	PhoneNumber         string
+	PhoneNumberVerified bool
+	Name                string
+	Gender              string
+}
+
+// ProviderMetadata is the OpenID Connect discovery document (OpenID Connect Discovery 1.0 section 3).
+type ProviderMetadata struct {
+	Issuer                            string
+	AuthorizationEndpoint             string
+	TokenEndpoint                     string
+	UserInfoEndpoint                  string
+	JWKSURI                           string
+	ScopesSupported                   []string
+	ResponseTypesSupported            []string
+	GrantTypesSupported               []string
+	SubjectTypesSupported             []string
+	IDTokenSigningAlgValuesSupported  []string
+	TokenEndpointAuthMethodsSupported []string
+	ClaimsSupported                   []string
+	CodeChallengeMethodsSupported     []string
+}
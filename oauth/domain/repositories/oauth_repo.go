@@ -0,0 +1,30 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+)
+
+var (
+	ErrClientNotFound            = errors.New("oauth client not found")
+	ErrAuthorizationCodeNotFound = errors.New("authorization code not found")
+	ErrConsentNotFound           = errors.New("consent not found")
+)
+
+type OAuthRepo interface {
+	SaveClient(ctx context.Context, client entities.Client) (*entities.Client, error)
+	GetClient(ctx context.Context, clientID string) (*entities.Client, error)
+	SaveAuthorizationCode(ctx context.Context, code entities.AuthorizationCode) error
+	GetAuthorizationCode(ctx context.Context, codeHash string) (*entities.AuthorizationCode, error)
+	// MarkAuthorizationCodeUsed moves an active authorization code to the used status. It returns false if the
+	// code was not active anymore, i.e. it has already been exchanged by a concurrent request.
+	MarkAuthorizationCodeUsed(ctx context.Context, id int64) (bool, error)
+	GetConsent(ctx context.Context, userID int64, clientID string) (*entities.Consent, error)
+	SaveConsent(ctx context.Context, consent entities.Consent) error
+	// UseClientAssertion records the id of a client assertion until it expires. It returns false if the client has
+	// already used an assertion with the same id.
+	UseClientAssertion(ctx context.Context, clientID string, assertionID string, expiryAt time.Time) (bool, error)
+}
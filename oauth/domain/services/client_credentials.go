@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	"github.com/devesh2997/consequent/oauth/constants"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+	"github.com/devesh2997/consequent/oauth/domain/repositories"
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+)
+
+const (
+	// clientAssertionMaxLifetime is how far in the future the expiry of a client assertion may be. Assertions are
+	// remembered by their jti for this long, so that none can be used twice.
+	clientAssertionMaxLifetime = time.Minute * 5
+	clientAssertionClockSkew   = time.Minute
+)
+
+func (service oauthService) RegisterServiceClient(ctx context.Context, name string, scopes []string, publicKeyPEM string) (*entities.Client, string, error) {
+	if name == "" {
+		return nil, "", errInvalidClientRegistration("client name is required")
+	}
+	if publicKeyPEM != "" {
+		if _, err := parseClientPublicKey(publicKeyPEM); err != nil {
+			return nil, "", errInvalidClientRegistration("public key must be a pem encoded rsa or ec p-256 public key")
+		}
+	}
+
+	client := entities.Client{
+		ClientID:   uuid.New().String(),
+		PublicKey:  publicKeyPEM,
+		Name:       name,
+		Type:       constants.OAUTH_CLIENT_TYPE_SERVICE,
+		Scopes:     scopes,
+		FirstParty: true,
+		Status:     constants.OAUTH_CLIENT_STATUS_ACTIVE,
+		CreatedAt:  time.Now(),
+		UpdatedAt:  time.Now(),
+	}
+
+	// clients with a public key authenticate with signed jwts only, the others with a secret.
+	secret := ""
+	if publicKeyPEM == "" {
+		var err error
+		secret, err = generateSecret()
+		if err != nil {
+			return nil, "", errorx.NewSystemError(-1, err)
+		}
+		client.SecretHash = hashSecret(secret)
+	}
+
+	savedClient, err := service.repo.SaveClient(ctx, client)
+	if err != nil {
+		return nil, "", errorx.NewSystemError(-1, err)
+	}
+
+	return savedClient, secret, nil
+}
+
+// issueServiceToken handles the client credentials grant (RFC 6749 section 4.4), which only service clients may
+// use. No refresh token is issued, services request a new token instead.
+func (service oauthService) issueServiceToken(ctx context.Context, client entities.Client, request entities.TokenRequest) (*entities.TokenResponse, error) {
+	if !client.IsService() {
+		return nil, errUnauthorizedClient()
+	}
+
+	scopes := entities.SplitScope(request.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !client.AllowsScopes(scopes) {
+		return nil, errInvalidScope()
+	}
+	scope := entities.JoinScopes(scopes)
+
+	token, err := service.tokenService.SignServiceToken(ctx, client.ClientID, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.TokenResponse{
+		AccessToken: token.Token,
+		ExpiryAt:    token.ExpiryAt,
+		Scope:       scope,
+	}, nil
+}
+
+// authenticateClientAssertion authenticates a client with a jwt signed by its private key (RFC 7523 section 3). The
+// client id may be left out of the request, as it is the issuer and the subject of the assertion.
+func (service oauthService) authenticateClientAssertion(ctx context.Context, request entities.TokenRequest) (*entities.Client, error) {
+	if request.ClientAssertionType != constants.CLIENT_ASSERTION_TYPE_JWT_BEARER {
+		return nil, errInvalidClient()
+	}
+
+	clientID := request.ClientID
+	if clientID == "" {
+		unverifiedClaims := clientAssertionClaims{}
+		if _, _, err := new(jwt.Parser).ParseUnverified(request.ClientAssertion, &unverifiedClaims); err != nil {
+			return nil, errInvalidClient()
+		}
+		clientID = unverifiedClaims.Subject
+	}
+	if clientID == "" {
+		return nil, errInvalidClient()
+	}
+
+	client, err := service.repo.GetClient(ctx, clientID)
+	if err != nil && err != repositories.ErrClientNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrClientNotFound || !client.IsActive() || client.PublicKey == "" {
+		return nil, errInvalidClient()
+	}
+
+	claims, err := service.verifyClientAssertion(*client, request.ClientAssertion)
+	if err != nil {
+		return nil, errInvalidClient()
+	}
+
+	// the assertion is single use, a replayed one is refused until it would have expired anyway.
+	unused, err := service.repo.UseClientAssertion(ctx, client.ClientID, claims.ID, time.Unix(claims.ExpiresAt, 0).Add(clientAssertionClockSkew))
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if !unused {
+		return nil, errInvalidClient()
+	}
+
+	return client, nil
+}
+
+func (service oauthService) verifyClientAssertion(client entities.Client, assertion string) (*clientAssertionClaims, error) {
+	publicKey, err := parseClientPublicKey(client.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	claims := clientAssertionClaims{}
+	parser := jwt.Parser{SkipClaimsValidation: true}
+	_, err = parser.ParseWithClaims(assertion, &claims, func(token *jwt.Token) (interface{}, error) {
+		// the algorithm is pinned by the type of the registered key.
+		switch publicKey.(type) {
+		case *rsa.PublicKey:
+			if token.Method.Alg() != jwt.SigningMethodRS256.Alg() {
+				return nil, fmt.Errorf("unexpected method: %s", token.Method.Alg())
+			}
+		case *ecdsa.PublicKey:
+			if token.Method.Alg() != jwt.SigningMethodES256.Alg() {
+				return nil, fmt.Errorf("unexpected method: %s", token.Method.Alg())
+			}
+		}
+
+		return publicKey, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if claims.Issuer != client.ClientID || claims.Subject != client.ClientID {
+		return nil, errors.New("issuer and subject must be the client id")
+	}
+	if !claims.Audience.contains(service.tokenEndpoint()) && !claims.Audience.contains(service.provider.Issuer) {
+		return nil, errors.New("invalid audience")
+	}
+	if claims.ExpiresAt == 0 || now.After(time.Unix(claims.ExpiresAt, 0).Add(clientAssertionClockSkew)) {
+		return nil, errors.New("assertion has expired")
+	}
+	if time.Unix(claims.ExpiresAt, 0).After(now.Add(clientAssertionMaxLifetime)) {
+		return nil, errors.New("assertion expires too far in the future")
+	}
+	if claims.NotBefore != 0 && now.Add(clientAssertionClockSkew).Before(time.Unix(claims.NotBefore, 0)) {
+		return nil, errors.New("assertion is not valid yet")
+	}
+	if claims.ID == "" {
+		return nil, errors.New("jti is required")
+	}
+
+	return &claims, nil
+}
+
+func (service oauthService) tokenEndpoint() string {
+	return strings.TrimSuffix(service.provider.Issuer, "/") + "/oauth/token"
+}
+
+func parseClientPublicKey(publicKeyPEM string) (interface{}, error) {
+	if rsaKey, err := jwt.ParseRSAPublicKeyFromPEM([]byte(publicKeyPEM)); err == nil {
+		return rsaKey, nil
+	}
+	ecKey, err := jwt.ParseECPublicKeyFromPEM([]byte(publicKeyPEM))
+	if err != nil {
+		return nil, err
+	}
+	if ecKey.Curve.Params().Name != "P-256" {
+		return nil, errors.New("unsupported curve " + ecKey.Curve.Params().Name)
+	}
+
+	return ecKey, nil
+}
+
+// clientAssertionClaims are the claims of a client assertion. Unlike jwt.StandardClaims, the audience may be a
+// list.
+type clientAssertionClaims struct {
+	Issuer    string            `json:"iss"`
+	Subject   string            `json:"sub"`
+	Audience  assertionAudience `json:"aud"`
+	ExpiresAt int64             `json:"exp"`
+	NotBefore int64             `json:"nbf"`
+	IssuedAt  int64             `json:"iat"`
+	ID        string            `json:"jti"`
+}
+
+// Valid is left to verifyClientAssertion, which knows the client and the token endpoint.
+func (clientAssertionClaims) Valid() error {
+	return nil
+}
+
+type assertionAudience []string
+
+func (audience *assertionAudience) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*audience = assertionAudience{single}
+		return nil
+	}
+
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*audience = list
+
+	return nil
+}
+
+func (audience assertionAudience) contains(value string) bool {
+	for _, element := range audience {
+		if element == value {
+			return true
+		}
+	}
+
+	return false
+}
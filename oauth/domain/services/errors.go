@@ -0,0 +1,78 @@
+package services
+
+import (
+	"net/http"
+
+	"github.com/devesh2997/consequent/errorx"
+)
+
+// Error is an oauth error (RFC 6749 sections 4.1.2.1 and 5.2). Errors of authorization requests are sent back to
+// the client by redirecting to it, errors of token requests are the response of the token endpoint.
+type Error struct {
+	Code        string
+	Description string
+}
+
+func (err Error) Error() string {
+	if err.Description == "" {
+		return err.Code
+	}
+
+	return err.Code + ": " + err.Description
+}
+
+// HTTPStatusCode is the status that the error is responded with by the token and userinfo endpoints.
+func (err Error) HTTPStatusCode() int {
+	switch err.Code {
+	case "invalid_client", "invalid_token":
+		return http.StatusUnauthorized
+	case "insufficient_scope":
+		return http.StatusForbidden
+	}
+
+	return http.StatusBadRequest
+}
+
+var (
+	errInvalidRequest = func(description string) error {
+		return Error{Code: "invalid_request", Description: description}
+	}
+	errInvalidClient = func() error {
+		return Error{Code: "invalid_client", Description: "client authentication failed"}
+	}
+	errInvalidGrant = func(description string) error {
+		return Error{Code: "invalid_grant", Description: description}
+	}
+	errUnauthorizedClient = func() error {
+		return Error{Code: "unauthorized_client", Description: "client is not allowed to use the grant type"}
+	}
+	errUnsupportedGrantType = func() error {
+		return Error{Code: "unsupported_grant_type"}
+	}
+	errUnsupportedResponseType = func() error {
+		return Error{Code: "unsupported_response_type"}
+	}
+	errInvalidScope = func() error {
+		return Error{Code: "invalid_scope", Description: "requested scope is not allowed for the client"}
+	}
+	errInsufficientScope = func() error {
+		return Error{Code: "insufficient_scope", Description: "access token does not have the openid scope"}
+	}
+	errInvalidToken = func() error {
+		return Error{Code: "invalid_token"}
+	}
+	errAccessDenied = func() error {
+		return Error{Code: "access_denied", Description: "user denied the request"}
+	}
+
+	// errors that must not be sent back to the client, since the client or its redirect uri cannot be trusted.
+	errUnknownClient = func() error {
+		return errorx.NewBusinessError(-1, "unknown oauth client")
+	}
+	errInvalidRedirectURI = func() error {
+		return errorx.NewBusinessError(-1, "redirect_uri is not registered for the client")
+	}
+	errInvalidClientRegistration = func(msg string) error {
+		return errorx.NewBusinessError(-1, msg)
+	}
+)
@@ -0,0 +1,439 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"time"
+
+	"github.com/devesh2997/consequent/errorx"
+	identityServices "github.com/devesh2997/consequent/identity/domain/services"
+	"github.com/devesh2997/consequent/keymanager"
+	"github.com/devesh2997/consequent/oauth/constants"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+	"github.com/devesh2997/consequent/oauth/domain/repositories"
+	userRepositories "github.com/devesh2997/consequent/user/domain/repositories"
+	userServices "github.com/devesh2997/consequent/user/domain/services"
+	"github.com/google/uuid"
+)
+
+const authorizationCodeExpiryDuration = time.Minute * 5
+
+type OAuthService interface {
+	// RegisterClient registers a client. The secret of confidential clients is only returned here, it is stored
+	// hashed.
+	RegisterClient(ctx context.Context, name string, redirectURIs []string, scopes []string, confidential bool, firstParty bool) (client *entities.Client, secret string, err error)
+	// RegisterServiceClient registers a service client, which authenticates with a jwt signed by the private key of
+	// the given public key, or with a secret if no public key is given. The secret is only returned here.
+	RegisterServiceClient(ctx context.Context, name string, scopes []string, publicKeyPEM string) (client *entities.Client, secret string, err error)
+	// Authorize handles an authorization request of the signed in user. The user is sent back to the client with
+	// an authorization code, unless the client needs the consent of the user first.
+	Authorize(ctx context.Context, userID int64, request entities.AuthorizationRequest) (*entities.AuthorizationResult, error)
+	// Consent records the decision of the user on an authorization request that needed consent, and sends the
+	// user back to the client.
+	Consent(ctx context.Context, userID int64, request entities.AuthorizationRequest, approved bool) (*entities.AuthorizationResult, error)
+	// Token exchanges an authorization code or a refresh token of a client for tokens. An id token is issued as
+	// well for the openid scope. Service clients get a service token with the client credentials grant.
+	Token(ctx context.Context, request entities.TokenRequest) (*entities.TokenResponse, error)
+	// UserInfo returns the claims about the user that the scope of the access token grants (OpenID Connect).
+	UserInfo(ctx context.Context, userID int64, scope string) (*entities.UserInfo, error)
+	// ProviderMetadata returns the OpenID Connect discovery document.
+	ProviderMetadata(ctx context.Context) (*entities.ProviderMetadata, error)
+}
+
+func NewOAuthService(repo repositories.OAuthRepo, userService userServices.UserService, tokenService identityServices.TokenService, keyManager keymanager.KeyManager, provider ProviderConfig) OAuthService {
+	return oauthService{repo: repo, userService: userService, tokenService: tokenService, keyManager: keyManager, provider: provider}
+}
+
+type oauthService struct {
+	repo         repositories.OAuthRepo
+	userService  userServices.UserService
+	tokenService identityServices.TokenService
+	keyManager   keymanager.KeyManager
+	provider     ProviderConfig
+}
+
+func (service oauthService) RegisterClient(ctx context.Context, name string, redirectURIs []string, scopes []string, confidential bool, firstParty bool) (*entities.Client, string, error) {
+	if name == "" {
+		return nil, "", errInvalidClientRegistration("client name is required")
+	}
+	if len(redirectURIs) == 0 {
+		return nil, "", errInvalidClientRegistration("at least one redirect uri is required")
+	}
+	for _, redirectURI := range redirectURIs {
+		if !isValidRedirectURI(redirectURI) {
+			return nil, "", errInvalidClientRegistration("redirect uri must be absolute and without a fragment: " + redirectURI)
+		}
+	}
+
+	client := entities.Client{
+		ClientID:     uuid.New().String(),
+		Name:         name,
+		Type:         constants.OAUTH_CLIENT_TYPE_PUBLIC,
+		RedirectURIs: redirectURIs,
+		Scopes:       scopes,
+		FirstParty:   firstParty,
+		Status:       constants.OAUTH_CLIENT_STATUS_ACTIVE,
+		CreatedAt:    time.Now(),
+		UpdatedAt:    time.Now(),
+	}
+
+	secret := ""
+	if confidential {
+		var err error
+		secret, err = generateSecret()
+		if err != nil {
+			return nil, "", errorx.NewSystemError(-1, err)
+		}
+		client.Type = constants.OAUTH_CLIENT_TYPE_CONFIDENTIAL
+		client.SecretHash = hashSecret(secret)
+	}
+
+	savedClient, err := service.repo.SaveClient(ctx, client)
+	if err != nil {
+		return nil, "", errorx.NewSystemError(-1, err)
+	}
+
+	return savedClient, secret, nil
+}
+
+func (service oauthService) Authorize(ctx context.Context, userID int64, request entities.AuthorizationRequest) (*entities.AuthorizationResult, error) {
+	validatedRequest, result, err := service.validateAuthorizationRequest(ctx, request)
+	if err != nil || result != nil {
+		return result, err
+	}
+
+	if !validatedRequest.client.FirstParty {
+		consent, err := service.repo.GetConsent(ctx, userID, validatedRequest.client.ClientID)
+		if err != nil && err != repositories.ErrConsentNotFound {
+			return nil, errorx.NewSystemError(-1, err)
+		}
+		if err == repositories.ErrConsentNotFound || !consent.Covers(validatedRequest.scopes) {
+			return &entities.AuthorizationResult{
+				ConsentRequired: true,
+				Client:          validatedRequest.client,
+				Scopes:          validatedRequest.scopes,
+			}, nil
+		}
+	}
+
+	return service.issueAuthorizationCode(ctx, userID, *validatedRequest)
+}
+
+func (service oauthService) Consent(ctx context.Context, userID int64, request entities.AuthorizationRequest, approved bool) (*entities.AuthorizationResult, error) {
+	validatedRequest, result, err := service.validateAuthorizationRequest(ctx, request)
+	if err != nil || result != nil {
+		return result, err
+	}
+
+	if !approved {
+		return redirectWithError(validatedRequest.redirectURI, request.State, errAccessDenied()), nil
+	}
+
+	consent, err := service.repo.GetConsent(ctx, userID, validatedRequest.client.ClientID)
+	if err != nil && err != repositories.ErrConsentNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrConsentNotFound {
+		consent = &entities.Consent{UserID: userID, ClientID: validatedRequest.client.ClientID, CreatedAt: time.Now()}
+	}
+	for _, scope := range validatedRequest.scopes {
+		if !consent.Covers([]string{scope}) {
+			consent.Scopes = append(consent.Scopes, scope)
+		}
+	}
+	consent.UpdatedAt = time.Now()
+	if err := service.repo.SaveConsent(ctx, *consent); err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	return service.issueAuthorizationCode(ctx, userID, *validatedRequest)
+}
+
+type validatedAuthorizationRequest struct {
+	client        *entities.Client
+	redirectURI   string
+	scopes        []string
+	state         string
+	codeChallenge string
+	nonce         string
+}
+
+// validateAuthorizationRequest returns the validated request, or the result that sends the error back to the client.
+// Errors are only returned when the client or the redirect uri are invalid, as the user must not be sent to them.
+func (service oauthService) validateAuthorizationRequest(ctx context.Context, request entities.AuthorizationRequest) (*validatedAuthorizationRequest, *entities.AuthorizationResult, error) {
+	client, err := service.repo.GetClient(ctx, request.ClientID)
+	if err != nil && err != repositories.ErrClientNotFound {
+		return nil, nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrClientNotFound || !client.IsActive() {
+		return nil, nil, errUnknownClient()
+	}
+
+	redirectURI := request.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+	if !client.HasRedirectURI(redirectURI) {
+		return nil, nil, errInvalidRedirectURI()
+	}
+
+	if request.ResponseType != constants.RESPONSE_TYPE_CODE {
+		return nil, redirectWithError(redirectURI, request.State, errUnsupportedResponseType()), nil
+	}
+	if request.CodeChallenge == "" {
+		return nil, redirectWithError(redirectURI, request.State, errInvalidRequest("code_challenge is required")), nil
+	}
+	if request.CodeChallengeMethod != constants.CODE_CHALLENGE_METHOD_S256 {
+		return nil, redirectWithError(redirectURI, request.State, errInvalidRequest("code_challenge_method must be S256")), nil
+	}
+
+	scopes := entities.SplitScope(request.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+	if !client.AllowsScopes(scopes) {
+		return nil, redirectWithError(redirectURI, request.State, errInvalidScope()), nil
+	}
+
+	return &validatedAuthorizationRequest{
+		client:        client,
+		redirectURI:   redirectURI,
+		scopes:        scopes,
+		state:         request.State,
+		codeChallenge: request.CodeChallenge,
+		nonce:         request.Nonce,
+	}, nil, nil
+}
+
+func (service oauthService) issueAuthorizationCode(ctx context.Context, userID int64, request validatedAuthorizationRequest) (*entities.AuthorizationResult, error) {
+	code, err := generateSecret()
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	authorizationCode := entities.AuthorizationCode{
+		CodeHash:            hashSecret(code),
+		ClientID:            request.client.ClientID,
+		UserID:              userID,
+		RedirectURI:         request.redirectURI,
+		Scope:               entities.JoinScopes(request.scopes),
+		CodeChallenge:       request.codeChallenge,
+		CodeChallengeMethod: constants.CODE_CHALLENGE_METHOD_S256,
+		Nonce:               request.nonce,
+		Status:              constants.AUTHORIZATION_CODE_STATUS_ACTIVE,
+		CreatedAt:           time.Now(),
+		ExpiryAt:            time.Now().Add(authorizationCodeExpiryDuration),
+		UpdatedAt:           time.Now(),
+	}
+	if err := service.repo.SaveAuthorizationCode(ctx, authorizationCode); err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	params := url.Values{}
+	params.Set("code", code)
+	if request.state != "" {
+		params.Set("state", request.state)
+	}
+
+	return &entities.AuthorizationResult{RedirectTo: addQueryParams(request.redirectURI, params)}, nil
+}
+
+func (service oauthService) Token(ctx context.Context, request entities.TokenRequest) (*entities.TokenResponse, error) {
+	client, err := service.authenticateClient(ctx, request)
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.GrantType {
+	case constants.GRANT_TYPE_AUTHORIZATION_CODE:
+		return service.exchangeAuthorizationCode(ctx, *client, request)
+	case constants.GRANT_TYPE_REFRESH_TOKEN:
+		return service.exchangeRefreshToken(ctx, *client, request)
+	case constants.GRANT_TYPE_CLIENT_CREDENTIALS:
+		return service.issueServiceToken(ctx, *client, request)
+	}
+
+	return nil, errUnsupportedGrantType()
+}
+
+// authenticateClient checks the secret or the client assertion of confidential clients. Public clients are only
+// identified by their id.
+func (service oauthService) authenticateClient(ctx context.Context, request entities.TokenRequest) (*entities.Client, error) {
+	if request.ClientAssertion != "" {
+		return service.authenticateClientAssertion(ctx, request)
+	}
+
+	clientID, clientSecret := request.ClientID, request.ClientSecret
+	if clientID == "" {
+		return nil, errInvalidClient()
+	}
+
+	client, err := service.repo.GetClient(ctx, clientID)
+	if err != nil && err != repositories.ErrClientNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrClientNotFound || !client.IsActive() {
+		return nil, errInvalidClient()
+	}
+
+	if client.IsConfidential() {
+		// clients with a public key can only authenticate with a client assertion.
+		if client.SecretHash == "" || clientSecret == "" || subtle.ConstantTimeCompare([]byte(hashSecret(clientSecret)), []byte(client.SecretHash)) != 1 {
+			return nil, errInvalidClient()
+		}
+	}
+
+	return client, nil
+}
+
+func (service oauthService) exchangeAuthorizationCode(ctx context.Context, client entities.Client, request entities.TokenRequest) (*entities.TokenResponse, error) {
+	if request.Code == "" {
+		return nil, errInvalidRequest("code is required")
+	}
+
+	code, err := service.repo.GetAuthorizationCode(ctx, hashSecret(request.Code))
+	if err != nil && err != repositories.ErrAuthorizationCodeNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == repositories.ErrAuthorizationCodeNotFound || code.ClientID != client.ClientID {
+		return nil, errInvalidGrant("invalid authorization code")
+	}
+	if !code.IsActive() || code.HasExpired() {
+		return nil, errInvalidGrant("authorization code has expired or has already been used")
+	}
+	if request.RedirectURI != "" && request.RedirectURI != code.RedirectURI {
+		return nil, errInvalidGrant("redirect_uri does not match the authorization request")
+	}
+	if !verifyCodeChallenge(code.CodeChallenge, request.CodeVerifier) {
+		return nil, errInvalidGrant("invalid code_verifier")
+	}
+
+	exchanged, err := service.repo.MarkAuthorizationCodeUsed(ctx, code.ID)
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if !exchanged {
+		return nil, errInvalidGrant("authorization code has expired or has already been used")
+	}
+
+	user, err := service.userService.FindByID(ctx, code.UserID)
+	if err != nil && err != userRepositories.ErrUserNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == userRepositories.ErrUserNotFound || user.IsSuspended() {
+		return nil, errInvalidGrant("user is not allowed to sign in")
+	}
+
+	token, err := service.tokenService.GenerateForClient(ctx, *user, client.ClientID, code.Scope)
+	if err != nil {
+		return nil, err
+	}
+	idToken, err := service.generateIDToken(ctx, *user, client.ClientID, code.Nonce, entities.SplitScope(code.Scope))
+	if err != nil {
+		return nil, err
+	}
+
+	return &entities.TokenResponse{
+		AccessToken:  token.JWT.Token,
+		ExpiryAt:     token.JWT.ExpiryAt,
+		RefreshToken: token.RefreshToken.Token,
+		Scope:        code.Scope,
+		IDToken:      idToken,
+	}, nil
+}
+
+func (service oauthService) exchangeRefreshToken(ctx context.Context, client entities.Client, request entities.TokenRequest) (*entities.TokenResponse, error) {
+	if request.RefreshToken == "" {
+		return nil, errInvalidRequest("refresh_token is required")
+	}
+
+	token, err := service.tokenService.RefreshForClient(ctx, request.RefreshToken, client.ClientID)
+	var systemError errorx.SystemError
+	if errors.As(err, &systemError) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, errInvalidGrant(err.Error())
+	}
+
+	idToken := ""
+	scopes := entities.SplitScope(token.RefreshToken.Scope)
+	if hasScope(scopes, constants.SCOPE_OPENID) {
+		user, err := service.userService.FindByID(ctx, token.RefreshToken.UserID)
+		if err != nil {
+			return nil, errorx.NewSystemError(-1, err)
+		}
+		idToken, err = service.generateIDToken(ctx, *user, client.ClientID, "", scopes)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &entities.TokenResponse{
+		AccessToken:  token.JWT.Token,
+		ExpiryAt:     token.JWT.ExpiryAt,
+		RefreshToken: token.RefreshToken.Token,
+		Scope:        token.RefreshToken.Scope,
+		IDToken:      idToken,
+	}, nil
+}
+
+func redirectWithError(redirectURI string, state string, err error) *entities.AuthorizationResult {
+	oauthError := err.(Error)
+	params := url.Values{}
+	params.Set("error", oauthError.Code)
+	if oauthError.Description != "" {
+		params.Set("error_description", oauthError.Description)
+	}
+	if state != "" {
+		params.Set("state", state)
+	}
+
+	return &entities.AuthorizationResult{RedirectTo: addQueryParams(redirectURI, params)}
+}
+
+func addQueryParams(redirectURI string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		// registered redirect uris are validated, so this does not happen.
+		return redirectURI
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		query[key] = values
+	}
+	u.RawQuery = query.Encode()
+
+	return u.String()
+}
+
+func isValidRedirectURI(redirectURI string) bool {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return false
+	}
+
+	return u.IsAbs() && u.Fragment == ""
+}
+
+func generateSecret() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+func hashSecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+
+	return hex.EncodeToString(hash[:])
+}
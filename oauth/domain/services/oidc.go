@@ -0,0 +1,125 @@
+package services
+
+import (
+	"context"
+	"strconv"
+	"strings"
+
+	"github.com/devesh2997/consequent/errorx"
+	identityServices "github.com/devesh2997/consequent/identity/domain/services"
+	"github.com/devesh2997/consequent/oauth/constants"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+	userEntities "github.com/devesh2997/consequent/user/domain/entities"
+	userRepositories "github.com/devesh2997/consequent/user/domain/repositories"
+	"github.com/golang-jwt/jwt"
+)
+
+// ProviderConfig is what the discovery document is built from. Issuer is the base url that the endpoints are served
+// from, and must equal the iss claim of issued tokens. AuthorizationEndpoint is the sign in page that forwards
+// authorization requests to /oauth/authorize once the user has signed in, it defaults to /oauth/authorize itself.
+type ProviderConfig struct {
+	Issuer                string
+	AuthorizationEndpoint string
+}
+
+func (service oauthService) ProviderMetadata(ctx context.Context) (*entities.ProviderMetadata, error) {
+	signingKey, err := service.keyManager.SigningKey()
+	if err != nil {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+
+	issuer := strings.TrimSuffix(service.provider.Issuer, "/")
+	authorizationEndpoint := service.provider.AuthorizationEndpoint
+	if authorizationEndpoint == "" {
+		authorizationEndpoint = issuer + "/oauth/authorize"
+	}
+
+	return &entities.ProviderMetadata{
+		Issuer:                            issuer,
+		AuthorizationEndpoint:             authorizationEndpoint,
+		TokenEndpoint:                     service.tokenEndpoint(),
+		UserInfoEndpoint:                  issuer + "/oauth/userinfo",
+		JWKSURI:                           issuer + "/.well-known/jwks.json",
+		ScopesSupported:                   []string{constants.SCOPE_OPENID, constants.SCOPE_PROFILE, constants.SCOPE_EMAIL, constants.SCOPE_PHONE},
+		ResponseTypesSupported:            []string{constants.RESPONSE_TYPE_CODE},
+		GrantTypesSupported:               []string{constants.GRANT_TYPE_AUTHORIZATION_CODE, constants.GRANT_TYPE_REFRESH_TOKEN, constants.GRANT_TYPE_CLIENT_CREDENTIALS},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{signingKey.Algorithm},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "private_key_jwt", "none"},
+		ClaimsSupported:                   []string{"sub", "iss", "aud", "exp", "iat", "nonce", "email", "email_verified", "phone_number", "phone_number_verified", "name", "gender"},
+		CodeChallengeMethodsSupported:     []string{constants.CODE_CHALLENGE_METHOD_S256},
+	}, nil
+}
+
+func (service oauthService) UserInfo(ctx context.Context, userID int64, scope string) (*entities.UserInfo, error) {
+	scopes := entities.SplitScope(scope)
+	if !hasScope(scopes, constants.SCOPE_OPENID) {
+		return nil, errInsufficientScope()
+	}
+
+	user, err := service.userService.FindByID(ctx, userID)
+	if err != nil && err != userRepositories.ErrUserNotFound {
+		return nil, errorx.NewSystemError(-1, err)
+	}
+	if err == userRepositories.ErrUserNotFound {
+		return nil, errInvalidToken()
+	}
+
+	userInfo := getUserInfo(*user, scopes)
+
+	return &userInfo, nil
+}
+
+// generateIDToken returns the id token for the openid scope, and nothing for other scopes.
+func (service oauthService) generateIDToken(ctx context.Context, user userEntities.User, clientID string, nonce string, scopes []string) (string, error) {
+	if !hasScope(scopes, constants.SCOPE_OPENID) {
+		return "", nil
+	}
+
+	userInfo := getUserInfo(user, scopes)
+
+	return service.tokenService.SignIDToken(ctx, identityServices.IDTokenClaims{
+		StandardClaims: jwt.StandardClaims{
+			Subject:  userInfo.Subject,
+			Audience: clientID,
+		},
+		Nonce:               nonce,
+		Email:               userInfo.Email,
+		EmailVerified:       userInfo.EmailVerified,
+		PhoneNumber:         userInfo.PhoneNumber,
+		PhoneNumberVerified: userInfo.PhoneNumberVerified,
+		Name:                userInfo.Name,
+		Gender:              userInfo.Gender,
+	})
+}
+
+// getUserInfo returns the claims of the user that the scopes grant access to.
+func getUserInfo(user userEntities.User, scopes []string) entities.UserInfo {
+	userInfo := entities.UserInfo{Subject: strconv.FormatInt(user.ID, 10)}
+	// unverified emails are left out, clients take the email of the claims as the email of the user.
+	if hasScope(scopes, constants.SCOPE_EMAIL) && user.Email != "" && user.EmailVerified {
+		userInfo.Email = user.Email
+		userInfo.EmailVerified = true
+	}
+	if hasScope(scopes, constants.SCOPE_PHONE) && user.Mobile != "" {
+		userInfo.PhoneNumber = user.Mobile
+		// mobile numbers are only ever set through otp verification.
+		userInfo.PhoneNumberVerified = true
+	}
+	if hasScope(scopes, constants.SCOPE_PROFILE) {
+		userInfo.Name = user.Name
+		userInfo.Gender = user.Gender
+	}
+
+	return userInfo
+}
+
+func hasScope(scopes []string, scope string) bool {
+	for _, s := range scopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+)
+
+const (
+	minCodeVerifierLength = 43
+	maxCodeVerifierLength = 128
+)
+
+// verifyCodeChallenge checks the code verifier of a token request against the S256 code challenge of the
+// authorization request (RFC 7636 section 4.6).
+func verifyCodeChallenge(codeChallenge string, codeVerifier string) bool {
+	if len(codeVerifier) < minCodeVerifierLength || len(codeVerifier) > maxCodeVerifierLength {
+		return false
+	}
+	for _, c := range codeVerifier {
+		if !isUnreservedCharacter(c) {
+			return false
+		}
+	}
+
+	hash := sha256.Sum256([]byte(codeVerifier))
+	expectedChallenge := base64.RawURLEncoding.EncodeToString(hash[:])
+
+	return subtle.ConstantTimeCompare([]byte(expectedChallenge), []byte(codeChallenge)) == 1
+}
+
+func isUnreservedCharacter(c rune) bool {
+	return (c >= 'A' && c <= 'Z') || (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') ||
+		c == '-' || c == '.' || c == '_' || c == '~'
+}
@@ -0,0 +1,194 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/devesh2997/consequent/app/controller"
+	"github.com/devesh2997/consequent/contextx"
+	"github.com/devesh2997/consequent/oauth/data/mappers"
+	"github.com/devesh2997/consequent/oauth/data/models"
+	"github.com/devesh2997/consequent/oauth/domain/entities"
+	"github.com/devesh2997/consequent/oauth/domain/services"
+	"github.com/gin-gonic/gin"
+)
+
+type OAuthController interface {
+	Authorize(gCtx *gin.Context)
+	Consent(gCtx *gin.Context)
+	Token(gCtx *gin.Context)
+	UserInfo(gCtx *gin.Context)
+	ProviderMetadata(gCtx *gin.Context)
+}
+
+func NewOAuthController(service services.OAuthService) OAuthController {
+	return oauthController{service: service}
+}
+
+type oauthController struct {
+	controller.Controller
+	service services.OAuthService
+}
+
+type authorizationRequestInput struct {
+	ResponseType        string `json:"response_type" form:"response_type"`
+	ClientID            string `json:"client_id" form:"client_id"`
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri"`
+	Scope               string `json:"scope" form:"scope"`
+	State               string `json:"state" form:"state"`
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method"`
+	Nonce               string `json:"nonce" form:"nonce"`
+}
+
+func (input authorizationRequestInput) toEntity() entities.AuthorizationRequest {
+	return entities.AuthorizationRequest{
+		ResponseType:        input.ResponseType,
+		ClientID:            input.ClientID,
+		RedirectURI:         input.RedirectURI,
+		Scope:               input.Scope,
+		State:               input.State,
+		CodeChallenge:       input.CodeChallenge,
+		CodeChallengeMethod: input.CodeChallengeMethod,
+		Nonce:               input.Nonce,
+	}
+}
+
+// Authorize is called by our sign in page once the user has signed in with any of the identity methods. The page
+// then either sends the user to redirect_to or asks for consent.
+func (c oauthController) Authorize(gCtx *gin.Context) {
+	input := authorizationRequestInput{}
+
+	if err := gCtx.ShouldBindQuery(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+	if input.ClientID == "" {
+		c.SendBadRequestError(gCtx, errors.New("client_id is required"))
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	result, err := c.service.Authorize(gCtx.Request.Context(), requestUser.ID, input.toEntity())
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, mappers.NewAuthorizationResultMapper().ToModel(*result))
+}
+
+func (c oauthController) Consent(gCtx *gin.Context) {
+	input := struct {
+		authorizationRequestInput
+		Approve bool `json:"approve" form:"approve"`
+	}{}
+
+	if err := c.BindQueryAndBody(gCtx, &input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+	if input.ClientID == "" {
+		c.SendBadRequestError(gCtx, errors.New("client_id is required"))
+		return
+	}
+
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	result, err := c.service.Consent(gCtx.Request.Context(), requestUser.ID, input.toEntity(), input.Approve)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, mappers.NewAuthorizationResultMapper().ToModel(*result))
+}
+
+// Token responds in the format of RFC 6749 section 5 instead of the usual response envelope, as oauth clients
+// expect.
+func (c oauthController) Token(gCtx *gin.Context) {
+	input := struct {
+		GrantType    string `form:"grant_type"`
+		ClientID     string `form:"client_id"`
+		ClientSecret string `form:"client_secret"`
+		Code         string `form:"code"`
+		RedirectURI  string `form:"redirect_uri"`
+		CodeVerifier string `form:"code_verifier"`
+		RefreshToken string `form:"refresh_token"`
+		Scope        string `form:"scope"`
+		// client assertions of RFC 7523
+		ClientAssertionType string `form:"client_assertion_type"`
+		ClientAssertion     string `form:"client_assertion"`
+	}{}
+
+	gCtx.Header("Cache-Control", "no-store")
+	gCtx.Header("Pragma", "no-cache")
+
+	if err := gCtx.ShouldBind(&input); err != nil {
+		gCtx.JSON(http.StatusBadRequest, models.ErrorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+		return
+	}
+	// client credentials are accepted in the authorization header as well (RFC 6749 section 2.3.1).
+	if clientID, clientSecret, ok := gCtx.Request.BasicAuth(); ok {
+		input.ClientID = clientID
+		input.ClientSecret = clientSecret
+	}
+
+	response, err := c.service.Token(gCtx.Request.Context(), entities.TokenRequest{
+		GrantType:           input.GrantType,
+		ClientID:            input.ClientID,
+		ClientSecret:        input.ClientSecret,
+		Code:                input.Code,
+		RedirectURI:         input.RedirectURI,
+		CodeVerifier:        input.CodeVerifier,
+		RefreshToken:        input.RefreshToken,
+		Scope:               input.Scope,
+		ClientAssertionType: input.ClientAssertionType,
+		ClientAssertion:     input.ClientAssertion,
+	})
+	var oauthError services.Error
+	if errors.As(err, &oauthError) {
+		gCtx.JSON(oauthError.HTTPStatusCode(), models.ErrorResponse{Error: oauthError.Code, ErrorDescription: oauthError.Description})
+		return
+	}
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	gCtx.JSON(http.StatusOK, mappers.NewTokenResponseMapper().ToModel(*response))
+}
+
+// UserInfo responds with the claims of OpenID Connect Core 1.0 section 5.3.2 instead of the usual response envelope.
+func (c oauthController) UserInfo(gCtx *gin.Context) {
+	requestUser := contextx.GetRequestUser(gCtx.Request.Context())
+
+	gCtx.Header("Cache-Control", "no-store")
+
+	userInfo, err := c.service.UserInfo(gCtx.Request.Context(), requestUser.ID, requestUser.Scope)
+	var oauthError services.Error
+	if errors.As(err, &oauthError) {
+		// errors of bearer token requests are reported in the www-authenticate header (RFC 6750 section 3).
+		gCtx.Header("WWW-Authenticate", `Bearer error="`+oauthError.Code+`"`)
+		gCtx.JSON(oauthError.HTTPStatusCode(), models.ErrorResponse{Error: oauthError.Code, ErrorDescription: oauthError.Description})
+		return
+	}
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	gCtx.JSON(http.StatusOK, mappers.NewUserInfoMapper().ToModel(*userInfo))
+}
+
+func (c oauthController) ProviderMetadata(gCtx *gin.Context) {
+	metadata, err := c.service.ProviderMetadata(gCtx.Request.Context())
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	gCtx.Header("Cache-Control", "public, max-age=300")
+	gCtx.JSON(http.StatusOK, mappers.NewProviderMetadataMapper().ToModel(*metadata))
+}
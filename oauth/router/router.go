@@ -0,0 +1,45 @@
+package router
+
+import (
+	"github.com/devesh2997/consequent/app/middleware"
+	identityContainers "github.com/devesh2997/consequent/identity/containers"
+	"github.com/devesh2997/consequent/oauth/containers"
+	"github.com/gin-gonic/gin"
+)
+
+func InjectOAuthRoutes(router *gin.RouterGroup) {
+	tokenService := identityContainers.InjectTokenService()
+	apiKeyService := identityContainers.InjectAPIKeyService()
+	oauthController := containers.InjectOAuthController()
+
+	router.POST("/token", func(c *gin.Context) {
+		oauthController.Token(c)
+	})
+
+	authorised := router.Group("")
+	authorised.Use(middleware.Authorisation(tokenService, apiKeyService), middleware.RequireUser(), middleware.RequireAccessToken())
+	authorised.GET("/authorize", func(c *gin.Context) {
+		oauthController.Authorize(c)
+	})
+	authorised.POST("/authorize", func(c *gin.Context) {
+		oauthController.Consent(c)
+	})
+
+	// the userinfo endpoint is meant for the access tokens that oauth clients get on behalf of users.
+	clients := router.Group("")
+	clients.Use(middleware.Authorisation(tokenService, apiKeyService), middleware.RequireUserOrClient(), middleware.RequireAccessToken())
+	clients.GET("/userinfo", func(c *gin.Context) {
+		oauthController.UserInfo(c)
+	})
+	clients.POST("/userinfo", func(c *gin.Context) {
+		oauthController.UserInfo(c)
+	})
+}
+
+func InjectWellKnownRoutes(router *gin.RouterGroup) {
+	oauthController := containers.InjectOAuthController()
+
+	router.GET("/openid-configuration", func(c *gin.Context) {
+		oauthController.ProviderMetadata(c)
+	})
+}
@@ -0,0 +1,102 @@
+// Package passwordhash hashes and verifies user passwords. Hashes are stored in an encoded form that carries the
+// algorithm and its parameters, so that the parameters can be changed without invalidating existing hashes.
+package passwordhash
+
+import (
+	"crypto/subtle"
+	"errors"
+	"strings"
+)
+
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+var ErrUnknownAlgorithm = errors.New("passwordhash: unknown hashing algorithm")
+var ErrInvalidHash = errors.New("passwordhash: encoded hash is not in the correct format")
+
+// Hasher is a single password hashing algorithm.
+type Hasher interface {
+	// Hash returns the encoded hash of the given password.
+	Hash(password string) (string, error)
+	// Verify reports whether the password matches the encoded hash. The comparison is done in constant time.
+	Verify(password string, encodedHash string) (bool, error)
+	// NeedsRehash reports whether the encoded hash was created with parameters that differ from the hasher's.
+	NeedsRehash(encodedHash string) bool
+	// Identifies reports whether the encoded hash was created by this algorithm.
+	Identifies(encodedHash string) bool
+}
+
+// PasswordHasher hashes new passwords with a preferred algorithm and verifies hashes created by any of
+// the algorithms it knows about.
+type PasswordHasher interface {
+	Hash(password string) (string, error)
+	Verify(password string, encodedHash string) (bool, error)
+	// NeedsRehash reports whether the encoded hash should be replaced by a hash created with the preferred
+	// algorithm and parameters. Plaintext values always need a rehash.
+	NeedsRehash(encodedHash string) bool
+	// IsHashed reports whether the given value was created by one of the known algorithms.
+	IsHashed(value string) bool
+}
+
+// New returns a PasswordHasher which hashes with preferred and verifies with preferred or any of others.
+func New(preferred Hasher, others ...Hasher) PasswordHasher {
+	return passwordHasher{preferred: preferred, hashers: append([]Hasher{preferred}, others...)}
+}
+
+// NewWithAlgorithm returns a PasswordHasher which hashes with the named algorithm and verifies every known algorithm.
+func NewWithAlgorithm(algorithm string, argon2idParams Argon2idParams, bcryptCost int) (PasswordHasher, error) {
+	argon2idHasher := NewArgon2idHasher(argon2idParams)
+	bcryptHasher := NewBcryptHasher(bcryptCost)
+
+	switch strings.ToLower(algorithm) {
+	case "", AlgorithmArgon2id:
+		return New(argon2idHasher, bcryptHasher), nil
+	case AlgorithmBcrypt:
+		return New(bcryptHasher, argon2idHasher), nil
+	}
+
+	return nil, ErrUnknownAlgorithm
+}
+
+type passwordHasher struct {
+	preferred Hasher
+	hashers   []Hasher
+}
+
+func (h passwordHasher) Hash(password string) (string, error) {
+	return h.preferred.Hash(password)
+}
+
+func (h passwordHasher) Verify(password string, encodedHash string) (bool, error) {
+	hasher := h.identify(encodedHash)
+	if hasher == nil {
+		// the value is a plaintext password that has not been hashed yet.
+		return subtle.ConstantTimeCompare([]byte(password), []byte(encodedHash)) == 1, nil
+	}
+
+	return hasher.Verify(password, encodedHash)
+}
+
+func (h passwordHasher) NeedsRehash(encodedHash string) bool {
+	if !h.preferred.Identifies(encodedHash) {
+		return true
+	}
+
+	return h.preferred.NeedsRehash(encodedHash)
+}
+
+func (h passwordHasher) IsHashed(value string) bool {
+	return h.identify(value) != nil
+}
+
+func (h passwordHasher) identify(encodedHash string) Hasher {
+	for _, hasher := range h.hashers {
+		if hasher.Identifies(encodedHash) {
+			return hasher
+		}
+	}
+
+	return nil
+}
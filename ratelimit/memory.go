@@ -0,0 +1,72 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// sweepInterval is how often expired counters are removed from the memory store.
+const sweepInterval = time.Minute
+
+// NewMemoryCounterStore returns a CounterStore that keeps counters in the memory of the current process.
+func NewMemoryCounterStore() CounterStore {
+	return &memoryCounterStore{counters: map[string]*memoryCounter{}}
+}
+
+type memoryCounter struct {
+	count   int64
+	resetAt time.Time
+}
+
+type memoryCounterStore struct {
+	mu        sync.Mutex
+	counters  map[string]*memoryCounter
+	lastSweep time.Time
+}
+
+func (store *memoryCounterStore) Increment(ctx context.Context, key string, window time.Duration) (int64, time.Time, error) {
+	now := time.Now()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	store.sweep(now)
+
+	counter, ok := store.counters[key]
+	if !ok || !now.Before(counter.resetAt) {
+		counter = &memoryCounter{resetAt: now.Add(window)}
+		store.counters[key] = counter
+	}
+	counter.count++
+
+	return counter.count, counter.resetAt, nil
+}
+
+func (store *memoryCounterStore) Decrement(ctx context.Context, key string) error {
+	now := time.Now()
+
+	store.mu.Lock()
+	defer store.mu.Unlock()
+
+	counter, ok := store.counters[key]
+	if ok && now.Before(counter.resetAt) && counter.count > 0 {
+		counter.count--
+	}
+
+	return nil
+}
+
+// sweep removes the counters whose window has ended, so that the store does not grow without bound.
+func (store *memoryCounterStore) sweep(now time.Time) {
+	if now.Sub(store.lastSweep) < sweepInterval {
+		return
+	}
+	store.lastSweep = now
+
+	for key, counter := range store.counters {
+		if !now.Before(counter.resetAt) {
+			delete(store.counters, key)
+		}
+	}
+}
@@ -0,0 +1,93 @@
+// Package ratelimit limits how often an action can be performed for a key using fixed window counters.
+package ratelimit
+
+import (
+	"context"
+	"time"
+)
+
+// CounterStore keeps the hit counters of keys. Implementations shared by multiple instances of the app
+// make the limits apply across all of them.
+type CounterStore interface {
+	// Increment adds a hit to the current window of the key. It returns the number of hits in the window, including
+	// this one, and the time at which the window ends.
+	Increment(ctx context.Context, key string, window time.Duration) (count int64, resetAt time.Time, err error)
+	// Decrement takes a hit back from the current window of the key.
+	Decrement(ctx context.Context, key string) error
+}
+
+// Limit allows Requests hits per Window. A zero Limit allows everything.
+type Limit struct {
+	Requests int64
+	Window   time.Duration
+}
+
+// KeyedLimit is a Limit that applies to the hits of a key.
+type KeyedLimit struct {
+	Key   string
+	Limit Limit
+}
+
+func (limit Limit) isUnlimited() bool {
+	return limit.Requests <= 0 || limit.Window <= 0
+}
+
+type Limiter interface {
+	// Allow records a hit for the key and reports whether it is within the limit. When it is not, retryAt is the
+	// time after which the key is allowed again.
+	Allow(ctx context.Context, key string, limit Limit) (allowed bool, retryAt time.Time, err error)
+	// AllowAll records a hit for every key and reports whether all of them are within their limits. When one is not,
+	// the hits are taken back, so that a rejected request does not use up the limits of the other keys.
+	AllowAll(ctx context.Context, limits []KeyedLimit) (allowed bool, retryAt time.Time, err error)
+}
+
+func NewLimiter(store CounterStore) Limiter {
+	return limiter{store: store}
+}
+
+type limiter struct {
+	store CounterStore
+}
+
+func (l limiter) Allow(ctx context.Context, key string, limit Limit) (bool, time.Time, error) {
+	if limit.isUnlimited() {
+		return true, time.Time{}, nil
+	}
+
+	count, resetAt, err := l.store.Increment(ctx, key, limit.Window)
+	if err != nil {
+		return false, time.Time{}, err
+	}
+	if count > limit.Requests {
+		return false, resetAt, nil
+	}
+
+	return true, time.Time{}, nil
+}
+
+func (l limiter) AllowAll(ctx context.Context, limits []KeyedLimit) (bool, time.Time, error) {
+	counted := []string{}
+	for _, keyedLimit := range limits {
+		if keyedLimit.Limit.isUnlimited() {
+			continue
+		}
+
+		count, resetAt, err := l.store.Increment(ctx, keyedLimit.Key, keyedLimit.Limit.Window)
+		if err != nil {
+			return false, time.Time{}, err
+		}
+		counted = append(counted, keyedLimit.Key)
+
+		if count > keyedLimit.Limit.Requests {
+			for _, key := range counted {
+				if err := l.store.Decrement(ctx, key); err != nil {
+					return false, time.Time{}, err
+				}
+			}
+
+			return false, resetAt, nil
+		}
+	}
+
+	return true, time.Time{}, nil
+}
@@ -0,0 +1,111 @@
+// Package totp implements time-based one-time passwords (RFC 6238) with the parameters that authenticator apps
+// support everywhere: HMAC-SHA1, 6 digits and a 30 second period.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	Digits = 6
+	Period = 30 * time.Second
+	// secretSize is the size of generated secrets in bytes, the size of the HMAC-SHA1 output as recommended by
+	// RFC 4226.
+	secretSize = 20
+	// skewSteps is the number of periods before and after the current one whose codes are accepted as well, to
+	// allow for clock differences and the time the user takes to type the code.
+	skewSteps = 1
+)
+
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// GenerateSecret returns a random base32 encoded secret.
+func GenerateSecret() (string, error) {
+	b := make([]byte, secretSize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(b), nil
+}
+
+// URI returns the otpauth uri of the secret that authenticator apps are enrolled with, usually through a qr code.
+func URI(issuer string, accountName string, secret string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(Digits))
+	query.Set("period", fmt.Sprint(int(Period.Seconds())))
+
+	label := url.PathEscape(issuer) + ":" + url.PathEscape(accountName)
+
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// Code returns the code of the secret for the period that t falls in.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decodeSecret(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return hotp(key, counter(t)), nil
+}
+
+// Validate checks the code against the codes of the secret around t. It returns the counter of the period that the
+// code belongs to, which callers store to refuse the same code, or an older one, from being used again.
+func Validate(secret string, code string, t time.Time) (int64, bool) {
+	key, err := decodeSecret(secret)
+	if err != nil || len(code) != Digits {
+		return 0, false
+	}
+
+	current := counter(t)
+	for step := int64(-skewSteps); step <= skewSteps; step++ {
+		if subtle.ConstantTimeCompare([]byte(hotp(key, current+step)), []byte(code)) == 1 {
+			return current + step, true
+		}
+	}
+
+	return 0, false
+}
+
+func counter(t time.Time) int64 {
+	return t.Unix() / int64(Period.Seconds())
+}
+
+// hotp is the HOTP value of RFC 4226 section 5.3.
+func hotp(key []byte, counter int64) string {
+	msg := make([]byte, 8)
+	binary.BigEndian.PutUint64(msg, uint64(counter))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	modulus := uint32(1)
+	for i := 0; i < Digits; i++ {
+		modulus *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%modulus)
+}
+
+func decodeSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+
+	return encoding.DecodeString(strings.TrimRight(secret, "="))
+}
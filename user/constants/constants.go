@@ -0,0 +1,27 @@
+package constants
+
+const (
+	USER_STATUS_ACTIVE    = "active"
+	USER_STATUS_SUSPENDED = "suspended"
+	// users that have been merged into another user, see the merged_into_id column.
+	USER_STATUS_MERGED = "merged"
+	// users whose account has been deleted, their personal data is cleared from the row.
+	USER_STATUS_DELETED = "deleted"
+)
+
+// permissions that the admin apis are guarded with, see the permissions table.
+const (
+	PERMISSION_USERS_READ  = "users:read"
+	PERMISSION_USERS_WRITE = "users:write"
+	PERMISSION_ROLES_READ  = "roles:read"
+	PERMISSION_ROLES_WRITE = "roles:write"
+)
+
+// scopes that api keys can be limited to, each allows the user apis that only read what its name says. Keys without
+// a scope can be used for all of them.
+const (
+	SCOPE_USER_READ     = "user:read"
+	SCOPE_SESSIONS_READ = "sessions:read"
+	SCOPE_API_KEYS_READ = "api-keys:read"
+	SCOPE_ACCOUNT_READ  = "account:read"
+)
@@ -18,8 +18,26 @@ func InjectUserService() services.UserService {
 	return services.NewUserService(repo)
 }
 
+func InjectRoleService() services.RoleService {
+	ds, err := datasources.Get()
+	if err != nil {
+		panic(err)
+	}
+
+	repo := repositories.NewRoleRepository(ds.SQLClients.GetGormDB())
+	userRepo := repositories.NewUserRepository(ds.SQLClients.GetGormDB())
+
+	return services.NewRoleService(repo, userRepo)
+}
+
 func InjectUserController() controllers.UserController {
 	service := InjectUserService()
 
 	return controllers.NewUserController(service)
 }
+
+func InjectRoleController() controllers.RoleController {
+	service := InjectRoleService()
+
+	return controllers.NewRoleController(service)
+}
@@ -1,5 +1,9 @@
 package constants
 
 const (
-	TABLE_NAME_USERS = "users"
+	TABLE_NAME_USERS            = "users"
+	TABLE_NAME_ROLES            = "roles"
+	TABLE_NAME_PERMISSIONS      = "permissions"
+	TABLE_NAME_ROLE_PERMISSIONS = "role_permissions"
+	TABLE_NAME_USER_ROLES       = "user_roles"
 )
@@ -0,0 +1,30 @@
+package mappers
+
+import (
+	"github.com/devesh2997/consequent/user/data/models"
+	"github.com/devesh2997/consequent/user/domain/entities"
+)
+
+type roleMapper struct{}
+
+func NewRoleMapper() roleMapper {
+	return roleMapper{}
+}
+
+func (mapper roleMapper) ToModel(entity entities.Role) models.Role {
+	return models.Role{
+		ID:          entity.ID,
+		Name:        entity.Name,
+		Description: entity.Description,
+		Permissions: entity.Permissions,
+	}
+}
+
+func (mapper roleMapper) ToEntity(model models.Role) entities.Role {
+	return entities.Role{
+		ID:          model.ID,
+		Name:        model.Name,
+		Description: model.Description,
+		Permissions: model.Permissions,
+	}
+}
@@ -13,20 +13,24 @@ func NewUserMapper() userMapper {
 
 func (mapper userMapper) ToModel(entity entities.User) models.User {
 	return models.User{
-		ID:     entity.ID,
-		Mobile: entity.Mobile,
-		Email:  entity.Email,
-		Name:   entity.Name,
-		Gender: entity.Gender,
+		ID:            entity.ID,
+		Mobile:        entity.Mobile,
+		Email:         entity.Email,
+		EmailVerified: entity.EmailVerified,
+		Name:          entity.Name,
+		Gender:        entity.Gender,
+		Status:        entity.Status,
 	}
 }
 
 func (mapper userMapper) ToEntity(model models.User) entities.User {
 	return entities.User{
-		ID:     model.ID,
-		Mobile: model.Mobile,
-		Email:  model.Email,
-		Name:   model.Name,
-		Gender: model.Gender,
+		ID:            model.ID,
+		Mobile:        model.Mobile,
+		Email:         model.Email,
+		EmailVerified: model.EmailVerified,
+		Name:          model.Name,
+		Gender:        model.Gender,
+		Status:        model.Status,
 	}
 }
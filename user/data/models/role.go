@@ -0,0 +1,49 @@
+package models
+
+import (
+	"time"
+
+	"github.com/devesh2997/consequent/user/data/constants"
+)
+
+type Role struct {
+	ID          int64    `json:"id" gorm:"column:id"`
+	Name        string   `json:"name" gorm:"column:name"`
+	Description string   `json:"description" gorm:"column:description"`
+	Permissions []string `json:"permissions" gorm:"-"`
+}
+
+func (Role) TableName() string {
+	return constants.TABLE_NAME_ROLES
+}
+
+type Permission struct {
+	ID          int64  `gorm:"column:id"`
+	Name        string `gorm:"column:name"`
+	Description string `gorm:"column:description"`
+}
+
+func (Permission) TableName() string {
+	return constants.TABLE_NAME_PERMISSIONS
+}
+
+type RolePermission struct {
+	ID           int64 `gorm:"column:id"`
+	RoleID       int64 `gorm:"column:role_id"`
+	PermissionID int64 `gorm:"column:permission_id"`
+}
+
+func (RolePermission) TableName() string {
+	return constants.TABLE_NAME_ROLE_PERMISSIONS
+}
+
+type UserRole struct {
+	ID        int64     `gorm:"column:id"`
+	UserID    int64     `gorm:"column:user_id"`
+	RoleID    int64     `gorm:"column:role_id"`
+	CreatedAt time.Time `gorm:"column:created_at"`
+}
+
+func (UserRole) TableName() string {
+	return constants.TABLE_NAME_USER_ROLES
+}
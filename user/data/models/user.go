@@ -3,11 +3,13 @@ package models
 import "github.com/devesh2997/consequent/user/data/constants"
 
 type User struct {
-	ID     int64  `json:"id" gorm:"column:id"`
-	Mobile string `json:"mobile" gorm:"column:mobile"`
-	Email  string `json:"email" gorm:"column:email"`
-	Name   string `json:"name" gorm:"column:name"`
-	Gender string `json:"gender" gorm:"column:gender"`
+	ID            int64  `json:"id" gorm:"column:id"`
+	Mobile        string `json:"mobile" gorm:"column:mobile"`
+	Email         string `json:"email" gorm:"column:email"`
+	EmailVerified bool   `json:"email_verified" gorm:"column:email_verified"`
+	Name          string `json:"name" gorm:"column:name"`
+	Gender        string `json:"gender" gorm:"column:gender"`
+	Status        string `json:"status" gorm:"column:status"`
 }
 
 func (user User) TableName() string {
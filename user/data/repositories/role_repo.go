@@ -0,0 +1,124 @@
+package repositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/devesh2997/consequent/user/data/constants"
+	"github.com/devesh2997/consequent/user/data/mappers"
+	"github.com/devesh2997/consequent/user/data/models"
+	"github.com/devesh2997/consequent/user/domain/entities"
+	"github.com/devesh2997/consequent/user/domain/repositories"
+	"gorm.io/gorm"
+)
+
+type roleRepo struct {
+	db *gorm.DB
+}
+
+func NewRoleRepository(db *gorm.DB) repositories.RoleRepository {
+	return roleRepo{db: db}
+}
+
+func (repo roleRepo) FindRoleByName(ctx context.Context, name string) (*entities.Role, error) {
+	roleModel := models.Role{}
+	res := repo.db.Where("name = ?", name).Find(&roleModel)
+	if res.Error != nil && res.Error != gorm.ErrRecordNotFound {
+		return nil, res.Error
+	}
+	if res.Error == gorm.ErrRecordNotFound || res.RowsAffected == 0 {
+		return nil, repositories.ErrRoleNotFound
+	}
+
+	roles, err := repo.withPermissions([]models.Role{roleModel})
+	if err != nil {
+		return nil, err
+	}
+
+	return &roles[0], nil
+}
+
+func (repo roleRepo) GetRoles(ctx context.Context) ([]entities.Role, error) {
+	roleModels := []models.Role{}
+	err := repo.db.Order("name").Find(&roleModels).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return repo.withPermissions(roleModels)
+}
+
+func (repo roleRepo) GetUserRoles(ctx context.Context, userID int64) ([]entities.Role, error) {
+	roleModels := []models.Role{}
+	err := repo.db.Select(constants.TABLE_NAME_ROLES+".*").
+		Joins("JOIN "+constants.TABLE_NAME_USER_ROLES+" ON user_roles.role_id = roles.id").
+		Where("user_roles.user_id = ?", userID).
+		Order("roles.name").
+		Find(&roleModels).Error
+	if err != nil && err != gorm.ErrRecordNotFound {
+		return nil, err
+	}
+
+	return repo.withPermissions(roleModels)
+}
+
+func (repo roleRepo) AddUserRole(ctx context.Context, userID int64, roleID int64) error {
+	userRole := models.UserRole{
+		UserID:    userID,
+		RoleID:    roleID,
+		CreatedAt: time.Now(),
+	}
+	if err := repo.db.Create(&userRole).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo roleRepo) RemoveUserRole(ctx context.Context, userID int64, roleID int64) (bool, error) {
+	res := repo.db.Where("user_id = ? AND role_id = ?", userID, roleID).Delete(&models.UserRole{})
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	return res.RowsAffected == 1, nil
+}
+
+// withPermissions maps the roles to entities along with their permissions, which are read in a single query.
+func (repo roleRepo) withPermissions(roleModels []models.Role) ([]entities.Role, error) {
+	roles := make([]entities.Role, 0, len(roleModels))
+	if len(roleModels) == 0 {
+		return roles, nil
+	}
+
+	roleIDs := make([]int64, 0, len(roleModels))
+	for _, roleModel := range roleModels {
+		roleIDs = append(roleIDs, roleModel.ID)
+	}
+
+	rows := []struct {
+		RoleID int64
+		Name   string
+	}{}
+	err := repo.db.Model(&models.RolePermission{}).
+		Select("role_permissions.role_id, permissions.name").
+		Joins("JOIN "+constants.TABLE_NAME_PERMISSIONS+" ON permissions.id = role_permissions.permission_id").
+		Where("role_permissions.role_id IN ?", roleIDs).
+		Order("permissions.name").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := map[int64][]string{}
+	for _, row := range rows {
+		permissions[row.RoleID] = append(permissions[row.RoleID], row.Name)
+	}
+
+	for _, roleModel := range roleModels {
+		roleModel.Permissions = permissions[roleModel.ID]
+		roles = append(roles, mappers.NewRoleMapper().ToEntity(roleModel))
+	}
+
+	return roles, nil
+}
@@ -36,7 +36,17 @@ func (repo userRepo) Update(ctx context.Context, user entities.User) error {
 	if user.ID == 0 {
 		return errorx.NewSystemError(-1, errors.New("user id is required"))
 	}
-	err := repo.db.Save(user).Error
+	userModel := mappers.NewUserMapper().ToModel(user)
+	err := repo.db.Save(&userModel).Error
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repo userRepo) UpdateStatus(ctx context.Context, id int64, status string) error {
+	err := repo.db.Model(&models.User{}).Where("id = ?", id).Update("status", status).Error
 	if err != nil {
 		return err
 	}
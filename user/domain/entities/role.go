@@ -0,0 +1,37 @@
+package entities
+
+import "sort"
+
+type Role struct {
+	ID          int64
+	Name        string
+	Description string
+	Permissions []string
+}
+
+// RoleNames returns the names of the roles.
+func RoleNames(roles []Role) []string {
+	names := make([]string, 0, len(roles))
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+
+	return names
+}
+
+// RolePermissions returns the permissions granted by any of the roles, sorted and without duplicates.
+func RolePermissions(roles []Role) []string {
+	seen := map[string]bool{}
+	permissions := []string{}
+	for _, role := range roles {
+		for _, permission := range role.Permissions {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	sort.Strings(permissions)
+
+	return permissions
+}
@@ -1,9 +1,22 @@
 package entities
 
+import "github.com/devesh2997/consequent/user/constants"
+
 type User struct {
 	ID     int64
 	Mobile string
 	Email  string
-	Name   string
-	Gender string
+	// EmailVerified is set once the user has proven that they own the email, e.g. with an emailed code.
+	EmailVerified bool
+	Name          string
+	Gender        string
+	Status        string
+}
+
+func (user User) IsSuspended() bool {
+	return user.Status == constants.USER_STATUS_SUSPENDED
+}
+
+func (user User) IsMerged() bool {
+	return user.Status == constants.USER_STATUS_MERGED
 }
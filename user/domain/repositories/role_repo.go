@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/devesh2997/consequent/user/domain/entities"
+)
+
+var (
+	ErrRoleNotFound = errors.New("role not found")
+)
+
+type RoleRepository interface {
+	FindRoleByName(ctx context.Context, name string) (*entities.Role, error)
+	GetRoles(ctx context.Context) ([]entities.Role, error)
+	GetUserRoles(ctx context.Context, userID int64) ([]entities.Role, error)
+	AddUserRole(ctx context.Context, userID int64, roleID int64) error
+	// RemoveUserRole reports whether the user had the role.
+	RemoveUserRole(ctx context.Context, userID int64, roleID int64) (bool, error)
+}
@@ -17,4 +17,5 @@ type UserRepository interface {
 	FindByID(ctx context.Context, id int64) (*entities.User, error)
 	FindByMobile(ctx context.Context, mobile string) (*entities.User, error)
 	FindByEmail(ctx context.Context, email string) (*entities.User, error)
+	UpdateStatus(ctx context.Context, id int64, status string) error
 }
@@ -5,3 +5,11 @@ import "github.com/devesh2997/consequent/errorx"
 var errUserNotFound = func() error {
 	return errorx.NewNotFoundError(-1, "user", "sql")
 }
+
+var errRoleNotFound = func() error {
+	return errorx.NewNotFoundError(-1, "role", "sql")
+}
+
+var errUserRoleNotFound = func() error {
+	return errorx.NewNotFoundError(-1, "user role", "sql")
+}
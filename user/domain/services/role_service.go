@@ -0,0 +1,91 @@
+package services
+
+import (
+	"context"
+
+	"github.com/devesh2997/consequent/user/domain/entities"
+	"github.com/devesh2997/consequent/user/domain/repositories"
+)
+
+// RoleService manages the roles of users. Roles are carried by access tokens, so a grant or a revocation takes
+// effect when the tokens of the user are next refreshed.
+type RoleService interface {
+	GetRoles(ctx context.Context) ([]entities.Role, error)
+	GetUserRoles(ctx context.Context, userID int64) ([]entities.Role, error)
+	// GrantRole grants the role to the user. Granting a role that the user already has does nothing.
+	GrantRole(ctx context.Context, userID int64, roleName string) error
+	RevokeRole(ctx context.Context, userID int64, roleName string) error
+}
+
+func NewRoleService(repo repositories.RoleRepository, userRepo repositories.UserRepository) RoleService {
+	return roleService{repo: repo, userRepo: userRepo}
+}
+
+type roleService struct {
+	repo     repositories.RoleRepository
+	userRepo repositories.UserRepository
+}
+
+func (service roleService) GetRoles(ctx context.Context) ([]entities.Role, error) {
+	return service.repo.GetRoles(ctx)
+}
+
+func (service roleService) GetUserRoles(ctx context.Context, userID int64) ([]entities.Role, error) {
+	return service.repo.GetUserRoles(ctx, userID)
+}
+
+func (service roleService) GrantRole(ctx context.Context, userID int64, roleName string) error {
+	existingUser, err := service.userRepo.FindByID(ctx, userID)
+	if err != nil && err != repositories.ErrUserNotFound {
+		return err
+	}
+	if existingUser == nil {
+		return errUserNotFound()
+	}
+
+	role, err := service.findRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	userRoles, err := service.repo.GetUserRoles(ctx, userID)
+	if err != nil {
+		return err
+	}
+	for _, userRole := range userRoles {
+		if userRole.ID == role.ID {
+			return nil
+		}
+	}
+
+	return service.repo.AddUserRole(ctx, userID, role.ID)
+}
+
+func (service roleService) RevokeRole(ctx context.Context, userID int64, roleName string) error {
+	role, err := service.findRole(ctx, roleName)
+	if err != nil {
+		return err
+	}
+
+	removed, err := service.repo.RemoveUserRole(ctx, userID, role.ID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return errUserRoleNotFound()
+	}
+
+	return nil
+}
+
+func (service roleService) findRole(ctx context.Context, name string) (*entities.Role, error) {
+	role, err := service.repo.FindRoleByName(ctx, name)
+	if err != nil && err != repositories.ErrRoleNotFound {
+		return nil, err
+	}
+	if role == nil {
+		return nil, errRoleNotFound()
+	}
+
+	return role, nil
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"context"
 
+	"github.com/devesh2997/consequent/user/constants"
 	"github.com/devesh2997/consequent/user/domain/entities"
 	"github.com/devesh2997/consequent/user/domain/repositories"
 )
@@ -13,6 +14,7 @@ type UserService interface {
 	FindByID(ctx context.Context, id int64) (*entities.User, error)
 	FindByMobile(ctx context.Context, mobile string) (*entities.User, error)
 	FindByEmail(ctx context.Context, email string) (*entities.User, error)
+	Suspend(ctx context.Context, id int64) error
 }
 
 func NewUserService(repo repositories.UserRepository) UserService {
@@ -24,6 +26,10 @@ type userService struct {
 }
 
 func (service userService) Create(ctx context.Context, user entities.User) (*entities.User, error) {
+	if user.Status == "" {
+		user.Status = constants.USER_STATUS_ACTIVE
+	}
+
 	return service.repo.Create(ctx, user)
 }
 
@@ -50,3 +56,15 @@ func (service userService) FindByMobile(ctx context.Context, mobile string) (*en
 func (service userService) FindByEmail(ctx context.Context, email string) (*entities.User, error) {
 	return service.repo.FindByEmail(ctx, email)
 }
+
+func (service userService) Suspend(ctx context.Context, id int64) error {
+	existingUser, err := service.repo.FindByID(ctx, id)
+	if err != nil && err != repositories.ErrUserNotFound {
+		return err
+	}
+	if existingUser == nil {
+		return errUserNotFound()
+	}
+
+	return service.repo.UpdateStatus(ctx, id, constants.USER_STATUS_SUSPENDED)
+}
@@ -0,0 +1,105 @@
+package controllers
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/devesh2997/consequent/app/controller"
+	"github.com/devesh2997/consequent/user/data/mappers"
+	"github.com/devesh2997/consequent/user/data/models"
+	"github.com/devesh2997/consequent/user/domain/entities"
+	"github.com/devesh2997/consequent/user/domain/services"
+	"github.com/gin-gonic/gin"
+)
+
+var errInvalidUserID = errors.New("invalid user id")
+
+// RoleController serves the admin apis that manage the roles of users.
+type RoleController interface {
+	GetRoles(gCtx *gin.Context)
+	GetUserRoles(gCtx *gin.Context)
+	GrantRole(gCtx *gin.Context)
+	RevokeRole(gCtx *gin.Context)
+}
+
+func NewRoleController(service services.RoleService) RoleController {
+	return roleController{service: service}
+}
+
+type roleController struct {
+	controller.Controller
+	service services.RoleService
+}
+
+func (c roleController) GetRoles(gCtx *gin.Context) {
+	roles, err := c.service.GetRoles(gCtx.Request.Context())
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, toRoleModels(roles))
+}
+
+func (c roleController) GetUserRoles(gCtx *gin.Context) {
+	userID, err := strconv.ParseInt(gCtx.Param("user_id"), 10, 64)
+	if err != nil {
+		c.SendBadRequestError(gCtx, errInvalidUserID)
+		return
+	}
+
+	roles, err := c.service.GetUserRoles(gCtx.Request.Context(), userID)
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.Send(gCtx, toRoleModels(roles))
+}
+
+func (c roleController) GrantRole(gCtx *gin.Context) {
+	input := struct {
+		Role string `json:"role" form:"role" binding:"required"`
+	}{}
+
+	userID, err := strconv.ParseInt(gCtx.Param("user_id"), 10, 64)
+	if err != nil {
+		c.SendBadRequestError(gCtx, errInvalidUserID)
+		return
+	}
+	if err := gCtx.ShouldBind(&input); err != nil {
+		c.SendBadRequestError(gCtx, err)
+		return
+	}
+
+	if err := c.service.GrantRole(gCtx.Request.Context(), userID, input.Role); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func (c roleController) RevokeRole(gCtx *gin.Context) {
+	userID, err := strconv.ParseInt(gCtx.Param("user_id"), 10, 64)
+	if err != nil {
+		c.SendBadRequestError(gCtx, errInvalidUserID)
+		return
+	}
+
+	if err := c.service.RevokeRole(gCtx.Request.Context(), userID, gCtx.Param("role")); err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	c.SendSuccess(gCtx)
+}
+
+func toRoleModels(roles []entities.Role) []models.Role {
+	roleModels := make([]models.Role, 0, len(roles))
+	for _, role := range roles {
+		roleModels = append(roleModels, mappers.NewRoleMapper().ToModel(role))
+	}
+
+	return roleModels
+}
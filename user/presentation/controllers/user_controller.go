@@ -1,15 +1,21 @@
 package controllers
 
 import (
+	"net/http"
+	"strconv"
+
 	"github.com/devesh2997/consequent/app/controller"
 	"github.com/devesh2997/consequent/contextx"
 	"github.com/devesh2997/consequent/user/data/mappers"
+	"github.com/devesh2997/consequent/user/domain/repositories"
 	"github.com/devesh2997/consequent/user/domain/services"
 	"github.com/gin-gonic/gin"
 )
 
 type UserController interface {
 	GetUser(gCtx *gin.Context)
+	// GetUserByID is the admin api to look up any user.
+	GetUserByID(gCtx *gin.Context)
 }
 
 func NewUserController(service services.UserService) UserController {
@@ -36,3 +42,25 @@ func (c userController) GetUser(gCtx *gin.Context) {
 
 	c.Send(gCtx, userModel)
 }
+
+func (c userController) GetUserByID(gCtx *gin.Context) {
+	userID, err := strconv.ParseInt(gCtx.Param("user_id"), 10, 64)
+	if err != nil {
+		c.SendBadRequestError(gCtx, errInvalidUserID)
+		return
+	}
+
+	user, err := c.service.FindByID(gCtx.Request.Context(), userID)
+	if err == repositories.ErrUserNotFound {
+		c.SendWithHTTPStatusCodeAndError(gCtx, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		c.SendWithError(gCtx, err)
+		return
+	}
+
+	userModel := mappers.NewUserMapper().ToModel(*user)
+
+	c.Send(gCtx, userModel)
+}
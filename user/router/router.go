@@ -3,6 +3,7 @@ package router
 import (
 	"github.com/devesh2997/consequent/app/middleware"
 	identityContainers "github.com/devesh2997/consequent/identity/containers"
+	"github.com/devesh2997/consequent/user/constants"
 	"github.com/devesh2997/consequent/user/containers"
 	"github.com/gin-gonic/gin"
 )
@@ -13,11 +14,97 @@ func InjectUserRoutes(router *gin.RouterGroup) {
 
 func setupV1Routes(r *gin.RouterGroup) {
 	tokenService := identityContainers.InjectTokenService()
+	apiKeyService := identityContainers.InjectAPIKeyService()
 	userController := containers.InjectUserController()
+	roleController := containers.InjectRoleController()
+	identityController := identityContainers.InjectIdentityController()
+	apiKeyController := identityContainers.InjectAPIKeyController()
+	accountController := identityContainers.InjectAccountController()
 
 	v1 := r.Group("/v1")
-	v1.Use(middleware.Authorisation(tokenService))
-	v1.GET("user", func(c *gin.Context) {
+	v1.Use(middleware.Authorisation(tokenService, apiKeyService), middleware.RequireUser())
+
+	// the apis that api keys can be used for, within the scope of the key.
+	v1.GET("user", middleware.RequireScope(constants.SCOPE_USER_READ), func(c *gin.Context) {
 		userController.GetUser(c)
 	})
+	v1.GET("sessions", middleware.RequireScope(constants.SCOPE_SESSIONS_READ), func(c *gin.Context) {
+		identityController.GetSessions(c)
+	})
+	v1.GET("api-keys", middleware.RequireScope(constants.SCOPE_API_KEYS_READ), func(c *gin.Context) {
+		apiKeyController.GetAPIKeys(c)
+	})
+	v1.GET("account/deletion", middleware.RequireScope(constants.SCOPE_ACCOUNT_READ), func(c *gin.Context) {
+		accountController.GetDeletionRequest(c)
+	})
+
+	// the apis that manage the account and its credentials, which only the user themselves may use.
+	account := v1.Group("", middleware.RequireAccessToken())
+	account.DELETE("sessions/:session_id", func(c *gin.Context) {
+		identityController.RevokeSession(c)
+	})
+	account.POST("api-keys", func(c *gin.Context) {
+		apiKeyController.CreateAPIKey(c)
+	})
+	account.DELETE("api-keys/:api_key_id", func(c *gin.Context) {
+		apiKeyController.RevokeAPIKey(c)
+	})
+	account.POST("password", func(c *gin.Context) {
+		identityController.ChangePassword(c)
+	})
+	account.POST("email/send-code", func(c *gin.Context) {
+		identityController.SendEmailLinkCode(c)
+	})
+	account.POST("email", func(c *gin.Context) {
+		identityController.LinkEmail(c)
+	})
+	account.POST("mobile", func(c *gin.Context) {
+		identityController.LinkMobile(c)
+	})
+	account.POST("external-identities", func(c *gin.Context) {
+		identityController.LinkExternalIdentity(c)
+	})
+	account.POST("totp", func(c *gin.Context) {
+		identityController.EnrollTOTP(c)
+	})
+	account.POST("totp/confirm", func(c *gin.Context) {
+		identityController.ConfirmTOTP(c)
+	})
+	account.POST("recovery-codes", func(c *gin.Context) {
+		identityController.RegenerateRecoveryCodes(c)
+	})
+	// the export holds the personal data of the user, which a leaked key must not be able to read in one go.
+	account.GET("account/export", func(c *gin.Context) {
+		accountController.Export(c)
+	})
+	account.POST("account/deletion", func(c *gin.Context) {
+		accountController.RequestDeletion(c)
+	})
+	account.DELETE("account/deletion", func(c *gin.Context) {
+		accountController.CancelDeletion(c)
+	})
+
+	admin := r.Group("/v1/admin")
+	admin.Use(middleware.Authorisation(tokenService, apiKeyService), middleware.RequireAccessToken())
+
+	userReaders := admin.Group("", middleware.RequirePermission(constants.PERMISSION_USERS_READ))
+	userReaders.GET("users/:user_id", func(c *gin.Context) {
+		userController.GetUserByID(c)
+	})
+
+	roleReaders := admin.Group("", middleware.RequirePermission(constants.PERMISSION_ROLES_READ))
+	roleReaders.GET("roles", func(c *gin.Context) {
+		roleController.GetRoles(c)
+	})
+	roleReaders.GET("users/:user_id/roles", func(c *gin.Context) {
+		roleController.GetUserRoles(c)
+	})
+
+	roleWriters := admin.Group("", middleware.RequirePermission(constants.PERMISSION_ROLES_WRITE))
+	roleWriters.POST("users/:user_id/roles", func(c *gin.Context) {
+		roleController.GrantRole(c)
+	})
+	roleWriters.DELETE("users/:user_id/roles/:role", func(c *gin.Context) {
+		roleController.RevokeRole(c)
+	})
 }
@@ -0,0 +1,105 @@
+package webauthn
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+)
+
+const (
+	AttestationFormatNone   = "none"
+	AttestationFormatPacked = "packed"
+)
+
+var (
+	ErrUnsupportedAttestationFormat = errors.New("webauthn: unsupported attestation format")
+	ErrInvalidAttestation           = errors.New("webauthn: invalid attestation statement")
+)
+
+// oidFIDOGenCeAAGUID is the certificate extension holding the aaguid of the authenticator.
+var oidFIDOGenCeAAGUID = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 45724, 1, 1, 4}
+
+// verifyAttestationStatement checks the attestation statement of the given format (WebAuthn Level 2 section 8).
+// Attestation certificates are checked for the requirements of the packed format, but not against trust anchors, so
+// the attestation only proves that the authenticator holds the credential key.
+func verifyAttestationStatement(format string, statement map[interface{}]interface{}, authData []byte, parsedAuthData authenticatorData, credentialKey publicKey, clientDataHash []byte) error {
+	switch format {
+	case AttestationFormatNone:
+		if len(statement) != 0 {
+			return fmt.Errorf("%w: none attestation with a statement", ErrInvalidAttestation)
+		}
+		return nil
+	case AttestationFormatPacked:
+		return verifyPackedAttestation(statement, authData, parsedAuthData, credentialKey, clientDataHash)
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnsupportedAttestationFormat, format)
+}
+
+func verifyPackedAttestation(statement map[interface{}]interface{}, authData []byte, parsedAuthData authenticatorData, credentialKey publicKey, clientDataHash []byte) error {
+	algorithm, ok := statement["alg"].(int64)
+	if !ok {
+		return fmt.Errorf("%w: alg not found", ErrInvalidAttestation)
+	}
+	signature, ok := statement["sig"].([]byte)
+	if !ok {
+		return fmt.Errorf("%w: sig not found", ErrInvalidAttestation)
+	}
+	signedData := append(append([]byte{}, authData...), clientDataHash...)
+
+	x5c, hasX5C := statement["x5c"].([]interface{})
+	if !hasX5C {
+		// self attestation is signed by the credential key itself.
+		if algorithm != credentialKey.algorithm {
+			return fmt.Errorf("%w: alg does not match the credential key", ErrInvalidAttestation)
+		}
+		if !credentialKey.verify(signedData, signature) {
+			return fmt.Errorf("%w: invalid signature", ErrInvalidAttestation)
+		}
+		return nil
+	}
+
+	if len(x5c) == 0 {
+		return fmt.Errorf("%w: empty x5c", ErrInvalidAttestation)
+	}
+	der, ok := x5c[0].([]byte)
+	if !ok {
+		return fmt.Errorf("%w: invalid x5c", ErrInvalidAttestation)
+	}
+	certificate, err := x509.ParseCertificate(der)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidAttestation, err)
+	}
+	if !verifySignature(algorithm, certificate.PublicKey, signedData, signature) {
+		return fmt.Errorf("%w: invalid signature", ErrInvalidAttestation)
+	}
+
+	// certificate requirements of section 8.2.1.
+	if certificate.Version != 3 {
+		return fmt.Errorf("%w: attestation certificate must be version 3", ErrInvalidAttestation)
+	}
+	subject := certificate.Subject
+	if len(subject.Country) == 0 || len(subject.Organization) == 0 || subject.CommonName == "" ||
+		len(subject.OrganizationalUnit) != 1 || subject.OrganizationalUnit[0] != "Authenticator Attestation" {
+		return fmt.Errorf("%w: invalid attestation certificate subject", ErrInvalidAttestation)
+	}
+	if certificate.IsCA {
+		return fmt.Errorf("%w: attestation certificate must not be a ca", ErrInvalidAttestation)
+	}
+	for _, extension := range certificate.Extensions {
+		if !extension.Id.Equal(oidFIDOGenCeAAGUID) {
+			continue
+		}
+		if extension.Critical {
+			return fmt.Errorf("%w: aaguid extension must not be critical", ErrInvalidAttestation)
+		}
+		var aaguid []byte
+		if _, err := asn1.Unmarshal(extension.Value, &aaguid); err != nil || !bytes.Equal(aaguid, parsedAuthData.aaguid) {
+			return fmt.Errorf("%w: aaguid does not match the certificate", ErrInvalidAttestation)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// authenticator data flags (WebAuthn Level 2 section 6.1).
+const (
+	flagUserPresent            = 0x01
+	flagUserVerified           = 0x04
+	flagAttestedCredentialData = 0x40
+	flagExtensionData          = 0x80
+)
+
+var errInvalidAuthenticatorData = errors.New("webauthn: invalid authenticator data")
+
+type authenticatorData struct {
+	rpIDHash  []byte
+	flags     byte
+	signCount uint32
+	// set when the attested credential data flag is set
+	aaguid       []byte
+	credentialID []byte
+	publicKey    []byte
+}
+
+func (data authenticatorData) userPresent() bool {
+	return data.flags&flagUserPresent != 0
+}
+
+func (data authenticatorData) userVerified() bool {
+	return data.flags&flagUserVerified != 0
+}
+
+func parseAuthenticatorData(data []byte) (authenticatorData, error) {
+	if len(data) < 37 {
+		return authenticatorData{}, errInvalidAuthenticatorData
+	}
+
+	parsed := authenticatorData{
+		rpIDHash:  data[:32],
+		flags:     data[32],
+		signCount: binary.BigEndian.Uint32(data[33:37]),
+	}
+	rest := data[37:]
+
+	if parsed.flags&flagAttestedCredentialData != 0 {
+		if len(rest) < 18 {
+			return authenticatorData{}, errInvalidAuthenticatorData
+		}
+		parsed.aaguid = rest[:16]
+		credentialIDLength := int(binary.BigEndian.Uint16(rest[16:18]))
+		rest = rest[18:]
+		if credentialIDLength > 1023 || len(rest) < credentialIDLength {
+			return authenticatorData{}, errInvalidAuthenticatorData
+		}
+		parsed.credentialID = rest[:credentialIDLength]
+		rest = rest[credentialIDLength:]
+
+		// the credential public key is followed by the extensions, so its length is only known by decoding it.
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return authenticatorData{}, errInvalidAuthenticatorData
+		}
+		parsed.publicKey = rest[:n]
+		rest = rest[n:]
+	}
+
+	if parsed.flags&flagExtensionData != 0 {
+		_, n, err := decodeCBOR(rest)
+		if err != nil {
+			return authenticatorData{}, errInvalidAuthenticatorData
+		}
+		rest = rest[n:]
+	}
+	if len(rest) != 0 {
+		return authenticatorData{}, errInvalidAuthenticatorData
+	}
+
+	return parsed, nil
+}
@@ -0,0 +1,169 @@
+package webauthn
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// maxCBORDepth limits the nesting of decoded values, attestation objects and cose keys are only a few levels deep.
+const maxCBORDepth = 8
+
+var errInvalidCBOR = errors.New("webauthn: invalid cbor")
+
+// decodeCBOR decodes the first cbor value in data, and returns it along with the number of bytes it took. Only the
+// subset of cbor (RFC 8949) that webauthn uses is supported: integers, byte and text strings, arrays, maps, booleans
+// and null. Integers are returned as int64, maps as map[interface{}]interface{} keyed by int64 or string.
+func decodeCBOR(data []byte) (interface{}, int, error) {
+	d := cborDecoder{data: data}
+	value, err := d.decode(0)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return value, d.offset, nil
+}
+
+type cborDecoder struct {
+	data   []byte
+	offset int
+}
+
+func (d *cborDecoder) decode(depth int) (interface{}, error) {
+	if depth > maxCBORDepth {
+		return nil, errInvalidCBOR
+	}
+
+	majorType, argument, err := d.readHead()
+	if err != nil {
+		return nil, err
+	}
+
+	switch majorType {
+	case 0:
+		if argument > 1<<63-1 {
+			return nil, errInvalidCBOR
+		}
+		return int64(argument), nil
+	case 1:
+		if argument > 1<<63-1 {
+			return nil, errInvalidCBOR
+		}
+		return -1 - int64(argument), nil
+	case 2, 3:
+		b, err := d.readBytes(argument)
+		if err != nil {
+			return nil, err
+		}
+		if majorType == 3 {
+			return string(b), nil
+		}
+		return b, nil
+	case 4:
+		if argument > uint64(len(d.data)) {
+			return nil, errInvalidCBOR
+		}
+		values := make([]interface{}, 0, argument)
+		for i := uint64(0); i < argument; i++ {
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, value)
+		}
+		return values, nil
+	case 5:
+		if argument > uint64(len(d.data)) {
+			return nil, errInvalidCBOR
+		}
+		values := make(map[interface{}]interface{}, argument)
+		for i := uint64(0); i < argument; i++ {
+			key, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			switch key.(type) {
+			case int64, string:
+			default:
+				return nil, fmt.Errorf("%w: unsupported map key", errInvalidCBOR)
+			}
+			if _, ok := values[key]; ok {
+				return nil, fmt.Errorf("%w: duplicate map key", errInvalidCBOR)
+			}
+			value, err := d.decode(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			values[key] = value
+		}
+		return values, nil
+	case 7:
+		switch argument {
+		case 20:
+			return false, nil
+		case 21:
+			return true, nil
+		case 22:
+			return nil, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unsupported major type %d", errInvalidCBOR, majorType)
+}
+
+// readHead reads the initial byte of a data item and its argument. Indefinite lengths are not supported.
+func (d *cborDecoder) readHead() (byte, uint64, error) {
+	if d.offset >= len(d.data) {
+		return 0, 0, errInvalidCBOR
+	}
+	initial := d.data[d.offset]
+	d.offset++
+
+	majorType := initial >> 5
+	info := initial & 0x1f
+	if info < 24 {
+		return majorType, uint64(info), nil
+	}
+
+	var size int
+	switch info {
+	case 24:
+		size = 1
+	case 25:
+		size = 2
+	case 26:
+		size = 4
+	case 27:
+		size = 8
+	default:
+		return 0, 0, errInvalidCBOR
+	}
+	b, err := d.readBytes(uint64(size))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var argument uint64
+	switch size {
+	case 1:
+		argument = uint64(b[0])
+	case 2:
+		argument = uint64(binary.BigEndian.Uint16(b))
+	case 4:
+		argument = uint64(binary.BigEndian.Uint32(b))
+	case 8:
+		argument = binary.BigEndian.Uint64(b)
+	}
+
+	return majorType, argument, nil
+}
+
+func (d *cborDecoder) readBytes(n uint64) ([]byte, error) {
+	if n > uint64(len(d.data)-d.offset) {
+		return nil, errInvalidCBOR
+	}
+	b := d.data[d.offset : d.offset+int(n)]
+	d.offset += int(n)
+
+	return b, nil
+}
@@ -0,0 +1,126 @@
+package webauthn
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// COSE algorithm identifiers (RFC 9053) of the supported credential keys.
+const (
+	AlgorithmES256 int64 = -7
+	AlgorithmEdDSA int64 = -8
+	AlgorithmRS256 int64 = -257
+)
+
+// cose key parameters (RFC 9052 section 7 and RFC 9053 section 7).
+const (
+	coseKeyType      = 1
+	coseKeyAlgorithm = 3
+	coseKeyCurve     = -1
+	coseKeyX         = -2
+	coseKeyY         = -3
+	coseKeyRSAN      = -1
+	coseKeyRSAE      = -2
+
+	coseKeyTypeOKP = 1
+	coseKeyTypeEC2 = 2
+	coseKeyTypeRSA = 3
+
+	coseCurveP256    = 1
+	coseCurveEd25519 = 6
+)
+
+var ErrUnsupportedAlgorithm = errors.New("webauthn: unsupported credential algorithm")
+
+// publicKey is a parsed cose key.
+type publicKey struct {
+	algorithm int64
+	key       crypto.PublicKey
+}
+
+func parseCOSEKey(data []byte) (publicKey, error) {
+	value, n, err := decodeCBOR(data)
+	if err != nil {
+		return publicKey{}, err
+	}
+	if n != len(data) {
+		return publicKey{}, errInvalidCBOR
+	}
+	params, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return publicKey{}, errInvalidCBOR
+	}
+
+	keyType, _ := params[int64(coseKeyType)].(int64)
+	algorithm, _ := params[int64(coseKeyAlgorithm)].(int64)
+
+	switch {
+	case keyType == coseKeyTypeEC2 && algorithm == AlgorithmES256:
+		curve, _ := params[int64(coseKeyCurve)].(int64)
+		x, _ := params[int64(coseKeyX)].([]byte)
+		y, _ := params[int64(coseKeyY)].([]byte)
+		if curve != coseCurveP256 || len(x) != 32 || len(y) != 32 {
+			return publicKey{}, fmt.Errorf("%w: invalid ec2 key", ErrUnsupportedAlgorithm)
+		}
+		key := &ecdsa.PublicKey{Curve: elliptic.P256(), X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !key.Curve.IsOnCurve(key.X, key.Y) {
+			return publicKey{}, fmt.Errorf("%w: point is not on the curve", ErrUnsupportedAlgorithm)
+		}
+		return publicKey{algorithm: algorithm, key: key}, nil
+	case keyType == coseKeyTypeOKP && algorithm == AlgorithmEdDSA:
+		curve, _ := params[int64(coseKeyCurve)].(int64)
+		x, _ := params[int64(coseKeyX)].([]byte)
+		if curve != coseCurveEd25519 || len(x) != ed25519.PublicKeySize {
+			return publicKey{}, fmt.Errorf("%w: invalid okp key", ErrUnsupportedAlgorithm)
+		}
+		return publicKey{algorithm: algorithm, key: ed25519.PublicKey(x)}, nil
+	case keyType == coseKeyTypeRSA && algorithm == AlgorithmRS256:
+		n, _ := params[int64(coseKeyRSAN)].([]byte)
+		e, _ := params[int64(coseKeyRSAE)].([]byte)
+		if len(n) < 256 || len(e) == 0 || len(e) > 4 {
+			return publicKey{}, fmt.Errorf("%w: invalid rsa key", ErrUnsupportedAlgorithm)
+		}
+		return publicKey{algorithm: algorithm, key: &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}}, nil
+	}
+
+	return publicKey{}, fmt.Errorf("%w: key type %d, algorithm %d", ErrUnsupportedAlgorithm, keyType, algorithm)
+}
+
+// verify checks a webauthn signature, which for ES256 is asn.1 encoded.
+func (key publicKey) verify(message []byte, signature []byte) bool {
+	return verifySignature(key.algorithm, key.key, message, signature)
+}
+
+func verifySignature(algorithm int64, key crypto.PublicKey, message []byte, signature []byte) bool {
+	switch algorithm {
+	case AlgorithmES256:
+		ecKey, ok := key.(*ecdsa.PublicKey)
+		if !ok {
+			return false
+		}
+		digest := sha256.Sum256(message)
+		return ecdsa.VerifyASN1(ecKey, digest[:], signature)
+	case AlgorithmEdDSA:
+		edKey, ok := key.(ed25519.PublicKey)
+		if !ok {
+			return false
+		}
+		return ed25519.Verify(edKey, message, signature)
+	case AlgorithmRS256:
+		rsaKey, ok := key.(*rsa.PublicKey)
+		if !ok {
+			return false
+		}
+		digest := sha256.Sum256(message)
+		return rsa.VerifyPKCS1v15(rsaKey, crypto.SHA256, digest[:], signature) == nil
+	}
+
+	return false
+}
@@ -0,0 +1,237 @@
+// Package webauthn implements the relying party side of the WebAuthn registration and authentication ceremonies
+// (WebAuthn Level 2) that passkeys are created and used with. Attestations of the none and packed formats are
+// verified.
+package webauthn
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	// Timeout is the time that users are given to complete a ceremony.
+	Timeout        = time.Minute * 5
+	challengeBytes = 32
+
+	clientDataTypeCreate = "webauthn.create"
+	clientDataTypeGet    = "webauthn.get"
+)
+
+var (
+	ErrInvalidClientData     = errors.New("webauthn: invalid client data")
+	ErrInvalidResponse       = errors.New("webauthn: invalid authenticator response")
+	ErrUserNotVerified       = errors.New("webauthn: user was not verified by the authenticator")
+	ErrInvalidSignature      = errors.New("webauthn: invalid signature")
+	ErrSignCountNotIncreased = errors.New("webauthn: sign count did not increase, the authenticator may have been cloned")
+)
+
+// RelyingParty is the site that credentials are scoped to. Origins are the origins that ceremonies are accepted
+// from, such as https://example.com or the android:apk-key-hash origins of our apps.
+type RelyingParty struct {
+	ID      string
+	Name    string
+	Origins []string
+}
+
+// Credential is a public key credential created by an authenticator during registration.
+type Credential struct {
+	ID                []byte
+	PublicKey         []byte
+	SignCount         uint32
+	AAGUID            []byte
+	AttestationFormat string
+}
+
+// GenerateChallenge returns a random challenge for a ceremony.
+func GenerateChallenge() ([]byte, error) {
+	challenge := make([]byte, challengeBytes)
+	if _, err := rand.Read(challenge); err != nil {
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+// CreationOptions returns the options that navigator.credentials.create is called with. User verification is
+// required, as passkeys replace both the password and the second factor.
+func (rp RelyingParty) CreationOptions(challenge []byte, userHandle []byte, userName string, userDisplayName string, excludeCredentialIDs [][]byte) CreationOptions {
+	excludeCredentials := []CredentialDescriptor{}
+	for _, credentialID := range excludeCredentialIDs {
+		excludeCredentials = append(excludeCredentials, CredentialDescriptor{Type: "public-key", ID: encode(credentialID)})
+	}
+
+	return CreationOptions{
+		RP:        RelyingPartyEntity{ID: rp.ID, Name: rp.Name},
+		User:      UserEntity{ID: encode(userHandle), Name: userName, DisplayName: userDisplayName},
+		Challenge: encode(challenge),
+		PubKeyCredParams: []CredentialParameter{
+			{Type: "public-key", Alg: AlgorithmES256},
+			{Type: "public-key", Alg: AlgorithmEdDSA},
+			{Type: "public-key", Alg: AlgorithmRS256},
+		},
+		Timeout:            Timeout.Milliseconds(),
+		ExcludeCredentials: excludeCredentials,
+		AuthenticatorSelection: AuthenticatorSelection{
+			ResidentKey:      "required",
+			UserVerification: "required",
+		},
+		Attestation: "none",
+	}
+}
+
+// RequestOptions returns the options that navigator.credentials.get is called with. No credentials are listed, so
+// that the user picks any of the passkeys that they have for the relying party.
+func (rp RelyingParty) RequestOptions(challenge []byte) RequestOptions {
+	return RequestOptions{
+		Challenge:        encode(challenge),
+		Timeout:          Timeout.Milliseconds(),
+		RPID:             rp.ID,
+		AllowCredentials: []CredentialDescriptor{},
+		UserVerification: "required",
+	}
+}
+
+// VerifyRegistration verifies the response of an authenticator to a registration ceremony (WebAuthn Level 2 section
+// 7.1) and returns the created credential.
+func (rp RelyingParty) VerifyRegistration(challenge []byte, clientDataJSON []byte, attestationObject []byte) (*Credential, error) {
+	if err := rp.verifyClientData(clientDataJSON, clientDataTypeCreate, challenge); err != nil {
+		return nil, err
+	}
+
+	value, n, err := decodeCBOR(attestationObject)
+	if err != nil || n != len(attestationObject) {
+		return nil, fmt.Errorf("%w: invalid attestation object", ErrInvalidResponse)
+	}
+	object, ok := value.(map[interface{}]interface{})
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid attestation object", ErrInvalidResponse)
+	}
+	format, _ := object["fmt"].(string)
+	statement, _ := object["attStmt"].(map[interface{}]interface{})
+	authData, _ := object["authData"].([]byte)
+	if format == "" || statement == nil || authData == nil {
+		return nil, fmt.Errorf("%w: invalid attestation object", ErrInvalidResponse)
+	}
+
+	parsedAuthData, err := parseAuthenticatorData(authData)
+	if err != nil {
+		return nil, err
+	}
+	if err := rp.verifyAuthenticatorData(parsedAuthData); err != nil {
+		return nil, err
+	}
+	if parsedAuthData.credentialID == nil {
+		return nil, fmt.Errorf("%w: attested credential data not found", ErrInvalidResponse)
+	}
+
+	credentialKey, err := parseCOSEKey(parsedAuthData.publicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	if err := verifyAttestationStatement(format, statement, authData, parsedAuthData, credentialKey, clientDataHash[:]); err != nil {
+		return nil, err
+	}
+
+	return &Credential{
+		ID:                parsedAuthData.credentialID,
+		PublicKey:         parsedAuthData.publicKey,
+		SignCount:         parsedAuthData.signCount,
+		AAGUID:            parsedAuthData.aaguid,
+		AttestationFormat: format,
+	}, nil
+}
+
+// VerifyAssertion verifies the response of an authenticator to an authentication ceremony (WebAuthn Level 2 section
+// 7.2) with the stored public key and sign count of the credential, and returns the new sign count.
+func (rp RelyingParty) VerifyAssertion(challenge []byte, credentialPublicKey []byte, storedSignCount uint32, clientDataJSON []byte, authData []byte, signature []byte) (uint32, error) {
+	if err := rp.verifyClientData(clientDataJSON, clientDataTypeGet, challenge); err != nil {
+		return 0, err
+	}
+
+	parsedAuthData, err := parseAuthenticatorData(authData)
+	if err != nil {
+		return 0, err
+	}
+	if err := rp.verifyAuthenticatorData(parsedAuthData); err != nil {
+		return 0, err
+	}
+
+	credentialKey, err := parseCOSEKey(credentialPublicKey)
+	if err != nil {
+		return 0, err
+	}
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	if !credentialKey.verify(append(append([]byte{}, authData...), clientDataHash[:]...), signature) {
+		return 0, ErrInvalidSignature
+	}
+
+	// authenticators that do not count signatures always report 0.
+	if (parsedAuthData.signCount != 0 || storedSignCount != 0) && parsedAuthData.signCount <= storedSignCount {
+		return 0, ErrSignCountNotIncreased
+	}
+
+	return parsedAuthData.signCount, nil
+}
+
+type clientData struct {
+	Type      string `json:"type"`
+	Challenge string `json:"challenge"`
+	Origin    string `json:"origin"`
+}
+
+func (rp RelyingParty) verifyClientData(clientDataJSON []byte, ceremonyType string, challenge []byte) error {
+	data := clientData{}
+	if err := json.Unmarshal(clientDataJSON, &data); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidClientData, err)
+	}
+	if data.Type != ceremonyType {
+		return fmt.Errorf("%w: unexpected type %s", ErrInvalidClientData, data.Type)
+	}
+	receivedChallenge, err := base64.RawURLEncoding.DecodeString(data.Challenge)
+	if err != nil || subtle.ConstantTimeCompare(receivedChallenge, challenge) != 1 {
+		return fmt.Errorf("%w: unexpected challenge", ErrInvalidClientData)
+	}
+	if !contains(rp.Origins, data.Origin) {
+		return fmt.Errorf("%w: unexpected origin %s", ErrInvalidClientData, data.Origin)
+	}
+
+	return nil
+}
+
+func (rp RelyingParty) verifyAuthenticatorData(data authenticatorData) error {
+	rpIDHash := sha256.Sum256([]byte(rp.ID))
+	if !bytes.Equal(data.rpIDHash, rpIDHash[:]) {
+		return fmt.Errorf("%w: unexpected rp id hash", ErrInvalidResponse)
+	}
+	if !data.userPresent() {
+		return fmt.Errorf("%w: user was not present", ErrInvalidResponse)
+	}
+	if !data.userVerified() {
+		return ErrUserNotVerified
+	}
+
+	return nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
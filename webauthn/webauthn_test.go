@@ -0,0 +1,528 @@
+package webauthn
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+)
+
+const (
+	testRPID   = "example.com"
+	testOrigin = "https://example.com"
+)
+
+var testRelyingParty = RelyingParty{ID: testRPID, Name: "Example", Origins: []string{testOrigin}}
+
+// softwareAuthenticator is an authenticator with an ES256 credential that is kept in memory. Its fields can be
+// changed to make it respond the way a faulty or malicious authenticator would.
+type softwareAuthenticator struct {
+	privateKey   *ecdsa.PrivateKey
+	credentialID []byte
+	aaguid       []byte
+	signCount    uint32
+	// rpID is the relying party whose id hash is reported in the authenticator data.
+	rpID  string
+	flags byte
+}
+
+func newSoftwareAuthenticator(t *testing.T) *softwareAuthenticator {
+	t.Helper()
+
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return &softwareAuthenticator{
+		privateKey:   privateKey,
+		credentialID: randomBytes(t, 16),
+		aaguid:       randomBytes(t, 16),
+		rpID:         testRPID,
+		flags:        flagUserPresent | flagUserVerified,
+	}
+}
+
+// testAttestation is the attestation that the software authenticator registers its credential with. Packed
+// attestations without a certificate are self attestations, signed by the credential key.
+type testAttestation struct {
+	format      string
+	key         *ecdsa.PrivateKey
+	certificate []byte
+}
+
+// register responds to a registration ceremony, and returns the client data json and the attestation object.
+func (a *softwareAuthenticator) register(t *testing.T, challenge []byte, origin string, attestation testAttestation) ([]byte, []byte) {
+	t.Helper()
+
+	clientDataJSON := newClientDataJSON(t, clientDataTypeCreate, challenge, origin)
+	authData := a.authenticatorData(true)
+
+	statement := cborMap{}
+	if attestation.format == AttestationFormatPacked {
+		key := attestation.key
+		if key == nil {
+			key = a.privateKey
+		}
+		statement = cborMap{
+			{"alg", AlgorithmES256},
+			{"sig", sign(t, key, authData, clientDataJSON)},
+		}
+		if attestation.certificate != nil {
+			statement = append(statement, cborPair{"x5c", []interface{}{attestation.certificate}})
+		}
+	}
+
+	attestationObject := encodeCBOR(cborMap{
+		{"fmt", attestation.format},
+		{"attStmt", statement},
+		{"authData", authData},
+	})
+
+	return clientDataJSON, attestationObject
+}
+
+// assert responds to an authentication ceremony, and returns the client data json, the authenticator data and the
+// signature.
+func (a *softwareAuthenticator) assert(t *testing.T, challenge []byte, origin string) ([]byte, []byte, []byte) {
+	t.Helper()
+
+	a.signCount++
+	clientDataJSON := newClientDataJSON(t, clientDataTypeGet, challenge, origin)
+	authData := a.authenticatorData(false)
+
+	return clientDataJSON, authData, sign(t, a.privateKey, authData, clientDataJSON)
+}
+
+func (a *softwareAuthenticator) authenticatorData(withCredential bool) []byte {
+	rpIDHash := sha256.Sum256([]byte(a.rpID))
+	flags := a.flags
+	if withCredential {
+		flags |= flagAttestedCredentialData
+	}
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.signCount)
+	if withCredential {
+		data = append(data, a.aaguid...)
+		data = binary.BigEndian.AppendUint16(data, uint16(len(a.credentialID)))
+		data = append(data, a.credentialID...)
+		data = append(data, a.cosePublicKey()...)
+	}
+
+	return data
+}
+
+func (a *softwareAuthenticator) cosePublicKey() []byte {
+	return encodeCBOR(cborMap{
+		{coseKeyType, coseKeyTypeEC2},
+		{coseKeyAlgorithm, AlgorithmES256},
+		{coseKeyCurve, coseCurveP256},
+		{coseKeyX, a.privateKey.X.FillBytes(make([]byte, 32))},
+		{coseKeyY, a.privateKey.Y.FillBytes(make([]byte, 32))},
+	})
+}
+
+// newAttestationCertificate returns a key and a certificate that meet the requirements of packed attestation
+// certificates, for the authenticator model with the aaguid.
+func newAttestationCertificate(t *testing.T, aaguid []byte) (*ecdsa.PrivateKey, []byte) {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	aaguidExtension, err := asn1.Marshal(aaguid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject: pkix.Name{
+			Country:            []string{"US"},
+			Organization:       []string{"Example Authenticators"},
+			OrganizationalUnit: []string{"Authenticator Attestation"},
+			CommonName:         "Example Authenticator",
+		},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		BasicConstraintsValid: true,
+		ExtraExtensions:       []pkix.Extension{{Id: oidFIDOGenCeAAGUID, Value: aaguidExtension}},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key, der
+}
+
+func newClientDataJSON(t *testing.T, ceremonyType string, challenge []byte, origin string) []byte {
+	t.Helper()
+
+	clientDataJSON, err := json.Marshal(clientData{Type: ceremonyType, Challenge: encode(challenge), Origin: origin})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return clientDataJSON
+}
+
+// sign signs the authenticator data and the hash of the client data json, as authenticators do.
+func sign(t *testing.T, key *ecdsa.PrivateKey, authData []byte, clientDataJSON []byte) []byte {
+	t.Helper()
+
+	clientDataHash := sha256.Sum256(clientDataJSON)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, key, digest[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signature
+}
+
+func newChallenge(t *testing.T) []byte {
+	t.Helper()
+
+	challenge, err := GenerateChallenge()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return challenge
+}
+
+func randomBytes(t *testing.T, n int) []byte {
+	t.Helper()
+
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		t.Fatal(err)
+	}
+
+	return b
+}
+
+// cborMap is a cbor map whose entries are encoded in order, as the ordering of go maps is random.
+type cborMap []cborPair
+
+type cborPair struct {
+	key   interface{}
+	value interface{}
+}
+
+// encodeCBOR encodes the subset of cbor that decodeCBOR supports.
+func encodeCBOR(value interface{}) []byte {
+	switch v := value.(type) {
+	case int:
+		return encodeCBOR(int64(v))
+	case int64:
+		if v < 0 {
+			return cborHead(1, uint64(-1-v))
+		}
+		return cborHead(0, uint64(v))
+	case []byte:
+		return append(cborHead(2, uint64(len(v))), v...)
+	case string:
+		return append(cborHead(3, uint64(len(v))), v...)
+	case []interface{}:
+		data := cborHead(4, uint64(len(v)))
+		for _, item := range v {
+			data = append(data, encodeCBOR(item)...)
+		}
+		return data
+	case cborMap:
+		data := cborHead(5, uint64(len(v)))
+		for _, pair := range v {
+			data = append(data, encodeCBOR(pair.key)...)
+			data = append(data, encodeCBOR(pair.value)...)
+		}
+		return data
+	}
+
+	panic("webauthn: cannot encode the value as cbor")
+}
+
+func cborHead(majorType byte, argument uint64) []byte {
+	switch {
+	case argument < 24:
+		return []byte{majorType<<5 | byte(argument)}
+	case argument <= 0xff:
+		return []byte{majorType<<5 | 24, byte(argument)}
+	case argument <= 0xffff:
+		return binary.BigEndian.AppendUint16([]byte{majorType<<5 | 25}, uint16(argument))
+	case argument <= 0xffffffff:
+		return binary.BigEndian.AppendUint32([]byte{majorType<<5 | 26}, uint32(argument))
+	}
+
+	return binary.BigEndian.AppendUint64([]byte{majorType<<5 | 27}, argument)
+}
+
+func TestRegistrationAndAssertion(t *testing.T) {
+	tests := []struct {
+		name        string
+		attestation func(authenticator *softwareAuthenticator) testAttestation
+	}{
+		{
+			name: "none attestation",
+			attestation: func(*softwareAuthenticator) testAttestation {
+				return testAttestation{format: AttestationFormatNone}
+			},
+		},
+		{
+			name: "packed self attestation",
+			attestation: func(*softwareAuthenticator) testAttestation {
+				return testAttestation{format: AttestationFormatPacked}
+			},
+		},
+		{
+			name: "packed attestation with a certificate",
+			attestation: func(authenticator *softwareAuthenticator) testAttestation {
+				key, certificate := newAttestationCertificate(t, authenticator.aaguid)
+				return testAttestation{format: AttestationFormatPacked, key: key, certificate: certificate}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authenticator := newSoftwareAuthenticator(t)
+			attestation := test.attestation(authenticator)
+
+			challenge := newChallenge(t)
+			clientDataJSON, attestationObject := authenticator.register(t, challenge, testOrigin, attestation)
+			credential, err := testRelyingParty.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+			if err != nil {
+				t.Fatalf("VerifyRegistration() error = %v", err)
+			}
+			if string(credential.ID) != string(authenticator.credentialID) {
+				t.Errorf("credential id = %x, want %x", credential.ID, authenticator.credentialID)
+			}
+			if string(credential.AAGUID) != string(authenticator.aaguid) {
+				t.Errorf("aaguid = %x, want %x", credential.AAGUID, authenticator.aaguid)
+			}
+			if credential.AttestationFormat != attestation.format {
+				t.Errorf("attestation format = %s, want %s", credential.AttestationFormat, attestation.format)
+			}
+
+			storedSignCount := credential.SignCount
+			for i := 0; i < 2; i++ {
+				challenge := newChallenge(t)
+				clientDataJSON, authData, signature := authenticator.assert(t, challenge, testOrigin)
+				signCount, err := testRelyingParty.VerifyAssertion(challenge, credential.PublicKey, storedSignCount, clientDataJSON, authData, signature)
+				if err != nil {
+					t.Fatalf("VerifyAssertion() error = %v", err)
+				}
+				if signCount != authenticator.signCount {
+					t.Errorf("sign count = %d, want %d", signCount, authenticator.signCount)
+				}
+				storedSignCount = signCount
+			}
+		})
+	}
+}
+
+func TestVerifyRegistrationFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		// respond registers the authenticator in response to the challenge, and returns the challenge that the
+		// response is verified with.
+		respond func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte) ([]byte, []byte, []byte)
+		wantErr error
+	}{
+		{
+			name: "bad origin",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte) ([]byte, []byte, []byte) {
+				clientDataJSON, attestationObject := authenticator.register(t, challenge, "https://evil.example.com", testAttestation{format: AttestationFormatNone})
+				return challenge, clientDataJSON, attestationObject
+			},
+			wantErr: ErrInvalidClientData,
+		},
+		{
+			name: "bad rp id hash",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte) ([]byte, []byte, []byte) {
+				authenticator.rpID = "evil.example.com"
+				clientDataJSON, attestationObject := authenticator.register(t, challenge, testOrigin, testAttestation{format: AttestationFormatNone})
+				return challenge, clientDataJSON, attestationObject
+			},
+			wantErr: ErrInvalidResponse,
+		},
+		{
+			name: "user not verified",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte) ([]byte, []byte, []byte) {
+				authenticator.flags = flagUserPresent
+				clientDataJSON, attestationObject := authenticator.register(t, challenge, testOrigin, testAttestation{format: AttestationFormatNone})
+				return challenge, clientDataJSON, attestationObject
+			},
+			wantErr: ErrUserNotVerified,
+		},
+		{
+			name: "replayed challenge",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte) ([]byte, []byte, []byte) {
+				clientDataJSON, attestationObject := authenticator.register(t, challenge, testOrigin, testAttestation{format: AttestationFormatNone})
+				// the response to an earlier ceremony is replayed in a new one.
+				return newChallenge(t), clientDataJSON, attestationObject
+			},
+			wantErr: ErrInvalidClientData,
+		},
+		{
+			name: "wrong ceremony type",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte) ([]byte, []byte, []byte) {
+				_, attestationObject := authenticator.register(t, challenge, testOrigin, testAttestation{format: AttestationFormatNone})
+				return challenge, newClientDataJSON(t, clientDataTypeGet, challenge, testOrigin), attestationObject
+			},
+			wantErr: ErrInvalidClientData,
+		},
+		{
+			name: "packed attestation signed by another key",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte) ([]byte, []byte, []byte) {
+				otherKey, _ := newAttestationCertificate(t, nil)
+				_, certificate := newAttestationCertificate(t, authenticator.aaguid)
+				clientDataJSON, attestationObject := authenticator.register(t, challenge, testOrigin, testAttestation{format: AttestationFormatPacked, key: otherKey, certificate: certificate})
+				return challenge, clientDataJSON, attestationObject
+			},
+			wantErr: ErrInvalidAttestation,
+		},
+		{
+			name: "packed attestation certificate of another authenticator model",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte) ([]byte, []byte, []byte) {
+				key, certificate := newAttestationCertificate(t, randomBytes(t, 16))
+				clientDataJSON, attestationObject := authenticator.register(t, challenge, testOrigin, testAttestation{format: AttestationFormatPacked, key: key, certificate: certificate})
+				return challenge, clientDataJSON, attestationObject
+			},
+			wantErr: ErrInvalidAttestation,
+		},
+		{
+			name: "unsupported attestation format",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte) ([]byte, []byte, []byte) {
+				clientDataJSON, attestationObject := authenticator.register(t, challenge, testOrigin, testAttestation{format: "fido-u2f"})
+				return challenge, clientDataJSON, attestationObject
+			},
+			wantErr: ErrUnsupportedAttestationFormat,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authenticator := newSoftwareAuthenticator(t)
+			challenge, clientDataJSON, attestationObject := test.respond(t, authenticator, newChallenge(t))
+
+			_, err := testRelyingParty.VerifyRegistration(challenge, clientDataJSON, attestationObject)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("VerifyRegistration() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}
+
+func TestVerifyAssertionFailures(t *testing.T) {
+	tests := []struct {
+		name string
+		// respond makes the authenticator assert in response to the challenge, and returns the challenge and the
+		// stored sign count that the response is verified with.
+		respond func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte, storedSignCount uint32) ([]byte, uint32, []byte, []byte, []byte)
+		wantErr error
+	}{
+		{
+			name: "bad origin",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte, storedSignCount uint32) ([]byte, uint32, []byte, []byte, []byte) {
+				clientDataJSON, authData, signature := authenticator.assert(t, challenge, "https://evil.example.com")
+				return challenge, storedSignCount, clientDataJSON, authData, signature
+			},
+			wantErr: ErrInvalidClientData,
+		},
+		{
+			name: "bad rp id hash",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte, storedSignCount uint32) ([]byte, uint32, []byte, []byte, []byte) {
+				authenticator.rpID = "evil.example.com"
+				clientDataJSON, authData, signature := authenticator.assert(t, challenge, testOrigin)
+				return challenge, storedSignCount, clientDataJSON, authData, signature
+			},
+			wantErr: ErrInvalidResponse,
+		},
+		{
+			name: "user not verified",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte, storedSignCount uint32) ([]byte, uint32, []byte, []byte, []byte) {
+				authenticator.flags = flagUserPresent
+				clientDataJSON, authData, signature := authenticator.assert(t, challenge, testOrigin)
+				return challenge, storedSignCount, clientDataJSON, authData, signature
+			},
+			wantErr: ErrUserNotVerified,
+		},
+		{
+			name: "replayed challenge",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte, storedSignCount uint32) ([]byte, uint32, []byte, []byte, []byte) {
+				clientDataJSON, authData, signature := authenticator.assert(t, challenge, testOrigin)
+				// the response to an earlier ceremony is replayed in a new one.
+				return newChallenge(t), storedSignCount, clientDataJSON, authData, signature
+			},
+			wantErr: ErrInvalidClientData,
+		},
+		{
+			name: "replayed assertion",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte, storedSignCount uint32) ([]byte, uint32, []byte, []byte, []byte) {
+				clientDataJSON, authData, signature := authenticator.assert(t, challenge, testOrigin)
+				// the sign count of the assertion has already been stored when it was first verified.
+				return challenge, authenticator.signCount, clientDataJSON, authData, signature
+			},
+			wantErr: ErrSignCountNotIncreased,
+		},
+		{
+			name: "sign count regression",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte, storedSignCount uint32) ([]byte, uint32, []byte, []byte, []byte) {
+				clientDataJSON, authData, signature := authenticator.assert(t, challenge, testOrigin)
+				// a clone of the authenticator has signed more often than this one.
+				return challenge, authenticator.signCount + 10, clientDataJSON, authData, signature
+			},
+			wantErr: ErrSignCountNotIncreased,
+		},
+		{
+			name: "signed by another key",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte, storedSignCount uint32) ([]byte, uint32, []byte, []byte, []byte) {
+				otherKey, _ := newAttestationCertificate(t, nil)
+				clientDataJSON, authData, _ := authenticator.assert(t, challenge, testOrigin)
+				return challenge, storedSignCount, clientDataJSON, authData, sign(t, otherKey, authData, clientDataJSON)
+			},
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name: "wrong ceremony type",
+			respond: func(t *testing.T, authenticator *softwareAuthenticator, challenge []byte, storedSignCount uint32) ([]byte, uint32, []byte, []byte, []byte) {
+				_, authData, _ := authenticator.assert(t, challenge, testOrigin)
+				clientDataJSON := newClientDataJSON(t, clientDataTypeCreate, challenge, testOrigin)
+				return challenge, storedSignCount, clientDataJSON, authData, sign(t, authenticator.privateKey, authData, clientDataJSON)
+			},
+			wantErr: ErrInvalidClientData,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			authenticator := newSoftwareAuthenticator(t)
+			registrationChallenge := newChallenge(t)
+			clientDataJSON, attestationObject := authenticator.register(t, registrationChallenge, testOrigin, testAttestation{format: AttestationFormatNone})
+			credential, err := testRelyingParty.VerifyRegistration(registrationChallenge, clientDataJSON, attestationObject)
+			if err != nil {
+				t.Fatalf("VerifyRegistration() error = %v", err)
+			}
+
+			challenge, storedSignCount, clientDataJSON, authData, signature := test.respond(t, authenticator, newChallenge(t), credential.SignCount)
+			_, err = testRelyingParty.VerifyAssertion(challenge, credential.PublicKey, storedSignCount, clientDataJSON, authData, signature)
+			if !errors.Is(err, test.wantErr) {
+				t.Errorf("VerifyAssertion() error = %v, want %v", err, test.wantErr)
+			}
+		})
+	}
+}